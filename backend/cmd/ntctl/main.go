@@ -0,0 +1,237 @@
+// Command ntctl runs network tools on a network-tools server and streams
+// their output to the terminal.
+//
+// Usage:
+//
+//	ntctl [flags] ping <target> [-c count]
+//	ntctl [flags] dig <domain> [type] [@nameserver] [+short] [+trace] [+answer]
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+
+	wsHandler "backend/internal/api/websocket"
+)
+
+// Exit codes used when the tool itself did not produce one
+const (
+	exitFailure = 1
+	exitUsage   = 2
+)
+
+// requestError is a failure reported by the server before a job started,
+// such as a validation error
+type requestError struct {
+	message string
+}
+
+func (e *requestError) Error() string {
+	return e.message
+}
+
+// transport runs a command on the server, calling onFrame for every frame
+// received until the job completes, and returns the completion frame
+type transport interface {
+	Run(ctx context.Context, cmd wsHandler.CommandRequest, onFrame func(wsHandler.CommandResponse)) (*wsHandler.CommandResponse, error)
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	fs := flag.NewFlagSet("ntctl", flag.ContinueOnError)
+	server := fs.String("server", envOr("NTCTL_SERVER", "http://localhost:8080"), "server base URL")
+	mode := fs.String("transport", "ws", "transport to use: ws or rest")
+	token := fs.String("token", os.Getenv("NTCTL_TOKEN"), "API key or JWT to authenticate with")
+	jsonOut := fs.Bool("json", false, "print the structured result as JSON instead of streaming output")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: ntctl [flags] ping <target> [-c count]")
+		fmt.Fprintln(fs.Output(), "       ntctl [flags] dig <domain> [type] [@nameserver] [+short] [+trace] [+answer]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	toolArgs, toolJSON := extractFlag(fs.Args()[1:], "--json")
+	*jsonOut = *jsonOut || toolJSON
+
+	cmd, err := buildCommand(fs.Arg(0), toolArgs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ntctl: %v\n", err)
+		return exitUsage
+	}
+
+	var t transport
+	switch *mode {
+	case "ws":
+		t = &wsTransport{baseURL: *server, token: *token}
+	case "rest":
+		t = &restTransport{baseURL: *server, token: *token}
+	default:
+		fmt.Fprintf(os.Stderr, "ntctl: unknown transport %q\n", *mode)
+		return exitUsage
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	return runCommand(ctx, t, cmd, *jsonOut, os.Stdout, os.Stderr)
+}
+
+// runCommand runs cmd over t, printing its output or, with jsonOut, its
+// completion frame, and returns the exit code for ntctl: the tool's own,
+// exitUsage when the server rejected the request, exitFailure otherwise
+func runCommand(ctx context.Context, t transport, cmd wsHandler.CommandRequest, jsonOut bool, stdout, stderr io.Writer) int {
+	final, err := t.Run(ctx, cmd, func(frame wsHandler.CommandResponse) {
+		switch {
+		case frame.Type == wsHandler.FrameShutdown:
+			fmt.Fprintf(stderr, "ntctl: %s, waiting for the job to finish\n", frame.Error)
+		case !jsonOut && frame.Type == wsHandler.FrameOutput:
+			fmt.Fprintln(stdout, frame.Output)
+		}
+	})
+	if err != nil {
+		fmt.Fprintf(stderr, "ntctl: %v\n", err)
+		var reqErr *requestError
+		if errors.As(err, &reqErr) {
+			return exitUsage
+		}
+		return exitFailure
+	}
+
+	if jsonOut {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(final); err != nil {
+			fmt.Fprintf(stderr, "ntctl: writing result: %v\n", err)
+			return exitFailure
+		}
+	} else if final.Error != "" {
+		fmt.Fprintf(stderr, "ntctl: %s\n", final.Error)
+	}
+
+	if final.ExitCode == nil {
+		return exitFailure
+	}
+	if code := *final.ExitCode; code >= 0 {
+		return code
+	}
+	return exitFailure
+}
+
+// buildCommand converts command line arguments into a command request
+func buildCommand(tool string, args []string) (wsHandler.CommandRequest, error) {
+	var params map[string]interface{}
+	var err error
+	switch tool {
+	case "ping":
+		params, err = pingParameters(args)
+	case "dig":
+		params, err = digParameters(args)
+	default:
+		return wsHandler.CommandRequest{}, fmt.Errorf("unknown tool %q", tool)
+	}
+	if err != nil {
+		return wsHandler.CommandRequest{}, err
+	}
+	return wsHandler.CommandRequest{Type: tool, Command: tool, Parameters: params}, nil
+}
+
+// pingParameters parses "<target> [-c count]" in any order
+func pingParameters(args []string) (map[string]interface{}, error) {
+	params := map[string]interface{}{"count": 4}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "-c" || arg == "--count":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("%s requires a value", arg)
+			}
+			i++
+			count, err := strconv.Atoi(args[i])
+			if err != nil {
+				return nil, fmt.Errorf("invalid count %q", args[i])
+			}
+			params["count"] = count
+		case strings.HasPrefix(arg, "-"):
+			return nil, fmt.Errorf("unknown ping option %q", arg)
+		default:
+			if _, ok := params["target"]; ok {
+				return nil, fmt.Errorf("unexpected argument %q", arg)
+			}
+			params["target"] = arg
+		}
+	}
+	if _, ok := params["target"]; !ok {
+		return nil, errors.New("ping requires a target")
+	}
+	return params, nil
+}
+
+// digParameters parses dig style arguments: a domain, an optional record
+// type, an optional @nameserver and +option flags
+func digParameters(args []string) (map[string]interface{}, error) {
+	params := map[string]interface{}{"recordType": "A"}
+	options := map[string]interface{}{}
+	typeSet := false
+	for _, arg := range args {
+		switch {
+		case strings.HasPrefix(arg, "@"):
+			params["nameserver"] = strings.TrimPrefix(arg, "@")
+		case strings.HasPrefix(arg, "+"):
+			options[strings.TrimPrefix(arg, "+")] = true
+		case strings.HasPrefix(arg, "-"):
+			return nil, fmt.Errorf("unknown dig option %q", arg)
+		case params["domain"] == nil:
+			params["domain"] = arg
+		case !typeSet:
+			params["recordType"] = strings.ToUpper(arg)
+			typeSet = true
+		default:
+			return nil, fmt.Errorf("unexpected argument %q", arg)
+		}
+	}
+	if params["domain"] == nil {
+		return nil, errors.New("dig requires a domain")
+	}
+	if len(options) > 0 {
+		params["parameters"] = options
+	}
+	return params, nil
+}
+
+// extractFlag removes every occurrence of a boolean flag from args
+func extractFlag(args []string, name string) ([]string, bool) {
+	rest := make([]string, 0, len(args))
+	found := false
+	for _, arg := range args {
+		if arg == name {
+			found = true
+			continue
+		}
+		rest = append(rest, arg)
+	}
+	return rest, found
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	wsHandler "backend/internal/api/websocket"
+)
+
+func TestPingParameters(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    map[string]interface{}
+		wantErr string
+	}{
+		{"Target only", []string{"example.com"}, map[string]interface{}{"target": "example.com", "count": 4}, ""},
+		{"Count", []string{"example.com", "-c", "5"}, map[string]interface{}{"target": "example.com", "count": 5}, ""},
+		{"Count first", []string{"--count", "5", "example.com"}, map[string]interface{}{"target": "example.com", "count": 5}, ""},
+		{"Missing count", []string{"example.com", "-c"}, nil, "-c requires a value"},
+		{"Bad count", []string{"example.com", "-c", "five"}, nil, "invalid count"},
+		{"Unknown option", []string{"example.com", "-t", "64"}, nil, "unknown ping option"},
+		{"Two targets", []string{"example.com", "example.net"}, nil, "unexpected argument"},
+		{"No target", nil, nil, "requires a target"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pingParameters(tt.args)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("pingParameters() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("pingParameters() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("pingParameters() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDigParameters(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    map[string]interface{}
+		wantErr string
+	}{
+		{"Domain only", []string{"example.com"}, map[string]interface{}{"domain": "example.com", "recordType": "A"}, ""},
+		{"Record type", []string{"example.com", "mx"}, map[string]interface{}{"domain": "example.com", "recordType": "MX"}, ""},
+		{
+			"Nameserver and options",
+			[]string{"@1.1.1.1", "example.com", "MX", "+short"},
+			map[string]interface{}{"domain": "example.com", "recordType": "MX", "nameserver": "1.1.1.1", "parameters": map[string]interface{}{"short": true}},
+			"",
+		},
+		{"Unknown option", []string{"example.com", "-x"}, nil, "unknown dig option"},
+		{"Extra argument", []string{"example.com", "MX", "extra"}, nil, "unexpected argument"},
+		{"No domain", []string{"+short"}, nil, "requires a domain"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := digParameters(tt.args)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("digParameters() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("digParameters() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("digParameters() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeTransport completes every command with final or fails with err
+type fakeTransport struct {
+	final *wsHandler.CommandResponse
+	err   error
+}
+
+func (f fakeTransport) Run(_ context.Context, _ wsHandler.CommandRequest, onFrame func(wsHandler.CommandResponse)) (*wsHandler.CommandResponse, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	onFrame(wsHandler.CommandResponse{Type: wsHandler.FrameOutput, Output: "line"})
+	return f.final, nil
+}
+
+// failingWriter rejects every write
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("broken pipe") }
+
+func TestRunCommandExitCode(t *testing.T) {
+	code := func(n int) *int { return &n }
+
+	tests := []struct {
+		name      string
+		transport fakeTransport
+		jsonOut   bool
+		stdout    io.Writer
+		want      int
+	}{
+		{"Tool exit code", fakeTransport{final: &wsHandler.CommandResponse{ExitCode: code(3)}}, false, io.Discard, 3},
+		{"Success", fakeTransport{final: &wsHandler.CommandResponse{ExitCode: code(0)}}, false, io.Discard, 0},
+		{"No exit code", fakeTransport{final: &wsHandler.CommandResponse{Error: "timed out"}}, false, io.Discard, exitFailure},
+		{"Negative exit code", fakeTransport{final: &wsHandler.CommandResponse{ExitCode: code(-1)}}, false, io.Discard, exitFailure},
+		{"Request error", fakeTransport{err: &requestError{message: "invalid target"}}, false, io.Discard, exitUsage},
+		{"Connection error", fakeTransport{err: errors.New("connection refused")}, false, io.Discard, exitFailure},
+		{"JSON result", fakeTransport{final: &wsHandler.CommandResponse{ExitCode: code(0)}}, true, io.Discard, 0},
+		{"JSON write fails", fakeTransport{final: &wsHandler.CommandResponse{ExitCode: code(0)}}, true, failingWriter{}, exitFailure},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := wsHandler.CommandRequest{Type: "ping", Command: "ping"}
+			if got := runCommand(context.Background(), tt.transport, cmd, tt.jsonOut, tt.stdout, io.Discard); got != tt.want {
+				t.Errorf("runCommand() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"backend/internal/api/rest"
+	wsHandler "backend/internal/api/websocket"
+
+	"github.com/fasthttp/websocket"
+)
+
+// maxStreamAttempts bounds how often the REST transport reconnects to an
+// interrupted event stream
+const maxStreamAttempts = 3
+
+// wsTransport runs commands over the /ws endpoint
+type wsTransport struct {
+	baseURL string
+	token   string
+}
+
+// setToken adds token to header as a bearer credential. API keys and JWTs
+// are both accepted this way.
+func setToken(header http.Header, token string) {
+	if token != "" {
+		header.Set("Authorization", "Bearer "+token)
+	}
+}
+
+func (t *wsTransport) Run(ctx context.Context, cmd wsHandler.CommandRequest, onFrame func(wsHandler.CommandResponse)) (*wsHandler.CommandResponse, error) {
+	u, err := url.Parse(t.baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid server URL: %v", err)
+	}
+	switch u.Scheme {
+	case "https":
+		u.Scheme = "wss"
+	default:
+		u.Scheme = "ws"
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/ws"
+
+	header := http.Header{}
+	setToken(header, t.token)
+	conn, resp, err := websocket.DefaultDialer.DialContext(ctx, u.String(), header)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusUnauthorized {
+			return nil, &requestError{message: "unauthorized, set --token or NTCTL_TOKEN"}
+		}
+		return nil, fmt.Errorf("connecting to %s: %v", u, err)
+	}
+	defer conn.Close()
+
+	if err := conn.WriteJSON(cmd); err != nil {
+		return nil, err
+	}
+
+	// Ask the server to cancel the job when interrupted; the completion
+	// frame still arrives through the read loop below. A job can only be
+	// cancelled by ID, so an interrupt before the started frame waits for it.
+	started := make(chan string, 1)
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-stop:
+			return
+		}
+		select {
+		case jobID := <-started:
+			conn.WriteJSON(wsHandler.CommandRequest{Type: "cancel", JobID: jobID})
+		case <-stop:
+		}
+	}()
+
+	for {
+		var frame wsHandler.CommandResponse
+		if err := conn.ReadJSON(&frame); err != nil {
+			return nil, fmt.Errorf("reading from server: %v", err)
+		}
+		switch frame.Type {
+		case wsHandler.FrameError:
+			if frame.JobID == "" {
+				return nil, &requestError{message: frame.Error}
+			}
+		case wsHandler.FrameStarted:
+			select {
+			case started <- frame.JobID:
+			default:
+			}
+		case wsHandler.FrameCompleted:
+			return &frame, nil
+		}
+		onFrame(frame)
+	}
+}
+
+// restTransport runs commands through the REST job API and follows their
+// output over Server-Sent Events
+type restTransport struct {
+	baseURL string
+	token   string
+}
+
+func (t *restTransport) Run(ctx context.Context, cmd wsHandler.CommandRequest, onFrame func(wsHandler.CommandResponse)) (*wsHandler.CommandResponse, error) {
+	base := strings.TrimSuffix(t.baseURL, "/") + "/api/v1/jobs"
+
+	body, err := json.Marshal(rest.JobRequest{Tool: cmd.Type, Parameters: cmd.Parameters})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, base, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	setToken(req.Header, t.token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to %s: %v", t.baseURL, err)
+	}
+	defer resp.Body.Close()
+
+	var created struct {
+		ID    string `json:"id"`
+		Error string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return nil, fmt.Errorf("decoding response: %v", err)
+	}
+	if resp.StatusCode >= 400 {
+		if resp.StatusCode < 500 {
+			return nil, &requestError{message: created.Error}
+		}
+		return nil, fmt.Errorf("server error: %s", created.Error)
+	}
+
+	// Cancel the job when interrupted; the stream then ends with its
+	// completion frame
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			req, err := http.NewRequest(http.MethodDelete, base+"/"+created.ID, nil)
+			if err == nil {
+				setToken(req.Header, t.token)
+				if resp, err := http.DefaultClient.Do(req); err == nil {
+					resp.Body.Close()
+				}
+			}
+		case <-stop:
+		}
+	}()
+
+	lastID := 0
+	for attempt := 0; attempt < maxStreamAttempts; attempt++ {
+		final, err := t.follow(base+"/"+created.ID+"/events", &lastID, onFrame)
+		if final != nil || err == nil {
+			return final, err
+		}
+	}
+	return nil, fmt.Errorf("event stream for job %s interrupted", created.ID)
+}
+
+// follow reads one event stream connection, resuming after *lastID. It returns
+// the completion frame, or a nil frame and an error if the stream broke off.
+func (t *restTransport) follow(streamURL string, lastID *int, onFrame func(wsHandler.CommandResponse)) (*wsHandler.CommandResponse, error) {
+	req, err := http.NewRequest(http.MethodGet, streamURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	setToken(req.Header, t.token)
+	if *lastID > 0 {
+		req.Header.Set("Last-Event-ID", strconv.Itoa(*lastID))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, resp.Body)
+		return nil, fmt.Errorf("event stream returned %s", resp.Status)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "id: "):
+			if id, err := strconv.Atoi(strings.TrimPrefix(line, "id: ")); err == nil {
+				*lastID = id
+			}
+		case strings.HasPrefix(line, "data: "):
+			var frame wsHandler.CommandResponse
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &frame); err != nil {
+				return nil, fmt.Errorf("decoding event: %v", err)
+			}
+			if frame.Type == wsHandler.FrameCompleted {
+				return &frame, nil
+			}
+			onFrame(frame)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.ErrUnexpectedEOF
+}
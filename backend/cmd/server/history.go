@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// historyPruneInterval is how often expired jobs are removed from the history
+const historyPruneInterval = time.Hour
+
+// pruneHistory removes jobs older than the configured retention, and links
+// that expired or point to removed jobs, once at startup and then
+// periodically until ctx is done
+func (r *runtime) pruneHistory(ctx context.Context) {
+	if r.history == nil {
+		return
+	}
+	ticker := time.NewTicker(historyPruneInterval)
+	defer ticker.Stop()
+	for {
+		if retention := r.Config().History.Retention; retention > 0 {
+			removed, err := r.history.PruneJobs(time.Now().Add(-retention))
+			if err != nil {
+				slog.Error("pruning job history failed", "error", err)
+			} else if removed > 0 {
+				slog.Info("pruned job history", "removed", removed)
+			}
+		}
+		if removed, err := r.history.PruneLinks(time.Now()); err != nil {
+			slog.Error("pruning job links failed", "error", err)
+		} else if removed > 0 {
+			slog.Info("pruned job links", "removed", removed)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -1,38 +1,172 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
+	"flag"
 	"log"
-	"time"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/websocket/v2"
 
+	"backend/internal/api/middleware"
+	"backend/internal/api/rest"
 	wsHandler "backend/internal/api/websocket"
+	"backend/internal/config"
+	"backend/internal/metrics"
+	"backend/pkg/proxyproto"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "verify" {
+		os.Exit(runVerify(os.Args[2:]))
+	}
+
+	configPath := flag.String("config", "", "path to the YAML configuration file")
+	listen := flag.String("listen", "", "listen address, overrides server.listen")
+	logLevel := flag.String("log-level", "", "log level, overrides logging.level")
+	logFormat := flag.String("log-format", "", "log format, overrides logging.format")
+	flag.Parse()
+
+	// Command line flags take precedence over the file and environment
+	overrides := func(cfg *config.Config) {
+		if *listen != "" {
+			cfg.Server.Listen = *listen
+		}
+		if *logLevel != "" {
+			cfg.Logging.Level = *logLevel
+		}
+		if *logFormat != "" {
+			cfg.Logging.Format = *logFormat
+		}
+	}
+
+	cfg, err := loadConfig(*configPath, overrides)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Reloadable components shared by the WebSocket and REST APIs
+	rt, err := newRuntime(*configPath, overrides, cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go rt.watch(ctx)
+	go rt.pruneHistory(ctx)
+	// Alerts keep being delivered while running jobs finish during shutdown
+	alertCtx, stopAlerts := context.WithCancel(context.Background())
+	defer stopAlerts()
+	go rt.alerts.Run(alertCtx)
+
 	app := fiber.New(fiber.Config{
-		ReadTimeout:  time.Minute,
-		WriteTimeout: time.Minute,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
 	})
 
 	// Middleware
-	app.Use(logger.New())
-	app.Use(cors.New())
+	trusted, err := middleware.NewTrustedProxies(cfg.Server.TrustedProxies)
+	if err != nil {
+		log.Fatal(err)
+	}
+	app.Use(middleware.RealIP(trusted, cfg.Server.ProxyHeader))
+	app.Use(logger.New(logger.Config{
+		CustomTags: map[string]logger.LogFunc{
+			logger.TagIP: func(output logger.Buffer, c *fiber.Ctx, _ *logger.Data, _ string) (int, error) {
+				return output.WriteString(middleware.ClientIP(c))
+			},
+		},
+	}))
+	if cfg.Server.TLS.Enabled && cfg.Server.TLS.ClientAuth != "none" {
+		app.Use(middleware.ClientCertificate())
+	}
+	origins := middleware.NewOriginPolicy(cfg.CORS.AllowOrigins)
+	app.Use(cors.New(cors.Config{
+		AllowOriginsFunc: origins.Allowed,
+		AllowMethods:     strings.Join(cfg.CORS.AllowMethods, ","),
+		AllowHeaders:     strings.Join(cfg.CORS.AllowHeaders, ","),
+		ExposeHeaders:    strings.Join(cfg.CORS.ExposeHeaders, ","),
+		AllowCredentials: cfg.CORS.AllowCredentials,
+		MaxAge:           int(cfg.CORS.MaxAge.Seconds()),
+	}))
 
-	// WebSocket upgrade middleware
-	app.Use("/ws", func(c *fiber.Ctx) error {
-		if websocket.IsWebSocketUpgrade(c) {
-			c.Locals("allowed", true)
-			return c.Next()
-		}
-		return fiber.ErrUpgradeRequired
+	// Health checks
+	health := rest.NewHealthHandler(rt.manager.Registry)
+	health.RegisterProbes(app)
+	app.Hooks().OnListen(func(fiber.ListenData) error {
+		health.SetListening(true)
+		return nil
 	})
 
+	// Prometheus metrics
+	ws := wsHandler.NewHandler(rt.manager, rt.limiter)
+	wsHandle := ws.Serve
+	if cfg.Metrics.Enabled {
+		m := metrics.New(rt.manager)
+		rt.limiter.OnReject(m.RateLimited)
+		wsHandle = m.WebSocket(wsHandle)
+		app.Get(cfg.Metrics.Path, m.Handler())
+	}
+
+	// Scheduled jobs start once every job observer has been added
+	if rt.scheduler != nil {
+		go rt.scheduler.Run(ctx)
+	}
+
+	// REST API
+	api := app.Group("/api/v1", rt.auth.Handler())
+	jobsHandler := rest.NewHandler(rt.manager, rt.limiter, rt.history, rest.AdminToken(rt.Config))
+	jobsHandler.Register(api)
+	rest.NewScheduleHandler(rt.scheduler).Register(api)
+	rest.NewAdminHandler(rt.Config).Register(api)
+	health.Register(api)
+
+	// Read-only permalinks to finished jobs
+	jobsHandler.RegisterShare(app.Group("/share", rt.auth.Handler()))
+
+	// WebSocket upgrade middleware
+	app.Use("/ws", middleware.CheckOrigin(origins), rt.auth.Handler(), middleware.WebSocketUpgrade())
+
 	// WebSocket route
-	app.Get("/ws", websocket.New(wsHandler.Handler))
+	app.Get("/ws", websocket.New(wsHandle))
 
-	log.Fatal(app.Listen(":8080"))
+	listenErr := make(chan error, 1)
+	go func() {
+		ln, err := net.Listen("tcp", cfg.Server.Listen)
+		if err != nil {
+			listenErr <- err
+			return
+		}
+		if cfg.Server.ProxyProtocol {
+			ln = proxyproto.NewListener(ln, trusted.Contains, cfg.Server.ReadTimeout)
+		}
+		if cfg.Server.TLS.Enabled {
+			tlsConfig, err := newTLSConfig(cfg.Server.TLS)
+			if err != nil {
+				listenErr <- err
+				return
+			}
+			ln = tls.NewListener(ln, tlsConfig)
+		}
+		listenErr <- app.Listener(ln)
+	}()
+
+	select {
+	case err := <-listenErr:
+		log.Fatal(err)
+	case <-ctx.Done():
+		stop()
+		shutdown(app, rt.manager, ws, health, cfg.Server.ShutdownGrace)
+		if rt.history != nil {
+			rt.history.Close()
+		}
+	}
 }
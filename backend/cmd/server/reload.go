@@ -0,0 +1,414 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"backend/internal/alerts"
+	"backend/internal/api/middleware"
+	"backend/internal/config"
+	"backend/internal/jobs"
+	"backend/internal/schedule"
+	"backend/internal/store"
+	"backend/internal/tools"
+	"backend/internal/validation"
+	applog "backend/pkg/logger"
+)
+
+// restartOnly lists configuration paths that only take effect on restart
+var restartOnly = []string{"server.", "logging.format", "logging.audit.", "cors.", "reload.", "metrics.", "history.path", "scheduler.enabled"}
+
+// runtime owns the live configuration and pushes it into the components that
+// can change without a restart
+type runtime struct {
+	path      string
+	overrides func(*config.Config)
+
+	manager *jobs.Manager
+	limiter *middleware.IPRateLimiter
+	auth    *middleware.Auth
+	// history is nil when job history is disabled
+	history *store.Store
+	// scheduler is nil when the scheduler or the history is disabled
+	scheduler *schedule.Scheduler
+	alerts    *alerts.Engine
+
+	mu      sync.Mutex
+	current atomic.Pointer[config.Config]
+}
+
+// loadConfig reads the configuration file and environment, applies command
+// line overrides and validates the result
+func loadConfig(path string, overrides func(*config.Config)) (*config.Config, error) {
+	cfg, err := config.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	overrides(cfg)
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// newRuntime builds the reloadable components from cfg
+func newRuntime(path string, overrides func(*config.Config), cfg *config.Config) (*runtime, error) {
+	var history *store.Store
+	if cfg.History.Path != "" {
+		var err error
+		if history, err = store.Open(cfg.History.Path); err != nil {
+			return nil, fmt.Errorf("opening job history: %w", err)
+		}
+	}
+	settings, err := jobSettings(cfg, baselineLookup(history))
+	if err != nil {
+		return nil, err
+	}
+	auth, err := authSettings(cfg)
+	if err != nil {
+		return nil, err
+	}
+	alerting, err := alertSettings(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := applog.Configure(cfg.Logging.Level, cfg.Logging.Format); err != nil {
+		return nil, err
+	}
+	validation.SetCurrent(settings.Validator)
+
+	r := &runtime{
+		path:      path,
+		overrides: overrides,
+		manager:   jobs.NewManager(settings),
+		limiter:   middleware.NewIPRateLimiter(rateLimit(cfg), cfg.RateLimit.Burst),
+		auth:      middleware.NewAuth(auth),
+		history:   history,
+		alerts:    alerts.NewEngine(alerting),
+	}
+	audit, err := auditLogger(cfg.Logging.Audit)
+	if err != nil {
+		return nil, err
+	}
+	r.manager.AddObserver(jobs.NewAuditObserver(audit))
+	r.manager.AddObserver(r.alerts)
+	if r.history != nil {
+		r.manager.AddObserver(store.NewJobRecorder(r.history))
+		if cfg.Scheduler.Enabled {
+			r.scheduler = schedule.New(r.history, r.manager, schedulerLimits(cfg))
+		}
+	}
+	r.current.Store(cfg)
+	return r, nil
+}
+
+// Config returns the configuration currently in effect
+func (r *runtime) Config() *config.Config {
+	return r.current.Load()
+}
+
+// reload re-reads the configuration and applies it. An invalid configuration
+// is rejected as a whole and the running configuration is kept.
+func (r *runtime) reload(reason string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	next, err := loadConfig(r.path, r.overrides)
+	if err != nil {
+		slog.Error("configuration reload rejected", "reason", reason, "error", err)
+		return
+	}
+	settings, err := jobSettings(next, baselineLookup(r.history))
+	if err != nil {
+		slog.Error("configuration reload rejected", "reason", reason, "error", err)
+		return
+	}
+	auth, err := authSettings(next)
+	if err != nil {
+		slog.Error("configuration reload rejected", "reason", reason, "error", err)
+		return
+	}
+	alerting, err := alertSettings(next)
+	if err != nil {
+		slog.Error("configuration reload rejected", "reason", reason, "error", err)
+		return
+	}
+
+	prev := r.Config()
+	changes := config.Diff(prev, next)
+
+	// Keep settings that cannot change while the server is running
+	next.Server = prev.Server
+	next.Logging.Format = prev.Logging.Format
+	next.Logging.Audit = prev.Logging.Audit
+	next.CORS = prev.CORS
+	next.Reload = prev.Reload
+	next.Metrics = prev.Metrics
+	next.History.Path = prev.History.Path
+	next.Scheduler.Enabled = prev.Scheduler.Enabled
+
+	var applied []string
+	for _, change := range changes {
+		if requiresRestart(change.Path) {
+			slog.Warn("configuration change requires a restart", "change", change.String())
+			continue
+		}
+		applied = append(applied, change.String())
+	}
+	if len(applied) == 0 {
+		slog.Info("configuration reloaded without changes", "reason", reason)
+		return
+	}
+
+	applog.SetLevel(next.Logging.Level)
+	validation.SetCurrent(settings.Validator)
+	r.manager.Apply(settings)
+	r.limiter.SetLimit(rateLimit(next), next.RateLimit.Burst)
+	r.auth.Apply(auth)
+	if r.scheduler != nil {
+		r.scheduler.Apply(schedulerLimits(next))
+	}
+	r.alerts.Apply(alerting)
+	r.current.Store(next)
+
+	slog.Info("configuration reloaded", "reason", reason, "changes", applied)
+}
+
+// watch reloads on SIGHUP and, when enabled, whenever the configuration file
+// changes, until ctx is done
+func (r *runtime) watch(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	cfg := r.Config()
+	var tick <-chan time.Time
+	if cfg.Reload.Watch && r.path != "" {
+		ticker := time.NewTicker(cfg.Reload.Interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	last, _ := os.ReadFile(r.path)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			last, _ = os.ReadFile(r.path)
+			r.reload("SIGHUP")
+		case <-tick:
+			data, err := os.ReadFile(r.path)
+			if err != nil || bytes.Equal(data, last) {
+				continue
+			}
+			last = data
+			r.reload("file changed")
+		}
+	}
+}
+
+func requiresRestart(path string) bool {
+	for _, prefix := range restartOnly {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// jobSettings builds the tool registry, validator, roles and limits for cfg.
+// baselines is nil when the job history is disabled.
+func jobSettings(cfg *config.Config, baselines tools.BaselineLookup) (jobs.Settings, error) {
+	v, err := validation.NewValidator(validation.Policy{
+		MinPingCount:  cfg.Tools.Ping.MinCount,
+		MaxPingCount:  cfg.Tools.Ping.MaxCount,
+		RecordTypes:   cfg.Tools.Dig.RecordTypes,
+		DigParameters: cfg.Tools.Dig.Parameters,
+		AllowTargets:  cfg.Policy.AllowTargets,
+		DenyTargets:   cfg.Policy.DenyTargets,
+	})
+	if err != nil {
+		return jobs.Settings{}, err
+	}
+
+	var enabled []tools.Tool
+	if cfg.Tools.Ping.Enabled {
+		enabled = append(enabled, tools.Ping{})
+	}
+	if cfg.Tools.Dig.Enabled {
+		enabled = append(enabled, tools.Dig{})
+	}
+	if cfg.Tools.Batch.Enabled && len(enabled) > 0 {
+		enabled = append(enabled, tools.NewBatch(cfg.Tools.Batch.MaxTargets, cfg.Tools.Batch.Concurrency, enabled...))
+	}
+	if cfg.Tools.Sweep.Enabled && cfg.Tools.Ping.Enabled {
+		enabled = append(enabled, tools.NewSweep(cfg.Tools.Sweep.MaxHosts, cfg.Tools.Sweep.Concurrency, cfg.Tools.Sweep.Timeout))
+	}
+	if cfg.Tools.DNSDiff.Enabled && cfg.Tools.Dig.Enabled {
+		enabled = append(enabled, tools.NewDNSDiff(baselines, cfg.Tools.DNSDiff.MaxQueries, cfg.Tools.DNSDiff.Concurrency))
+	}
+	if cfg.Tools.DNSCompare.Enabled && cfg.Tools.Dig.Enabled {
+		presets := make([]tools.NameserverPreset, 0, len(cfg.Tools.DNSCompare.Presets))
+		for _, p := range cfg.Tools.DNSCompare.Presets {
+			presets = append(presets, tools.NameserverPreset{Name: p.Name, Address: p.Address})
+		}
+		enabled = append(enabled, tools.NewDNSCompare(presets, cfg.Tools.DNSCompare.MaxServers))
+	}
+	if cfg.Tools.ZoneCheck.Enabled && cfg.Tools.Dig.Enabled {
+		enabled = append(enabled, tools.NewZoneCheck(cfg.Tools.ZoneCheck.MaxServers))
+	}
+
+	var rbac *validation.RBAC
+	if cfg.RBAC.Enabled {
+		roles := make([]validation.Role, 0, len(cfg.RBAC.Roles))
+		for name, role := range cfg.RBAC.Roles {
+			roles = append(roles, validation.Role{
+				Name:          name,
+				Tools:         role.Tools,
+				MaxPingCount:  role.MaxPingCount,
+				RecordTypes:   role.RecordTypes,
+				DigParameters: role.DigParameters,
+				AllowTargets:  role.AllowTargets,
+				DenyTargets:   role.DenyTargets,
+			})
+		}
+		if rbac, err = validation.NewRBAC(roles, cfg.RBAC.DefaultRoles, cfg.RBAC.Assignments); err != nil {
+			return jobs.Settings{}, err
+		}
+	}
+
+	return jobs.Settings{
+		Registry:  tools.NewRegistry(enabled...),
+		Validator: v,
+		RBAC:      rbac,
+		Limits: jobs.Limits{
+			MaxConcurrent: cfg.Jobs.MaxConcurrent,
+			Timeout:       cfg.Jobs.Timeout,
+		},
+	}, nil
+}
+
+// authSettings builds the authenticators enabled in cfg
+func authSettings(cfg *config.Config) (middleware.AuthSettings, error) {
+	settings := middleware.AuthSettings{Required: cfg.Auth.Required}
+	if len(cfg.Auth.APIKeys) > 0 {
+		settings.Authenticators = append(settings.Authenticators, middleware.NewAPIKeys(cfg.Auth.APIKeys))
+	}
+	if cfg.Auth.HtpasswdFile != "" {
+		h, err := middleware.NewHtpasswd(cfg.Auth.HtpasswdFile)
+		if err != nil {
+			return settings, fmt.Errorf("auth.htpasswdFile: %w", err)
+		}
+		settings.Authenticators = append(settings.Authenticators, h)
+	}
+	if cfg.Auth.JWT.JWKSURL != "" {
+		settings.Authenticators = append(settings.Authenticators, middleware.NewJWT(middleware.JWTOptions{
+			JWKSURL:         cfg.Auth.JWT.JWKSURL,
+			Issuer:          cfg.Auth.JWT.Issuer,
+			Audience:        cfg.Auth.JWT.Audience,
+			SubjectClaim:    cfg.Auth.JWT.SubjectClaim,
+			RolesClaim:      cfg.Auth.JWT.RolesClaim,
+			RefreshInterval: cfg.Auth.JWT.RefreshInterval,
+		}))
+	}
+	return settings, nil
+}
+
+// auditLogger creates the command execution log, writing to hash chained
+// files when a directory is configured
+func auditLogger(cfg config.AuditConfig) (*applog.Logger, error) {
+	if cfg.Dir == "" {
+		return applog.New(), nil
+	}
+	f, err := applog.OpenAuditFile(cfg.Dir, applog.AuditFileOptions{
+		MaxSize:     int64(cfg.MaxSizeMB) << 20,
+		RotateEvery: cfg.RotateEvery,
+		MaxFiles:    cfg.MaxFiles,
+		MaxAge:      cfg.MaxAge,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("opening audit log: %w", err)
+	}
+	return applog.NewWithWriters(f, os.Stderr), nil
+}
+
+// rateLimit converts the configured request budget into a token rate
+func rateLimit(cfg *config.Config) rate.Limit {
+	return rate.Every(cfg.RateLimit.Period / time.Duration(cfg.RateLimit.Requests))
+}
+
+// schedulerLimits extracts the limits on client schedules
+func schedulerLimits(cfg *config.Config) schedule.Limits {
+	return schedule.Limits{
+		MinInterval:  cfg.Scheduler.MinInterval,
+		MaxSchedules: cfg.Scheduler.MaxSchedules,
+	}
+}
+
+// alertSettings parses the alert rules and webhooks in cfg. Disabled
+// alerting yields no rules.
+func alertSettings(cfg *config.Config) (alerts.Settings, error) {
+	settings := alerts.Settings{
+		RepeatInterval: cfg.Alerts.RepeatInterval,
+		Retries:        cfg.Alerts.Retries,
+		RetryBackoff:   cfg.Alerts.RetryBackoff,
+		Webhooks:       make(map[string]*alerts.Webhook),
+	}
+	if !cfg.Alerts.Enabled {
+		return settings, nil
+	}
+	for name, w := range cfg.Alerts.Webhooks {
+		webhook := &alerts.Webhook{
+			Name:    name,
+			URL:     w.URL,
+			Format:  w.Format,
+			Headers: w.Headers,
+			Timeout: w.Timeout,
+		}
+		if webhook.Format == "" {
+			webhook.Format = alerts.FormatJSON
+		}
+		if webhook.Timeout == 0 {
+			webhook.Timeout = 10 * time.Second
+		}
+		settings.Webhooks[name] = webhook
+	}
+	for i, rule := range cfg.Alerts.Rules {
+		cond, err := alerts.ParseCondition(rule.Condition)
+		if err != nil {
+			return settings, fmt.Errorf("alerts.rules[%d]: %w", i, err)
+		}
+		if tool := cond.Tool(); tool != "" && rule.Tool != "" && tool != rule.Tool {
+			return settings, fmt.Errorf("alerts.rules[%d]: %s is not reported by %s jobs", i, cond.Metric, rule.Tool)
+		}
+		settings.Rules = append(settings.Rules, alerts.Rule{
+			Name:      rule.Name,
+			Tool:      rule.Tool,
+			Target:    rule.Target,
+			Condition: cond,
+			Webhooks:  rule.Webhooks,
+		})
+	}
+	return settings, nil
+}
+
+// baselineLookup reads dns-diff baselines from the job history, nil when
+// the history is disabled
+func baselineLookup(history *store.Store) tools.BaselineLookup {
+	if history == nil {
+		return nil
+	}
+	return history.DNSBaseline
+}
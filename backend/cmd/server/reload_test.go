@@ -0,0 +1,87 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"backend/internal/config"
+	"backend/internal/jobs"
+)
+
+func writeConfig(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newTestRuntime(t *testing.T, data string) *runtime {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	writeConfig(t, path, data)
+	overrides := func(cfg *config.Config) { cfg.History.Path = filepath.Join(dir, "nettools.db") }
+	cfg, err := loadConfig(path, overrides)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rt, err := newRuntime(path, overrides, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { rt.history.Close() })
+	return rt
+}
+
+func pingAllowed(rt *runtime, target string) error {
+	return rt.manager.Validate("ping", map[string]interface{}{"target": target, "count": 1}, jobs.Client{})
+}
+
+func TestReloadApplies(t *testing.T) {
+	rt := newTestRuntime(t, "jobs:\n  maxConcurrent: 2\n")
+	if err := pingAllowed(rt, "host.blocked.example"); err != nil {
+		t.Fatalf("ping before reload: %v", err)
+	}
+
+	writeConfig(t, rt.path, "jobs:\n  maxConcurrent: 7\npolicy:\n  denyTargets: [\"*.blocked.example\"]\n")
+	rt.reload("test")
+
+	if got := rt.manager.Limits().MaxConcurrent; got != 7 {
+		t.Errorf("manager maxConcurrent = %d, want 7", got)
+	}
+	if got := rt.Config().Jobs.MaxConcurrent; got != 7 {
+		t.Errorf("config maxConcurrent = %d, want 7", got)
+	}
+	if err := pingAllowed(rt, "host.blocked.example"); err == nil {
+		t.Error("denied target accepted after reload")
+	}
+}
+
+func TestReloadRejectsInvalidConfig(t *testing.T) {
+	rt := newTestRuntime(t, "jobs:\n  maxConcurrent: 2\n")
+	prev := rt.Config()
+
+	// The job settings are valid but the alert rule is not
+	writeConfig(t, rt.path, `jobs:
+  maxConcurrent: 7
+policy:
+  denyTargets: ["*.blocked.example"]
+alerts:
+  enabled: true
+  rules:
+    - name: broken
+      condition: "packetLoss >"
+`)
+	rt.reload("test")
+
+	if rt.Config() != prev {
+		t.Error("configuration replaced by an invalid one")
+	}
+	if got := rt.manager.Limits().MaxConcurrent; got != 2 {
+		t.Errorf("manager maxConcurrent = %d, want 2", got)
+	}
+	if err := pingAllowed(rt, "host.blocked.example"); err != nil {
+		t.Errorf("policy of the rejected configuration applied: %v", err)
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/websocket/v2"
+
+	"backend/internal/api/rest"
+	wsHandler "backend/internal/api/websocket"
+	"backend/internal/jobs"
+)
+
+// closeTimeout bounds how long cancelled jobs and open connections get to
+// wind down once the grace period has ended
+const closeTimeout = 5 * time.Second
+
+// shutdown drains the server: new jobs are refused, WebSocket clients are
+// told the server is going away, running jobs get up to grace to finish and
+// are then cancelled, and finally every connection is closed
+func shutdown(app *fiber.App, manager *jobs.Manager, ws *wsHandler.Handler, health *rest.HealthHandler, grace time.Duration) {
+	slog.Info("shutting down", "grace", grace.String())
+	health.SetDraining(true)
+	manager.Drain()
+	ws.Broadcast(wsHandler.CommandResponse{
+		Type:  wsHandler.FrameShutdown,
+		Error: jobs.ErrShuttingDown.Error(),
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), grace)
+	err := manager.Wait(ctx)
+	cancel()
+	if err != nil {
+		n := manager.CancelAll()
+		slog.Warn("grace period ended, cancelling running jobs", "jobs", n)
+		ctx, cancel := context.WithTimeout(context.Background(), closeTimeout)
+		manager.Wait(ctx)
+		cancel()
+	}
+
+	// Let clients receive every job's completed frame before the close
+	ctx, cancel = context.WithTimeout(context.Background(), closeTimeout)
+	ws.CloseAll(ctx, websocket.CloseGoingAway, jobs.ErrShuttingDown.Error())
+	cancel()
+	if err := app.ShutdownWithTimeout(closeTimeout); err != nil {
+		slog.Error("shutdown", "error", err)
+	}
+	slog.Info("shutdown complete")
+}
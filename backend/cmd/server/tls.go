@@ -0,0 +1,140 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"backend/internal/config"
+)
+
+// tlsCheckInterval limits how often the certificate files are checked for
+// changes, since the check runs during handshakes
+const tlsCheckInterval = time.Second
+
+// certReloader serves the listener's certificate and client CA pool, loading
+// them again whenever one of the files changes. A file that fails to load
+// keeps the previous version in use.
+type certReloader struct {
+	cfg  config.TLSConfig
+	base *tls.Config
+
+	mu        sync.Mutex
+	current   *tls.Config
+	modTimes  []time.Time
+	checkedAt time.Time
+}
+
+// newTLSConfig builds the listener's TLS configuration from cfg
+func newTLSConfig(cfg config.TLSConfig) (*tls.Config, error) {
+	r := &certReloader{
+		cfg: cfg,
+		base: &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			ClientAuth: clientAuthType(cfg.ClientAuth),
+		},
+	}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return r.config(), nil
+		},
+	}, nil
+}
+
+func clientAuthType(mode string) tls.ClientAuthType {
+	switch mode {
+	case "optional":
+		return tls.VerifyClientCertIfGiven
+	case "require":
+		return tls.RequireAndVerifyClientCert
+	default:
+		return tls.NoClientCert
+	}
+}
+
+// files returns the paths watched for changes
+func (r *certReloader) files() []string {
+	files := []string{r.cfg.CertFile, r.cfg.KeyFile}
+	if r.cfg.ClientCAFile != "" {
+		files = append(files, r.cfg.ClientCAFile)
+	}
+	return files
+}
+
+// config returns the current TLS configuration, reloading it first if any of
+// the files changed
+func (r *certReloader) config() *tls.Config {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if time.Since(r.checkedAt) < tlsCheckInterval {
+		return r.current
+	}
+	r.checkedAt = time.Now()
+
+	for i, name := range r.files() {
+		info, err := os.Stat(name)
+		if err != nil || info.ModTime().Equal(r.modTimes[i]) {
+			continue
+		}
+		if err := r.loadLocked(); err != nil {
+			slog.Error("TLS reload failed, keeping the previous certificate", "error", err)
+			// Do not retry until the files change again
+			r.modTimes[i] = info.ModTime()
+		} else {
+			slog.Info("TLS certificate reloaded", "certFile", r.cfg.CertFile)
+		}
+		break
+	}
+	return r.current
+}
+
+func (r *certReloader) load() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.loadLocked()
+}
+
+// loadLocked reads the certificate, key and client CA files.
+// The caller must hold r.mu.
+func (r *certReloader) loadLocked() error {
+	modTimes := make([]time.Time, 0, 3)
+	for _, name := range r.files() {
+		info, err := os.Stat(name)
+		if err != nil {
+			return err
+		}
+		modTimes = append(modTimes, info.ModTime())
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.cfg.CertFile, r.cfg.KeyFile)
+	if err != nil {
+		return fmt.Errorf("loading TLS certificate: %w", err)
+	}
+	next := r.base.Clone()
+	next.Certificates = []tls.Certificate{cert}
+
+	if r.cfg.ClientCAFile != "" {
+		pem, err := os.ReadFile(r.cfg.ClientCAFile)
+		if err != nil {
+			return err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return errors.New("server.tls.clientCAFile contains no certificates")
+		}
+		next.ClientCAs = pool
+	}
+
+	r.current = next
+	r.modTimes = modTimes
+	return nil
+}
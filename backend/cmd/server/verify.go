@@ -0,0 +1,54 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"backend/internal/config"
+	applog "backend/pkg/logger"
+)
+
+// runVerify implements the "verify" subcommand, which checks the hash chain
+// of the audit log files and exits non-zero if any record is missing or was
+// modified
+func runVerify(args []string) int {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	configPath := fs.String("config", "", "path to the YAML configuration file")
+	dir := fs.String("dir", "", "audit log directory, overrides logging.audit.dir")
+	fs.Parse(args)
+
+	if *dir == "" {
+		cfg, err := config.Load(*configPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		*dir = cfg.Logging.Audit.Dir
+	}
+	if *dir == "" {
+		fmt.Fprintln(os.Stderr, "verify: no audit directory configured, use -dir")
+		return 2
+	}
+
+	report, err := applog.VerifyAuditDir(*dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "verify: %v\n", err)
+		return 2
+	}
+
+	fmt.Printf("checked %d records in %d files (seq %d-%d)\n",
+		report.Records, report.Files, report.FirstSeq, report.LastSeq)
+	if report.FirstSeq > 1 {
+		fmt.Printf("chain starts at seq %d; earlier files were removed by retention\n", report.FirstSeq)
+	}
+	for _, issue := range report.Issues {
+		fmt.Println(issue)
+	}
+	if len(report.Issues) > 0 {
+		fmt.Printf("FAILED: %d problems found\n", len(report.Issues))
+		return 1
+	}
+	fmt.Println("OK")
+	return 0
+}
@@ -0,0 +1,298 @@
+// File: backend/internal/alerts/alerts.go
+package alerts
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"backend/internal/jobs"
+)
+
+const (
+	// queueSize bounds the notifications waiting for delivery; further
+	// notifications are dropped
+	queueSize = 256
+	// workers is the number of notifications delivered at once
+	workers = 4
+	// maxSeries bounds the series tracked per rule; the least recently
+	// updated one is forgotten to make room
+	maxSeries = 1000
+)
+
+// queryParams are the parameters that, besides the target, decide what a
+// job's result describes, so jobs differing in them are tracked apart
+var queryParams = []string{"recordType", "nameserver", "nameservers"}
+
+// Settings is the reloadable alerting configuration
+type Settings struct {
+	Rules    []Rule
+	Webhooks map[string]*Webhook
+	// RepeatInterval re-sends a firing alert that still holds after this
+	// long, 0 to notify only when it starts firing
+	RepeatInterval time.Duration
+	// Retries is how often a failed delivery is retried, waiting
+	// RetryBackoff before the first retry and doubling it each time
+	Retries      int
+	RetryBackoff time.Duration
+}
+
+// ruleState tracks a rule for one series of jobs
+type ruleState struct {
+	firing   bool
+	lastSent time.Time
+	updated  time.Time
+	// last is the metric's previous value, for change conditions
+	last string
+	seen bool
+}
+
+// delivery is a notification queued for one webhook
+type delivery struct {
+	webhook *Webhook
+	alert   *Alert
+	retries int
+	backoff time.Duration
+}
+
+// Engine evaluates alert rules against finished jobs and notifies webhooks.
+// Jobs are grouped into series by tool, target, query parameters and
+// schedule. A threshold alert is sent when its condition starts holding for
+// a series and again, as resolved, when it stops; repeats in between are
+// suppressed unless RepeatInterval has passed.
+type Engine struct {
+	settings atomic.Pointer[Settings]
+	client   *http.Client
+	queue    chan delivery
+
+	mu sync.Mutex
+	// state is keyed by rule, then by series
+	state map[string]map[string]*ruleState
+}
+
+// NewEngine creates an alert engine with settings. Run delivers its
+// notifications.
+func NewEngine(settings Settings) *Engine {
+	e := &Engine{
+		client: &http.Client{},
+		queue:  make(chan delivery, queueSize),
+		state:  make(map[string]map[string]*ruleState),
+	}
+	e.settings.Store(&settings)
+	return e
+}
+
+// Apply atomically replaces the engine's settings. Rules keep their state
+// unless their condition changed.
+func (e *Engine) Apply(settings Settings) {
+	keep := make(map[string]bool, len(settings.Rules))
+	for i := range settings.Rules {
+		keep[ruleKey(&settings.Rules[i])] = true
+	}
+	e.mu.Lock()
+	for key := range e.state {
+		if !keep[key] {
+			delete(e.state, key)
+		}
+	}
+	e.mu.Unlock()
+	e.settings.Store(&settings)
+}
+
+// ruleKey identifies a rule's state, which is reset when the condition of
+// a rule with the same name changes
+func ruleKey(r *Rule) string {
+	return r.Name + "\x00" + r.Condition.String()
+}
+
+// Run delivers queued notifications until ctx is done
+func (e *Engine) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case d := <-e.queue:
+					e.deliver(ctx, d)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func (e *Engine) JobRejected(string, map[string]interface{}, jobs.Client, error) {}
+
+func (e *Engine) JobStarted(*jobs.Job) {}
+
+func (e *Engine) JobFinished(job *jobs.Job) {
+	e.evaluate(job.Snapshot(), time.Now())
+}
+
+// evaluate checks every rule that applies to a finished job
+func (e *Engine) evaluate(snap jobs.Snapshot, now time.Time) {
+	settings := e.settings.Load()
+	target := jobs.Target(snap.Parameters)
+	series := seriesKey(snap, target)
+	for i := range settings.Rules {
+		rule := &settings.Rules[i]
+		if !rule.matches(snap, target) {
+			continue
+		}
+		value, ok := rule.Condition.value(snap)
+		if !ok {
+			continue
+		}
+		alert := e.transition(rule, series, value, now, settings.RepeatInterval)
+		if alert == nil {
+			continue
+		}
+		alert.Tool = snap.Tool
+		alert.Target = target
+		alert.JobID = snap.ID
+		alert.Schedule = snap.Client.Schedule
+		if alert.Status == StatusFiring {
+			slog.Warn("alert firing", "rule", rule.Name, "target", target, "value", value, "jobId", snap.ID)
+		} else {
+			slog.Info("alert resolved", "rule", rule.Name, "target", target, "value", value, "jobId", snap.ID)
+		}
+		e.notify(settings, rule, alert)
+	}
+}
+
+// transition updates a rule's state for a series with a new value and
+// returns the alert to send, if any
+func (e *Engine) transition(rule *Rule, series, value string, now time.Time, repeat time.Duration) *Alert {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	key := ruleKey(rule)
+	targets, ok := e.state[key]
+	if !ok {
+		targets = make(map[string]*ruleState)
+		e.state[key] = targets
+	}
+	st, ok := targets[series]
+	if !ok {
+		if len(targets) >= maxSeries {
+			evictOldest(targets)
+		}
+		st = &ruleState{}
+		targets[series] = st
+	}
+	st.updated = now
+
+	alert := &Alert{
+		Rule:      rule.Name,
+		Condition: rule.Condition.String(),
+		Metric:    rule.Condition.Metric,
+		Value:     value,
+		Time:      now,
+	}
+	if rule.Condition.Op == OpChanged {
+		previous, seen := st.last, st.seen
+		st.last, st.seen = value, true
+		if !seen || previous == value {
+			return nil
+		}
+		alert.Status = StatusFiring
+		alert.Previous = &previous
+		st.lastSent = now
+		return alert
+	}
+
+	switch holds := rule.Condition.holds(value); {
+	case holds && (!st.firing || (repeat > 0 && now.Sub(st.lastSent) >= repeat)):
+		st.firing = true
+		st.lastSent = now
+		alert.Status = StatusFiring
+		return alert
+	case !holds && st.firing:
+		st.firing = false
+		st.lastSent = now
+		alert.Status = StatusResolved
+		return alert
+	default:
+		return nil
+	}
+}
+
+// evictOldest forgets the least recently updated series
+func evictOldest(series map[string]*ruleState) {
+	var oldest string
+	var oldestTime time.Time
+	for key, st := range series {
+		if oldest == "" || st.updated.Before(oldestTime) {
+			oldest, oldestTime = key, st.updated
+		}
+	}
+	delete(series, oldest)
+}
+
+// notify queues an alert for each of the rule's webhooks
+func (e *Engine) notify(settings *Settings, rule *Rule, alert *Alert) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		slog.Error("creating alert ID failed", "error", err)
+		return
+	}
+	alert.ID = hex.EncodeToString(id)
+	for _, name := range rule.Webhooks {
+		w, ok := settings.Webhooks[name]
+		if !ok {
+			continue
+		}
+		select {
+		case e.queue <- delivery{webhook: w, alert: alert, retries: settings.Retries, backoff: settings.RetryBackoff}:
+		default:
+			slog.Error("alert queue full, dropping notification", "rule", rule.Name, "webhook", name)
+		}
+	}
+}
+
+// deliver sends a notification, retrying retryable failures with
+// exponential backoff
+func (e *Engine) deliver(ctx context.Context, d delivery) {
+	backoff := d.backoff
+	for attempt := 1; ; attempt++ {
+		err := d.webhook.send(ctx, e.client, d.alert)
+		if err == nil {
+			slog.Debug("alert delivered", "rule", d.alert.Rule, "webhook", d.webhook.Name, "alertId", d.alert.ID, "attempts", attempt)
+			return
+		}
+		var retryable *retryableError
+		if !errors.As(err, &retryable) || attempt > d.retries {
+			slog.Error("alert delivery failed", "rule", d.alert.Rule, "webhook", d.webhook.Name, "alertId", d.alert.ID, "attempts", attempt, "error", err)
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// seriesKey identifies the jobs whose results a rule compares: the same
+// tool run against the same target with the same query, by the same
+// schedule if any
+func seriesKey(snap jobs.Snapshot, target string) string {
+	key := snap.Tool + "\x00" + target
+	for _, name := range queryParams {
+		if v, ok := snap.Parameters[name]; ok {
+			key += "\x00" + name + "=" + fmt.Sprint(v)
+		}
+	}
+	return key + "\x00" + snap.Client.Schedule
+}
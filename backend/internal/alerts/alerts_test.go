@@ -0,0 +1,265 @@
+// File: backend/internal/alerts/alerts_test.go
+package alerts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"backend/internal/jobs"
+	"backend/internal/tools"
+)
+
+func TestParseCondition(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		err  bool
+	}{
+		{in: "packetLoss > 20%", want: "packetLoss > 20%"},
+		{in: "avgMs>=150ms", want: "avgMs >= 150ms"},
+		{in: "status != NOERROR", want: "status != NOERROR"},
+		{in: "answer changed", want: "answer changed"},
+		{in: "exitCode == 0", want: "exitCode == 0"},
+		{in: "status > NOERROR", err: true},
+		{in: "avgMs > fast", err: true},
+		{in: "latency > 10", err: true},
+		{in: "packetLoss >", err: true},
+		{in: "packetLoss", err: true},
+	}
+	for _, tt := range tests {
+		c, err := ParseCondition(tt.in)
+		if tt.err {
+			if err == nil {
+				t.Errorf("ParseCondition(%q) succeeded, want error", tt.in)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseCondition(%q): %v", tt.in, err)
+			continue
+		}
+		if got := c.String(); got != tt.want {
+			t.Errorf("ParseCondition(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// receiver is a webhook stub recording the alerts it accepted. It fails the
+// first failures requests with 500.
+type receiver struct {
+	mu       sync.Mutex
+	failures int
+	bodies   [][]byte
+	ids      []string
+	got      chan struct{}
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failures > 0 {
+		r.failures--
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	r.bodies = append(r.bodies, body)
+	r.ids = append(r.ids, req.Header.Get("X-Alert-Id"))
+	r.got <- struct{}{}
+}
+
+// wait returns the next body the receiver accepted
+func (r *receiver) wait(t *testing.T) []byte {
+	t.Helper()
+	select {
+	case <-r.got:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no alert delivered")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.bodies[len(r.bodies)-1]
+}
+
+func (r *receiver) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.bodies)
+}
+
+func pingSnap(id, target string, loss float64) jobs.Snapshot {
+	return jobs.Snapshot{
+		ID:         id,
+		Tool:       "ping",
+		Parameters: map[string]interface{}{"target": target},
+		State:      jobs.StateSucceeded,
+		Result:     &tools.PingResult{Target: target, Transmitted: 10, Received: 10 - int(loss/10), PacketLoss: loss},
+	}
+}
+
+func digSnap(id string, data ...string) jobs.Snapshot {
+	r := &tools.DigResult{Domain: "example.com", RecordType: "A", Status: "NOERROR"}
+	for _, d := range data {
+		r.Answer = append(r.Answer, tools.DigRecord{Name: "example.com.", Type: "A", Data: d})
+	}
+	return jobs.Snapshot{
+		ID:         id,
+		Tool:       "dig",
+		Parameters: map[string]interface{}{"domain": "example.com"},
+		State:      jobs.StateSucceeded,
+		Result:     r,
+	}
+}
+
+func TestEngineNotifies(t *testing.T) {
+	rcv := &receiver{failures: 1, got: make(chan struct{}, 16)}
+	srv := httptest.NewServer(rcv)
+	defer srv.Close()
+
+	loss, err := ParseCondition("packetLoss > 20%")
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed, err := ParseCondition("answer changed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := NewEngine(Settings{
+		Rules: []Rule{
+			{Name: "loss", Condition: loss, Webhooks: []string{"ops"}},
+			{Name: "records", Condition: changed, Webhooks: []string{"chat"}},
+		},
+		Webhooks: map[string]*Webhook{
+			"ops":  {Name: "ops", URL: srv.URL, Format: FormatJSON, Timeout: time.Second},
+			"chat": {Name: "chat", URL: srv.URL, Format: FormatSlack, Timeout: time.Second},
+		},
+		Retries:      2,
+		RetryBackoff: 10 * time.Millisecond,
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go e.Run(ctx)
+
+	// The first delivery is answered with 500 and retried
+	now := time.Now()
+	e.evaluate(pingSnap("j1", "10.0.0.1", 50), now)
+	var a Alert
+	if err := json.Unmarshal(rcv.wait(t), &a); err != nil {
+		t.Fatal(err)
+	}
+	if a.Status != StatusFiring || a.Rule != "loss" || a.Value != "50" || a.Target != "10.0.0.1" || a.JobID != "j1" {
+		t.Errorf("firing alert = %+v", a)
+	}
+	if rcv.ids[0] != a.ID {
+		t.Errorf("X-Alert-Id = %q, want %q", rcv.ids[0], a.ID)
+	}
+
+	// Still failing is not sent again, another target is tracked separately
+	e.evaluate(pingSnap("j2", "10.0.0.1", 60), now.Add(time.Minute))
+	e.evaluate(pingSnap("j3", "10.0.0.2", 10), now.Add(time.Minute))
+	e.evaluate(pingSnap("j4", "10.0.0.1", 0), now.Add(2*time.Minute))
+	if err := json.Unmarshal(rcv.wait(t), &a); err != nil {
+		t.Fatal(err)
+	}
+	if a.Status != StatusResolved || a.JobID != "j4" {
+		t.Errorf("resolved alert = %+v", a)
+	}
+
+	// A change is reported against the previous answer, in Slack format
+	e.evaluate(digSnap("d1", "192.0.2.1", "192.0.2.2"), now)
+	e.evaluate(digSnap("d2", "192.0.2.2", "192.0.2.1"), now)
+	e.evaluate(digSnap("d3", "192.0.2.3"), now)
+	var msg map[string]string
+	if err := json.Unmarshal(rcv.wait(t), &msg); err != nil {
+		t.Fatal(err)
+	}
+	want := `:rotating_light: [FIRING] records: answer of dig example.com changed from "192.0.2.1, 192.0.2.2" to "192.0.2.3" (job d3)`
+	if msg["text"] != want {
+		t.Errorf("slack text = %q, want %q", msg["text"], want)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if n := rcv.count(); n != 3 {
+		t.Errorf("delivered %d alerts, want 3", n)
+	}
+}
+
+func TestTransitionRepeat(t *testing.T) {
+	cond, err := ParseCondition("status != NOERROR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := NewEngine(Settings{})
+	rule := &Rule{Name: "dns", Condition: cond}
+	now := time.Now()
+	steps := []struct {
+		value string
+		after time.Duration
+		want  string
+	}{
+		{"SERVFAIL", 0, StatusFiring},
+		{"SERVFAIL", time.Minute, ""},
+		{"NXDOMAIN", 5 * time.Minute, StatusFiring},
+		{"noerror", 6 * time.Minute, StatusResolved},
+		{"NOERROR", 7 * time.Minute, ""},
+	}
+	for i, step := range steps {
+		got := ""
+		if a := e.transition(rule, "example.com", step.value, now.Add(step.after), 5*time.Minute); a != nil {
+			got = a.Status
+		}
+		if got != step.want {
+			t.Errorf("step %d (%s): status %q, want %q", i, step.value, got, step.want)
+		}
+	}
+}
+
+func TestSeriesKey(t *testing.T) {
+	a := digSnap("d1", "192.0.2.1")
+	mx := digSnap("d2", "mail.example.com.")
+	mx.Parameters = map[string]interface{}{"domain": "example.com", "recordType": "MX"}
+	ns := digSnap("d3", "192.0.2.1")
+	ns.Parameters = map[string]interface{}{"domain": "example.com", "nameserver": "192.0.2.53"}
+	scheduled := digSnap("d4", "192.0.2.1")
+	scheduled.Client.Schedule = "s1"
+	ping := pingSnap("p1", "example.com", 0)
+
+	keys := make(map[string]string)
+	for _, snap := range []jobs.Snapshot{a, mx, ns, scheduled, ping} {
+		key := seriesKey(snap, jobs.Target(snap.Parameters))
+		if other, ok := keys[key]; ok {
+			t.Errorf("jobs %s and %s share series %q", other, snap.ID, key)
+		}
+		keys[key] = snap.ID
+	}
+	if again := digSnap("d5", "192.0.2.9"); seriesKey(again, jobs.Target(again.Parameters)) != seriesKey(a, jobs.Target(a.Parameters)) {
+		t.Errorf("repeated query is not the same series")
+	}
+}
+
+func TestTransitionBounded(t *testing.T) {
+	cond, err := ParseCondition("status != NOERROR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := NewEngine(Settings{})
+	rule := &Rule{Name: "dns", Condition: cond}
+	now := time.Now()
+	for i := 0; i <= maxSeries; i++ {
+		e.transition(rule, fmt.Sprint("series", i), "SERVFAIL", now.Add(time.Duration(i)*time.Second), 0)
+	}
+	series := e.state[ruleKey(rule)]
+	if len(series) != maxSeries {
+		t.Errorf("tracking %d series, want %d", len(series), maxSeries)
+	}
+	if _, ok := series["series0"]; ok {
+		t.Errorf("least recently updated series was kept")
+	}
+}
@@ -0,0 +1,236 @@
+// File: backend/internal/alerts/rules.go
+package alerts
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"backend/internal/jobs"
+	"backend/internal/tools"
+)
+
+// OpChanged is the operator of conditions that hold whenever a metric differs
+// from its value in the previous job for the same rule and target
+const OpChanged = "changed"
+
+// comparisonOps are the binary operators, longest first so that >= is not
+// read as >
+var comparisonOps = []string{">=", "<=", "==", "!=", ">", "<"}
+
+// metric extracts one value from a finished job
+type metric struct {
+	// tool is the tool whose results carry the metric, empty for any tool
+	tool    string
+	numeric bool
+	// unit is an optional suffix accepted on threshold values
+	unit    string
+	extract func(snap jobs.Snapshot) (string, bool)
+}
+
+var metrics = map[string]metric{
+	"state": {extract: func(snap jobs.Snapshot) (string, bool) {
+		return string(snap.State), true
+	}},
+	"exitCode": {numeric: true, extract: func(snap jobs.Snapshot) (string, bool) {
+		if snap.ExitCode == nil {
+			return "", false
+		}
+		return strconv.Itoa(*snap.ExitCode), true
+	}},
+	"packetLoss": {tool: "ping", numeric: true, unit: "%", extract: pingMetric(func(r *tools.PingResult) (float64, bool) {
+		return r.PacketLoss, r.Transmitted > 0
+	})},
+	"received": {tool: "ping", numeric: true, extract: pingMetric(func(r *tools.PingResult) (float64, bool) {
+		return float64(r.Received), true
+	})},
+	"minMs": {tool: "ping", numeric: true, unit: "ms", extract: pingMetric(func(r *tools.PingResult) (float64, bool) {
+		return r.MinMs, r.Received > 0
+	})},
+	"avgMs": {tool: "ping", numeric: true, unit: "ms", extract: pingMetric(func(r *tools.PingResult) (float64, bool) {
+		return r.AvgMs, r.Received > 0
+	})},
+	"maxMs": {tool: "ping", numeric: true, unit: "ms", extract: pingMetric(func(r *tools.PingResult) (float64, bool) {
+		return r.MaxMs, r.Received > 0
+	})},
+	"status": {tool: "dig", extract: digMetric(func(r *tools.DigResult) (string, bool) {
+		return r.Status, r.Status != ""
+	})},
+	"answer": {tool: "dig", extract: digMetric(func(r *tools.DigResult) (string, bool) {
+		return strings.Join(answerSet(r), ", "), true
+	})},
+	"answerCount": {tool: "dig", numeric: true, extract: digMetric(func(r *tools.DigResult) (string, bool) {
+		return strconv.Itoa(len(answerSet(r))), true
+	})},
+	"queryTimeMs": {tool: "dig", numeric: true, unit: "ms", extract: digMetric(func(r *tools.DigResult) (string, bool) {
+		return strconv.Itoa(r.QueryTimeMs), r.QueryTimeMs > 0
+	})},
+}
+
+func pingMetric(value func(*tools.PingResult) (float64, bool)) func(jobs.Snapshot) (string, bool) {
+	return func(snap jobs.Snapshot) (string, bool) {
+		r, ok := snap.Result.(*tools.PingResult)
+		if !ok {
+			return "", false
+		}
+		v, ok := value(r)
+		return strconv.FormatFloat(v, 'f', -1, 64), ok
+	}
+}
+
+func digMetric(value func(*tools.DigResult) (string, bool)) func(jobs.Snapshot) (string, bool) {
+	return func(snap jobs.Snapshot) (string, bool) {
+		r, ok := snap.Result.(*tools.DigResult)
+		if !ok {
+			return "", false
+		}
+		return value(r)
+	}
+}
+
+// answerSet is the sorted record data of a dig answer, so that the same
+// records in a different order compare equal
+func answerSet(r *tools.DigResult) []string {
+	set := make([]string, 0, len(r.Answer)+len(r.Short))
+	for _, rec := range r.Answer {
+		set = append(set, rec.Data)
+	}
+	set = append(set, r.Short...)
+	sort.Strings(set)
+	return set
+}
+
+// Condition is a parsed rule condition such as "packetLoss > 20%",
+// "status != NOERROR" or "answer changed"
+type Condition struct {
+	Metric string
+	Op     string
+	// Value is the threshold, empty for OpChanged
+	Value     string
+	threshold float64
+	metric    metric
+}
+
+// ParseCondition parses "<metric> <op> <value>" or "<metric> changed"
+func ParseCondition(s string) (*Condition, error) {
+	s = strings.TrimSpace(s)
+	c := &Condition{}
+	if name, ok := strings.CutSuffix(s, " "+OpChanged); ok {
+		c.Metric, c.Op = strings.TrimSpace(name), OpChanged
+	} else {
+		for _, op := range comparisonOps {
+			if name, value, ok := strings.Cut(s, op); ok {
+				c.Metric, c.Op, c.Value = strings.TrimSpace(name), op, strings.TrimSpace(value)
+				break
+			}
+		}
+		if c.Op == "" {
+			return nil, fmt.Errorf("condition %q must be <metric> <op> <value> or <metric> changed", s)
+		}
+	}
+
+	m, ok := metrics[c.Metric]
+	if !ok {
+		return nil, fmt.Errorf("unknown metric %q in condition %q, expected one of %s", c.Metric, s, strings.Join(metricNames(), ", "))
+	}
+	c.metric = m
+	if c.Op == OpChanged {
+		return c, nil
+	}
+	if c.Value == "" {
+		return nil, fmt.Errorf("condition %q has no value", s)
+	}
+	if !m.numeric {
+		if c.Op != "==" && c.Op != "!=" {
+			return nil, fmt.Errorf("condition %q: %s can only be compared with == or !=", s, c.Metric)
+		}
+		return c, nil
+	}
+	number := c.Value
+	if m.unit != "" {
+		number = strings.TrimSpace(strings.TrimSuffix(number, m.unit))
+	}
+	threshold, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return nil, fmt.Errorf("condition %q: %s must be compared with a number", s, c.Metric)
+	}
+	c.threshold = threshold
+	return c, nil
+}
+
+func metricNames() []string {
+	names := make([]string, 0, len(metrics))
+	for name := range metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Tool returns the tool whose jobs the condition applies to, empty for any
+func (c *Condition) Tool() string {
+	return c.metric.tool
+}
+
+// String returns the condition in its canonical form
+func (c *Condition) String() string {
+	if c.Op == OpChanged {
+		return c.Metric + " " + OpChanged
+	}
+	return c.Metric + " " + c.Op + " " + c.Value
+}
+
+// value extracts the condition's metric from a job
+func (c *Condition) value(snap jobs.Snapshot) (string, bool) {
+	return c.metric.extract(snap)
+}
+
+// holds reports whether a comparison condition is met by value
+func (c *Condition) holds(value string) bool {
+	if !c.metric.numeric {
+		equal := strings.EqualFold(value, c.Value)
+		return equal == (c.Op == "==")
+	}
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return false
+	}
+	switch c.Op {
+	case ">":
+		return v > c.threshold
+	case ">=":
+		return v >= c.threshold
+	case "<":
+		return v < c.threshold
+	case "<=":
+		return v <= c.threshold
+	case "==":
+		return v == c.threshold
+	default:
+		return v != c.threshold
+	}
+}
+
+// Rule raises an alert when its condition holds for a finished job
+type Rule struct {
+	Name string
+	// Tool and Target restrict the jobs the rule applies to; empty matches
+	// every job the condition's metric applies to
+	Tool      string
+	Target    string
+	Condition *Condition
+	// Webhooks are the names of the webhooks notified
+	Webhooks []string
+}
+
+// matches reports whether the rule applies to a job
+func (r *Rule) matches(snap jobs.Snapshot, target string) bool {
+	if r.Tool != "" && r.Tool != snap.Tool {
+		return false
+	}
+	if tool := r.Condition.Tool(); tool != "" && tool != snap.Tool {
+		return false
+	}
+	return r.Target == "" || strings.EqualFold(strings.TrimSuffix(r.Target, "."), strings.TrimSuffix(target, "."))
+}
@@ -0,0 +1,130 @@
+// File: backend/internal/alerts/webhook.go
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Webhook payload formats
+const (
+	FormatJSON  = "json"
+	FormatSlack = "slack"
+)
+
+// Alert statuses
+const (
+	StatusFiring   = "firing"
+	StatusResolved = "resolved"
+)
+
+// Alert is a notification about a rule, sent as the generic JSON payload
+type Alert struct {
+	// ID identifies the alert across delivery retries so receivers can
+	// discard duplicates
+	ID        string `json:"id"`
+	Rule      string `json:"rule"`
+	Status    string `json:"status"`
+	Condition string `json:"condition"`
+	Metric    string `json:"metric"`
+	// Value is the metric's value in the job that raised the alert
+	Value string `json:"value"`
+	// Previous is the earlier value of a changed metric, nil for thresholds
+	Previous *string   `json:"previous,omitempty"`
+	Tool     string    `json:"tool"`
+	Target   string    `json:"target,omitempty"`
+	JobID    string    `json:"jobId"`
+	Schedule string    `json:"schedule,omitempty"`
+	Time     time.Time `json:"time"`
+}
+
+// Summary describes the alert in one line
+func (a *Alert) Summary() string {
+	subject := a.Tool
+	if a.Target != "" {
+		subject += " " + a.Target
+	}
+	switch {
+	case a.Status == StatusResolved:
+		return fmt.Sprintf("[RESOLVED] %s: %s no longer holds for %s (%s is %s)", a.Rule, a.Condition, subject, a.Metric, a.Value)
+	case a.Previous != nil:
+		return fmt.Sprintf("[FIRING] %s: %s of %s changed from %q to %q", a.Rule, a.Metric, subject, *a.Previous, a.Value)
+	default:
+		return fmt.Sprintf("[FIRING] %s: %s for %s (%s is %s)", a.Rule, a.Condition, subject, a.Metric, a.Value)
+	}
+}
+
+// Webhook is an HTTP endpoint alerts are posted to
+type Webhook struct {
+	Name   string
+	URL    string
+	Format string
+	// Headers are added to every request, e.g. for authorization
+	Headers map[string]string
+	Timeout time.Duration
+}
+
+// payload encodes an alert in the webhook's format
+func (w *Webhook) payload(a *Alert) ([]byte, error) {
+	if w.Format == FormatSlack {
+		icon := ":rotating_light:"
+		if a.Status == StatusResolved {
+			icon = ":white_check_mark:"
+		}
+		text := icon + " " + a.Summary()
+		if a.JobID != "" {
+			text += " (job " + a.JobID + ")"
+		}
+		return json.Marshal(map[string]string{"text": text})
+	}
+	return json.Marshal(a)
+}
+
+// retryableError is a delivery failure worth retrying
+type retryableError struct {
+	err error
+}
+
+func (e *retryableError) Error() string { return e.err.Error() }
+
+func (e *retryableError) Unwrap() error { return e.err }
+
+// send posts an alert once. Network errors, 429 and 5xx responses are
+// retryable; other error responses are not.
+func (w *Webhook) send(ctx context.Context, client *http.Client, a *Alert) error {
+	body, err := w.payload(a)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, w.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Alert-Id", a.ID)
+	for k, v := range w.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return &retryableError{err: err}
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+	switch {
+	case resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return &retryableError{err: fmt.Errorf("webhook responded %s", resp.Status)}
+	default:
+		return fmt.Errorf("webhook responded %s", resp.Status)
+	}
+}
@@ -0,0 +1,148 @@
+// File: backend/internal/api/middleware/auth.go
+package middleware
+
+import (
+	"crypto/subtle"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/websocket/v2"
+)
+
+// Authentication methods recorded in Identity.Method
+const (
+	MethodAPIKey = "apikey"
+	MethodBasic  = "basic"
+	MethodJWT    = "jwt"
+)
+
+// ErrInvalidCredentials is returned by an Authenticator for credentials it
+// recognises but cannot verify
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// Authenticator verifies one kind of credential
+type Authenticator interface {
+	// Authenticate returns the identity proven by the request, nil if the
+	// request carries no credential of this kind, or an error if the
+	// credential is invalid
+	Authenticate(c *fiber.Ctx) (*Identity, error)
+}
+
+// AuthSettings is the reloadable configuration of an Auth middleware
+type AuthSettings struct {
+	// Required rejects anonymous requests
+	Required bool
+	// Authenticators are tried in order until one recognises a credential
+	Authenticators []Authenticator
+}
+
+// Auth authenticates requests, storing the identity in Locals
+type Auth struct {
+	settings atomic.Pointer[AuthSettings]
+}
+
+// NewAuth creates an authentication middleware using settings
+func NewAuth(settings AuthSettings) *Auth {
+	a := &Auth{}
+	a.settings.Store(&settings)
+	return a
+}
+
+// Apply replaces the authenticators, e.g. after a configuration reload
+func (a *Auth) Apply(settings AuthSettings) {
+	a.settings.Store(&settings)
+}
+
+// Handler returns the middleware. Requests that already carry an identity,
+// such as a verified client certificate, pass through unchanged.
+func (a *Auth) Handler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if IdentityFrom(c) != nil {
+			return c.Next()
+		}
+		settings := a.settings.Load()
+		for _, auth := range settings.Authenticators {
+			id, err := auth.Authenticate(c)
+			if err != nil {
+				slog.Warn("authentication failed", "clientIP", ClientIP(c), "error", err)
+				return unauthorized(c)
+			}
+			if id != nil {
+				c.Locals(LocalIdentity, id)
+				return c.Next()
+			}
+		}
+		if settings.Required {
+			return unauthorized(c)
+		}
+		return c.Next()
+	}
+}
+
+func unauthorized(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderWWWAuthenticate, `Bearer, Basic realm="nettools"`)
+	return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+		"error": "unauthorized",
+	})
+}
+
+// bearerToken returns the bearer token of the request. Browsers cannot set
+// headers on WebSocket upgrades, so those may pass it as ?access_token=.
+func bearerToken(c *fiber.Ctx) string {
+	if token, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+	if websocket.IsWebSocketUpgrade(c) {
+		return c.Query("access_token")
+	}
+	return ""
+}
+
+// looksLikeJWT reports whether token has the three dot separated parts of a
+// JSON Web Token
+func looksLikeJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
+// APIKeys authenticates static API keys sent in the X-API-Key header or as a
+// bearer token
+type APIKeys struct {
+	// keys maps each key to the name of its owner
+	keys map[string]string
+}
+
+// NewAPIKeys creates an authenticator from a map of owner name to key
+func NewAPIKeys(keys map[string]string) *APIKeys {
+	a := &APIKeys{keys: make(map[string]string, len(keys))}
+	for name, key := range keys {
+		a.keys[key] = name
+	}
+	return a
+}
+
+// Authenticate implements Authenticator
+func (a *APIKeys) Authenticate(c *fiber.Ctx) (*Identity, error) {
+	key := c.Get("X-API-Key")
+	if key == "" {
+		if token := bearerToken(c); token != "" && !looksLikeJWT(token) {
+			key = token
+		}
+	}
+	if key == "" {
+		return nil, nil
+	}
+	// Compare against every key so the time taken does not reveal a match
+	var owner string
+	for candidate, name := range a.keys {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(candidate)) == 1 {
+			owner = name
+		}
+	}
+	if owner == "" {
+		return nil, ErrInvalidCredentials
+	}
+	return &Identity{Subject: owner, Method: MethodAPIKey}, nil
+}
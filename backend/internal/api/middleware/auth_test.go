@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// jwksStub serves key as a single key JWKS
+func jwksStub(t *testing.T, key *rsa.PublicKey) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kid": "test",
+				"kty": "RSA",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func signToken(t *testing.T, key *rsa.PrivateKey, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "test"
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestAuth(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwks := jwksStub(t, &key.PublicKey)
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("s3cret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	htpasswdFile := filepath.Join(t.TempDir(), "htpasswd")
+	if err := os.WriteFile(htpasswdFile, []byte("bob:"+string(hash)+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	htpasswd, err := NewHtpasswd(htpasswdFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	auth := NewAuth(AuthSettings{
+		Required: true,
+		Authenticators: []Authenticator{
+			NewAPIKeys(map[string]string{"ci": "0123456789abcdef"}),
+			htpasswd,
+			NewJWT(JWTOptions{JWKSURL: jwks.URL, Issuer: "https://idp.test", Audience: "nettools"}),
+		},
+	})
+	app := fiber.New()
+	app.Get("/", auth.Handler(), func(c *fiber.Ctx) error {
+		return c.SendString(IdentityFrom(c).String())
+	})
+
+	valid := jwt.MapClaims{
+		"sub": "carol",
+		"iss": "https://idp.test",
+		"aud": "nettools",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+	expired := jwt.MapClaims{
+		"sub": "carol",
+		"iss": "https://idp.test",
+		"aud": "nettools",
+		"exp": time.Now().Add(-time.Hour).Unix(),
+	}
+	wrongAudience := jwt.MapClaims{
+		"sub": "carol",
+		"iss": "https://idp.test",
+		"aud": "other",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+
+	tests := []struct {
+		name       string
+		header     string
+		value      string
+		wantStatus int
+		wantID     string
+	}{
+		{"Anonymous", "", "", fiber.StatusUnauthorized, ""},
+		{"API key header", "X-API-Key", "0123456789abcdef", fiber.StatusOK, "apikey:ci"},
+		{"API key bearer", "Authorization", "Bearer 0123456789abcdef", fiber.StatusOK, "apikey:ci"},
+		{"Wrong API key", "X-API-Key", "fedcba9876543210", fiber.StatusUnauthorized, ""},
+		{"Basic", "Authorization", "Basic " + base64.StdEncoding.EncodeToString([]byte("bob:s3cret")), fiber.StatusOK, "basic:bob"},
+		{"Wrong password", "Authorization", "Basic " + base64.StdEncoding.EncodeToString([]byte("bob:guess")), fiber.StatusUnauthorized, ""},
+		{"JWT", "Authorization", "Bearer " + signToken(t, key, valid), fiber.StatusOK, "jwt:carol"},
+		{"Expired JWT", "Authorization", "Bearer " + signToken(t, key, expired), fiber.StatusUnauthorized, ""},
+		{"Wrong audience", "Authorization", "Bearer " + signToken(t, key, wrongAudience), fiber.StatusUnauthorized, ""},
+		{"Untrusted signer", "Authorization", "Bearer " + signToken(t, other, valid), fiber.StatusUnauthorized, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if tt.wantID != "" {
+				body, _ := io.ReadAll(resp.Body)
+				if string(body) != tt.wantID {
+					t.Errorf("identity = %q, want %q", body, tt.wantID)
+				}
+			}
+		})
+	}
+}
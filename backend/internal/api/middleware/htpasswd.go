@@ -0,0 +1,126 @@
+// File: backend/internal/api/middleware/htpasswd.go
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// htpasswdCheckInterval limits how often the file is checked for changes
+const htpasswdCheckInterval = time.Second
+
+// Htpasswd authenticates HTTP basic credentials against an Apache htpasswd
+// file with bcrypt or {SHA} entries. The file is reloaded when it changes.
+type Htpasswd struct {
+	path string
+
+	mu        sync.Mutex
+	users     map[string]string
+	modTime   time.Time
+	checkedAt time.Time
+}
+
+// NewHtpasswd loads the htpasswd file at path
+func NewHtpasswd(path string) (*Htpasswd, error) {
+	h := &Htpasswd{path: path}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.load(info.ModTime()); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// Authenticate implements Authenticator
+func (h *Htpasswd) Authenticate(c *fiber.Ctx) (*Identity, error) {
+	encoded, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Basic ")
+	if !ok {
+		return nil, nil
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	user, password, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return nil, ErrInvalidCredentials
+	}
+
+	hash, ok := h.lookup(user)
+	if !ok || !checkHtpasswd(hash, password) {
+		return nil, ErrInvalidCredentials
+	}
+	return &Identity{Subject: user, Method: MethodBasic}, nil
+}
+
+// lookup returns the password hash for user, reloading the file first if it
+// changed
+func (h *Htpasswd) lookup(user string) (string, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if time.Since(h.checkedAt) >= htpasswdCheckInterval {
+		h.checkedAt = time.Now()
+		if info, err := os.Stat(h.path); err == nil && !info.ModTime().Equal(h.modTime) {
+			// A file that fails to parse keeps the previous users
+			h.loadLocked(info.ModTime())
+		}
+	}
+	hash, ok := h.users[user]
+	return hash, ok
+}
+
+func (h *Htpasswd) load(modTime time.Time) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.loadLocked(modTime)
+}
+
+// loadLocked reads the file. The caller must hold h.mu.
+func (h *Htpasswd) loadLocked(modTime time.Time) error {
+	h.modTime = modTime
+	data, err := os.ReadFile(h.path)
+	if err != nil {
+		return err
+	}
+	users := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		user, hash, ok := strings.Cut(text, ":")
+		if !ok {
+			return fmt.Errorf("%s:%d: expected user:hash", h.path, line)
+		}
+		if !strings.HasPrefix(hash, "$2") && !strings.HasPrefix(hash, "{SHA}") {
+			return fmt.Errorf("%s:%d: unsupported hash for %s, use bcrypt (htpasswd -B)", h.path, line, user)
+		}
+		users[user] = hash
+	}
+	h.users = users
+	return nil
+}
+
+// checkHtpasswd compares password against a bcrypt or {SHA} hash
+func checkHtpasswd(hash, password string) bool {
+	if sha, ok := strings.CutPrefix(hash, "{SHA}"); ok {
+		sum := sha1.Sum([]byte(password))
+		want := base64.StdEncoding.EncodeToString(sum[:])
+		return subtle.ConstantTimeCompare([]byte(sha), []byte(want)) == 1
+	}
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
@@ -0,0 +1,79 @@
+// File: backend/internal/api/middleware/identity.go
+package middleware
+
+import (
+	"crypto/x509"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// LocalIdentity is the Locals key holding the *Identity of an authenticated
+// client
+const LocalIdentity = "identity"
+
+// Authentication methods recorded in Identity.Method
+const (
+	MethodClientCert = "mtls"
+)
+
+// Identity is the authenticated client behind a request
+type Identity struct {
+	// Subject names the client, e.g. a certificate's common name
+	Subject string
+	// Method is how the client authenticated
+	Method string
+	// Roles are asserted by the credential itself, e.g. a JWT claim
+	Roles []string
+}
+
+// String returns the identity in the form method:subject, as recorded in the
+// audit log
+func (i *Identity) String() string {
+	return i.Method + ":" + i.Subject
+}
+
+// IdentityFrom returns the authenticated identity of the request, or nil for
+// anonymous clients
+func IdentityFrom(c *fiber.Ctx) *Identity {
+	id, _ := c.Locals(LocalIdentity).(*Identity)
+	return id
+}
+
+// ClientKey identifies the client for rate limiting and auditing: the
+// authenticated identity when there is one, otherwise the client address
+func ClientKey(c *fiber.Ctx) string {
+	if id := IdentityFrom(c); id != nil {
+		return id.String()
+	}
+	return ClientIP(c)
+}
+
+// ClientCertificate records the identity of clients that presented a
+// verified TLS client certificate
+func ClientCertificate() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		state := c.Context().TLSConnectionState()
+		if state != nil && len(state.VerifiedChains) > 0 {
+			c.Locals(LocalIdentity, &Identity{
+				Subject: certificateSubject(state.VerifiedChains[0][0]),
+				Method:  MethodClientCert,
+			})
+		}
+		return c.Next()
+	}
+}
+
+// certificateSubject names a client certificate by its common name, falling
+// back to its first email or DNS name
+func certificateSubject(cert *x509.Certificate) string {
+	switch {
+	case cert.Subject.CommonName != "":
+		return cert.Subject.CommonName
+	case len(cert.EmailAddresses) > 0:
+		return cert.EmailAddresses[0]
+	case len(cert.DNSNames) > 0:
+		return cert.DNSNames[0]
+	default:
+		return cert.Subject.String()
+	}
+}
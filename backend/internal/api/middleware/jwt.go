@@ -0,0 +1,267 @@
+// File: backend/internal/api/middleware/jwt.go
+package middleware
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// jwksFetchTimeout bounds a single JWKS request
+	jwksFetchTimeout = 10 * time.Second
+	// jwksMinRefresh limits refetching the key set when a token names an
+	// unknown key, so forged tokens cannot hammer the identity provider
+	jwksMinRefresh = time.Minute
+)
+
+// jwtAlgorithms are the signing algorithms accepted in tokens
+var jwtAlgorithms = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+// JWTOptions configures validation of OIDC/JWT bearer tokens
+type JWTOptions struct {
+	// JWKSURL is where the identity provider publishes its signing keys
+	JWKSURL string
+	// Issuer and Audience, when set, must match the token's iss and aud claims
+	Issuer   string
+	Audience string
+	// SubjectClaim names the claim that identifies the user, "sub" by default
+	SubjectClaim string
+	// RolesClaim, when set, names a claim listing the user's roles
+	RolesClaim string
+	// RefreshInterval is how often the key set is fetched again
+	RefreshInterval time.Duration
+	// Client is used to fetch the key set, http.DefaultClient by default
+	Client *http.Client
+}
+
+// JWT authenticates bearer tokens signed by a key in a JWKS
+type JWT struct {
+	opts   JWTOptions
+	parser *jwt.Parser
+
+	mu        sync.Mutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+}
+
+// NewJWT creates a JWT authenticator. The key set is fetched on first use.
+func NewJWT(opts JWTOptions) *JWT {
+	if opts.SubjectClaim == "" {
+		opts.SubjectClaim = "sub"
+	}
+	if opts.Client == nil {
+		opts.Client = http.DefaultClient
+	}
+	parserOpts := []jwt.ParserOption{
+		jwt.WithValidMethods(jwtAlgorithms),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(30 * time.Second),
+	}
+	if opts.Issuer != "" {
+		parserOpts = append(parserOpts, jwt.WithIssuer(opts.Issuer))
+	}
+	if opts.Audience != "" {
+		parserOpts = append(parserOpts, jwt.WithAudience(opts.Audience))
+	}
+	return &JWT{opts: opts, parser: jwt.NewParser(parserOpts...)}
+}
+
+// Authenticate implements Authenticator
+func (j *JWT) Authenticate(c *fiber.Ctx) (*Identity, error) {
+	token := bearerToken(c)
+	if token == "" || !looksLikeJWT(token) {
+		return nil, nil
+	}
+
+	claims := jwt.MapClaims{}
+	if _, err := j.parser.ParseWithClaims(token, claims, j.keyFunc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+	subject, _ := claims[j.opts.SubjectClaim].(string)
+	if subject == "" {
+		return nil, fmt.Errorf("%w: token has no %s claim", ErrInvalidCredentials, j.opts.SubjectClaim)
+	}
+	id := &Identity{Subject: subject, Method: MethodJWT}
+	if j.opts.RolesClaim != "" {
+		id.Roles = stringList(claims[j.opts.RolesClaim])
+	}
+	return id, nil
+}
+
+// stringList converts a claim holding a string or a list of strings
+func stringList(claim interface{}) []string {
+	switch v := claim.(type) {
+	case string:
+		return strings.Fields(v)
+	case []interface{}:
+		var out []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// keyFunc returns the public key named by the token's kid header
+func (j *JWT) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	stale := j.opts.RefreshInterval > 0 && time.Since(j.fetchedAt) > j.opts.RefreshInterval
+	_, known := j.lookupLocked(kid)
+	if j.keys == nil || stale || (!known && time.Since(j.fetchedAt) > jwksMinRefresh) {
+		if err := j.fetchLocked(); err != nil {
+			// Keep validating against the previous keys if the provider is
+			// briefly unavailable
+			slog.Error("fetching JWKS", "url", j.opts.JWKSURL, "error", err)
+			if j.keys == nil {
+				return nil, err
+			}
+		}
+	}
+
+	key, ok := j.lookupLocked(kid)
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return key, nil
+}
+
+// lookupLocked finds a key by ID. A token without a kid matches a key set
+// containing a single key. The caller must hold j.mu.
+func (j *JWT) lookupLocked(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(j.keys) == 1 {
+		for _, key := range j.keys {
+			return key, true
+		}
+	}
+	key, ok := j.keys[kid]
+	return key, ok
+}
+
+// jwk is a single JSON Web Key
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// fetchLocked downloads the key set. The caller must hold j.mu.
+func (j *JWT) fetchLocked() error {
+	j.fetchedAt = time.Now()
+
+	ctx, cancel := context.WithTimeout(context.Background(), jwksFetchTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.opts.JWKSURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := j.opts.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return err
+	}
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			slog.Warn("skipping JWKS key", "kid", k.Kid, "error", err)
+			continue
+		}
+		keys[k.Kid] = key
+	}
+	if len(keys) == 0 {
+		return errors.New("key set contains no usable signing keys")
+	}
+	j.keys = keys
+	return nil
+}
+
+// publicKey decodes an RSA or EC key
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,83 @@
+// File: backend/internal/api/middleware/origin.go
+package middleware
+
+import (
+	"log/slog"
+	"net/url"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/websocket/v2"
+)
+
+// OriginPolicy decides which web origins may call the API from a browser.
+// Entries are exact origins such as https://tools.example.com, origins with
+// a wildcard subdomain such as https://*.example.com, or "*" for any origin.
+type OriginPolicy struct {
+	any      bool
+	exact    map[string]bool
+	suffixes []originSuffix
+}
+
+// originSuffix matches scheme://*.domain entries
+type originSuffix struct {
+	scheme string
+	suffix string
+}
+
+// NewOriginPolicy compiles a list of allowed origins
+func NewOriginPolicy(origins []string) *OriginPolicy {
+	p := &OriginPolicy{exact: make(map[string]bool)}
+	for _, origin := range origins {
+		origin = strings.ToLower(strings.TrimSuffix(origin, "/"))
+		if origin == "*" {
+			p.any = true
+			continue
+		}
+		if scheme, host, ok := strings.Cut(origin, "://*."); ok {
+			p.suffixes = append(p.suffixes, originSuffix{scheme: scheme, suffix: "." + host})
+			continue
+		}
+		p.exact[origin] = true
+	}
+	return p
+}
+
+// Allowed reports whether a browser page at origin may use the API
+func (p *OriginPolicy) Allowed(origin string) bool {
+	if p.any {
+		return true
+	}
+	origin = strings.ToLower(origin)
+	if p.exact[origin] {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	for _, s := range p.suffixes {
+		if u.Scheme == s.scheme && strings.HasSuffix(u.Host, s.suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckOrigin rejects WebSocket upgrades from browser pages on origins that
+// are not allowed, preventing cross-site WebSocket hijacking. The Host
+// header is not trusted to vouch for an origin, as DNS rebinding puts both
+// under an attacker's control. Requests without an Origin header come from
+// non-browser clients and are allowed.
+func CheckOrigin(policy *OriginPolicy) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		origin := c.Get(fiber.HeaderOrigin)
+		if origin == "" || !websocket.IsWebSocketUpgrade(c) || policy.Allowed(origin) {
+			return c.Next()
+		}
+		slog.Warn("websocket origin rejected", "origin", origin, "clientIP", ClientIP(c), "path", c.Path())
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "origin not allowed",
+		})
+	}
+}
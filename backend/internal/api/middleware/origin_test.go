@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestCheckOrigin(t *testing.T) {
+	policy := NewOriginPolicy([]string{"https://tools.example.com", "https://*.corp.example"})
+	app := fiber.New()
+	app.Get("/ws", CheckOrigin(policy), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	tests := []struct {
+		name       string
+		origin     string
+		wantStatus int
+	}{
+		{"No origin", "", fiber.StatusOK},
+		{"Same host, not listed", "http://nettools.local", fiber.StatusForbidden},
+		{"Allowed origin", "https://tools.example.com", fiber.StatusOK},
+		{"Allowed subdomain", "https://noc.corp.example", fiber.StatusOK},
+		{"Wrong scheme", "http://tools.example.com", fiber.StatusForbidden},
+		{"Bare wildcard domain", "https://corp.example", fiber.StatusForbidden},
+		{"Lookalike domain", "https://evilcorp.example", fiber.StatusForbidden},
+		{"Foreign origin", "https://attacker.test", fiber.StatusForbidden},
+		{"Null origin", "null", fiber.StatusForbidden},
+		{"Rebound host", "http://evil.example:8080", fiber.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host := "nettools.local"
+			if u, err := url.Parse(tt.origin); err == nil && u.Host != "" {
+				// The Host header matches the origin, as under DNS rebinding
+				host = u.Host
+			}
+			req := httptest.NewRequest(http.MethodGet, "http://"+host+"/ws", nil)
+			req.Header.Set("Connection", "Upgrade")
+			req.Header.Set("Upgrade", "websocket")
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+}
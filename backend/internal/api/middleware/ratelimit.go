@@ -3,7 +3,6 @@ package middleware
 
 import (
 	"sync"
-	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"golang.org/x/time/rate"
@@ -14,9 +13,14 @@ type IPRateLimiter struct {
 	mu  *sync.RWMutex
 	r   rate.Limit
 	b   int
+
+	// onReject is called with the client key of every rejected request
+	onReject func(key string)
 }
 
-func newIPRateLimiter(r rate.Limit, b int) *IPRateLimiter {
+// NewIPRateLimiter creates a limiter allowing r events per second per IP
+// with bursts of up to b
+func NewIPRateLimiter(r rate.Limit, b int) *IPRateLimiter {
 	return &IPRateLimiter{
 		ips: make(map[string]*rate.Limiter),
 		mu:  &sync.RWMutex{},
@@ -38,14 +42,43 @@ func (i *IPRateLimiter) getLimiter(ip string) *rate.Limiter {
 	return limiter
 }
 
-// RateLimit creates a new rate limiting middleware
-func RateLimit() fiber.Handler {
-	// Create a new limiter for 10 requests per minute
-	limiter := newIPRateLimiter(rate.Every(time.Minute/10), 1)
+// SetLimit changes the rate and burst for every client, including clients
+// that already have a limiter
+func (i *IPRateLimiter) SetLimit(r rate.Limit, b int) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.r = r
+	i.b = b
+	for _, limiter := range i.ips {
+		limiter.SetLimit(r)
+		limiter.SetBurst(b)
+	}
+}
+
+// OnReject registers fn to be called whenever a request is rejected. It must
+// be set before the limiter is in use.
+func (i *IPRateLimiter) OnReject(fn func(key string)) {
+	i.onReject = fn
+}
+
+// Allow reports whether the client identified by key, normally its address,
+// may make another request now
+func (i *IPRateLimiter) Allow(key string) bool {
+	if i.getLimiter(key).Allow() {
+		return true
+	}
+	if i.onReject != nil {
+		i.onReject(key)
+	}
+	return false
+}
 
+// RateLimit creates a new rate limiting middleware. Authenticated clients are
+// limited by identity rather than address.
+func RateLimit(limiter *IPRateLimiter) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		ip := c.IP()
-		if !limiter.getLimiter(ip).Allow() {
+		if !limiter.Allow(ClientKey(c)) {
 			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
 				"error": "too many requests",
 			})
@@ -0,0 +1,138 @@
+// File: backend/internal/api/middleware/realip.go
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// TrustedProxies is the set of reverse proxies allowed to report the client
+// address of the requests they forward
+type TrustedProxies struct {
+	nets []*net.IPNet
+}
+
+// NewTrustedProxies parses a list of IP addresses and CIDRs
+func NewTrustedProxies(entries []string) (*TrustedProxies, error) {
+	t := &TrustedProxies{}
+	for _, entry := range entries {
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", entry)
+			}
+			bits := 8 * len(ip.To16())
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 32
+			}
+			entry = fmt.Sprintf("%s/%d", ip, bits)
+		}
+		_, ipnet, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q", entry)
+		}
+		t.nets = append(t.nets, ipnet)
+	}
+	return t, nil
+}
+
+// Contains reports whether ip belongs to a trusted proxy
+func (t *TrustedProxies) Contains(ip net.IP) bool {
+	if t == nil || ip == nil {
+		return false
+	}
+	for _, n := range t.nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP returns the address of the client behind a request, as resolved by
+// RealIP, falling back to the remote address of the connection
+func ClientIP(c *fiber.Ctx) string {
+	if ip, ok := c.Locals(LocalClientIP).(string); ok && ip != "" {
+		return ip
+	}
+	return c.IP()
+}
+
+// RealIP resolves the client address of every request. Only header, either
+// X-Forwarded-For or Forwarded, is read, and only when the connection comes
+// from a trusted proxy; the other header may come from the client unchanged.
+// All occurrences of the header are joined, and the chain they carry is
+// walked from the nearest hop outwards, stopping at the first address that
+// is not itself a trusted proxy.
+func RealIP(trusted *TrustedProxies, header string) fiber.Handler {
+	forwarded := strings.EqualFold(header, fiber.HeaderForwarded)
+	return func(c *fiber.Ctx) error {
+		var values []string
+		for _, v := range c.Request().Header.PeekAll(header) {
+			values = append(values, string(v))
+		}
+		c.Locals(LocalClientIP, resolveClientIP(trusted, c.Context().RemoteIP(), forwarded, strings.Join(values, ",")))
+		return c.Next()
+	}
+}
+
+// resolveClientIP picks the client address from the peer and the value of
+// its forwarding header, a Forwarded header if forwarded is set and
+// X-Forwarded-For otherwise
+func resolveClientIP(trusted *TrustedProxies, peer net.IP, forwarded bool, value string) string {
+	client := peer
+	if !trusted.Contains(peer) || value == "" {
+		return client.String()
+	}
+
+	hops := strings.Split(value, ",")
+	if forwarded {
+		hops = forwardedFor(value)
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := parseHop(hops[i])
+		if ip == nil {
+			// Obfuscated or malformed hops end the chain; the proxy that
+			// appended them is the best address we know
+			break
+		}
+		client = ip
+		if !trusted.Contains(ip) {
+			break
+		}
+	}
+	return client.String()
+}
+
+// forwardedFor returns the for= node of every element of an RFC 7239
+// Forwarded header, in order
+func forwardedFor(header string) []string {
+	var nodes []string
+	for _, element := range strings.Split(header, ",") {
+		node := ""
+		for _, pair := range strings.Split(element, ";") {
+			key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if ok && strings.EqualFold(key, "for") {
+				node = strings.Trim(value, `"`)
+			}
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// parseHop parses an address from a forwarding header, which may carry a
+// port and, for IPv6, brackets
+func parseHop(hop string) net.IP {
+	hop = strings.TrimSpace(hop)
+	if ip := net.ParseIP(hop); ip != nil {
+		return ip
+	}
+	if host, _, err := net.SplitHostPort(hop); err == nil {
+		return net.ParseIP(host)
+	}
+	return net.ParseIP(strings.Trim(hop, "[]"))
+}
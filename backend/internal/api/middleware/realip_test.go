@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"io"
+	"net"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestResolveClientIP(t *testing.T) {
+	trusted, err := NewTrustedProxies([]string{"10.0.0.0/8", "192.0.2.10"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		peer      string
+		forwarded bool
+		value     string
+		want      string
+	}{
+		{"Direct client", "203.0.113.5", false, "", "203.0.113.5"},
+		{"Untrusted peer spoofing", "203.0.113.5", false, "198.51.100.7", "203.0.113.5"},
+		{"Trusted proxy", "10.1.2.3", false, "198.51.100.7", "198.51.100.7"},
+		{"Trusted chain", "10.1.2.3", false, "198.51.100.7, 192.0.2.10, 10.4.4.4", "198.51.100.7"},
+		{"Spoofed leftmost hop", "10.1.2.3", false, "1.1.1.1, 198.51.100.7", "198.51.100.7"},
+		{"Proxy without header", "10.1.2.3", false, "", "10.1.2.3"},
+		{"Malformed hop", "10.1.2.3", false, "garbage", "10.1.2.3"},
+		{"Forwarded", "10.1.2.3", true, `for=198.51.100.7;proto=https, for="10.4.4.4:8080"`, "198.51.100.7"},
+		{"Forwarded IPv6", "10.1.2.3", true, `for="[2001:db8:cafe::17]:4711"`, "2001:db8:cafe::17"},
+		{"Forwarded obfuscated", "10.1.2.3", true, `for=_hidden`, "10.1.2.3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := resolveClientIP(trusted, net.ParseIP(tt.peer), tt.forwarded, tt.value)
+			if got != tt.want {
+				t.Errorf("client = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRealIPHeader(t *testing.T) {
+	// app.Test connects from 0.0.0.0
+	trusted, err := NewTrustedProxies([]string{"0.0.0.0", "10.0.0.0/8"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		header  string
+		headers [][2]string
+		want    string
+	}{
+		{"Client supplied Forwarded ignored", "X-Forwarded-For",
+			[][2]string{{"Forwarded", "for=1.2.3.4"}, {"X-Forwarded-For", "198.51.100.7"}}, "198.51.100.7"},
+		{"Client supplied X-Forwarded-For ignored", "Forwarded",
+			[][2]string{{"X-Forwarded-For", "1.2.3.4"}, {"Forwarded", "for=198.51.100.7"}}, "198.51.100.7"},
+		{"Repeated header joined", "X-Forwarded-For",
+			[][2]string{{"X-Forwarded-For", "1.2.3.4"}, {"X-Forwarded-For", "198.51.100.7, 10.4.4.4"}}, "198.51.100.7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Use(RealIP(trusted, tt.header))
+			app.Get("/", func(c *fiber.Ctx) error { return c.SendString(ClientIP(c)) })
+
+			req := httptest.NewRequest("GET", "/", nil)
+			for _, h := range tt.headers {
+				req.Header.Add(h[0], h[1])
+			}
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			if got := string(body); got != tt.want {
+				t.Errorf("client = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -5,10 +5,16 @@ import (
 	"github.com/gofiber/websocket/v2"
 )
 
+// LocalClientIP is the Locals key holding the client address resolved by
+// RealIP. Upgraded WebSocket connections keep it, as the request itself is
+// not otherwise available on the connection.
+const LocalClientIP = "clientIP"
+
 func WebSocketUpgrade() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if websocket.IsWebSocketUpgrade(c) {
 			c.Locals("allowed", true)
+			c.Locals(LocalClientIP, ClientIP(c))
 			return c.Next()
 		}
 		return fiber.ErrUpgradeRequired
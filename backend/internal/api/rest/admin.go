@@ -0,0 +1,69 @@
+// File: backend/internal/api/rest/admin.go
+package rest
+
+import (
+	"crypto/subtle"
+
+	"backend/internal/config"
+
+	"github.com/gofiber/fiber/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// AdminHandler serves administrative endpoints
+type AdminHandler struct {
+	config func() *config.Config
+}
+
+// NewAdminHandler creates an admin handler reading the effective
+// configuration from cfg
+func NewAdminHandler(cfg func() *config.Config) *AdminHandler {
+	return &AdminHandler{config: cfg}
+}
+
+// Register mounts the admin routes on router
+func (h *AdminHandler) Register(router fiber.Router) {
+	router.Get("/admin/config", h.requireToken, h.getConfig)
+}
+
+// requireToken checks the admin token. Without a configured token the admin
+// endpoints are disabled.
+func (h *AdminHandler) requireToken(c *fiber.Ctx) error {
+	token := h.config().Admin.Token
+	if token == "" {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "admin endpoints are disabled",
+		})
+	}
+	if !hasToken(c, token) {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "unauthorized",
+		})
+	}
+	return c.Next()
+}
+
+// AdminToken returns a check for requests carrying the configured admin
+// token. Without a configured token no request is an administrator's.
+func AdminToken(cfg func() *config.Config) func(c *fiber.Ctx) bool {
+	return func(c *fiber.Ctx) bool {
+		token := cfg().Admin.Token
+		return token != "" && hasToken(c, token)
+	}
+}
+
+// hasToken reports whether the request carries token in the X-Admin-Token
+// header, which accompanies the client's own credentials
+func hasToken(c *fiber.Ctx, token string) bool {
+	return subtle.ConstantTimeCompare([]byte(c.Get("X-Admin-Token")), []byte(token)) == 1
+}
+
+// getConfig renders the effective configuration as YAML with secrets redacted
+func (h *AdminHandler) getConfig(c *fiber.Ctx) error {
+	out, err := yaml.Marshal(h.config().Redacted())
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	c.Set(fiber.HeaderContentType, "application/yaml; charset=utf-8")
+	return c.Send(out)
+}
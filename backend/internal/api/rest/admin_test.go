@@ -0,0 +1,48 @@
+// File: backend/internal/api/rest/admin_test.go
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"backend/internal/config"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestAdminConfigAccess(t *testing.T) {
+	tests := []struct {
+		name    string
+		token   string
+		headers map[string]string
+		want    int
+	}{
+		{"No token configured", "", nil, http.StatusNotFound},
+		{"Missing header", "s3cret", nil, http.StatusUnauthorized},
+		{"Wrong token", "s3cret", map[string]string{"X-Admin-Token": "guess"}, http.StatusUnauthorized},
+		{"Bearer token", "s3cret", map[string]string{"Authorization": "Bearer s3cret"}, http.StatusUnauthorized},
+		{"Admin token header", "s3cret", map[string]string{"X-Admin-Token": "s3cret"}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			cfg.Admin.Token = tt.token
+			app := fiber.New()
+			NewAdminHandler(func() *config.Config { return cfg }).Register(app)
+
+			req := httptest.NewRequest(http.MethodGet, "/admin/config", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			resp, err := app.Test(req, -1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				t.Errorf("status %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+// File: backend/internal/api/rest/baselines.go
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+
+	"backend/internal/store"
+	"backend/internal/tools"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// baselineNameRegex limits baseline names to what fits in a URL path
+var baselineNameRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+// errBaselineNotFound is returned for unknown baseline names
+var errBaselineNotFound = errors.New("baseline not found")
+
+// BaselineRequest is the body of POST /api/v1/baselines
+type BaselineRequest struct {
+	Name string `json:"name"`
+	// JobID is a finished dig, dig batch or dns-diff job whose answers are
+	// saved
+	JobID string `json:"jobId"`
+}
+
+// baselineDiff is the response of GET /api/v1/baselines/:name/diff
+type baselineDiff struct {
+	Baseline string `json:"baseline"`
+	JobID    string `json:"jobId"`
+	*tools.DNSDiff
+}
+
+// listBaselines returns every baseline
+func (h *Handler) listBaselines(c *fiber.Ctx) error {
+	baselines, err := h.history.Baselines()
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"baselines": baselines})
+}
+
+// createBaseline saves the DNS answers of a finished job under a name. Only
+// a client that may access the job may save it, as baselines are visible to
+// every client.
+func (h *Handler) createBaseline(c *fiber.Ctx) error {
+	var req BaselineRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+	if !baselineNameRegex.MatchString(req.Name) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "name must be 1 to 64 letters, digits, dots, dashes or underscores",
+			"field": "name",
+		})
+	}
+
+	rec, err := h.finishedJob(c, req.JobID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	answers, err := tools.DNSAnswers(rec.Tool, rec.Result)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "job has no DNS answers: " + err.Error(),
+			"field": "jobId",
+		})
+	}
+
+	baseline := &store.Baseline{
+		DNSBaseline: tools.DNSBaseline{Name: req.Name, Nameserver: jobNameserver(rec), Answers: answers},
+		JobID:       rec.ID,
+		CreatedBy:   requester(c),
+	}
+	if err := h.history.CreateBaseline(baseline); err != nil {
+		if errors.Is(err, store.ErrExists) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "baseline already exists",
+			})
+		}
+		return errorResponse(c, err)
+	}
+	c.Location("/api/v1/baselines/" + baseline.Name)
+	return c.Status(fiber.StatusCreated).JSON(baseline)
+}
+
+func (h *Handler) getBaseline(c *fiber.Ctx) error {
+	baseline, err := h.baseline(c.Params("name"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	return c.JSON(baseline)
+}
+
+// deleteBaseline removes a baseline. Only its creator may delete it;
+// anonymous baselines belong to everyone.
+func (h *Handler) deleteBaseline(c *fiber.Ctx) error {
+	baseline, err := h.baseline(c.Params("name"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	if baseline.CreatedBy != "" && baseline.CreatedBy != requester(c) {
+		return forbidden(c, "only the baseline's creator may delete it")
+	}
+	if err := h.history.DeleteBaseline(baseline.Name); err != nil && !errors.Is(err, store.ErrNotFound) {
+		return errorResponse(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// diffBaseline compares the DNS answers of the finished job named by ?job=
+// against a baseline
+func (h *Handler) diffBaseline(c *fiber.Ctx) error {
+	baseline, err := h.baseline(c.Params("name"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	if c.Query("job") == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "job is required",
+			"field": "job",
+		})
+	}
+	rec, err := h.finishedJob(c, c.Query("job"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	answers, err := tools.DNSAnswers(rec.Tool, rec.Result)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "job has no DNS answers: " + err.Error(),
+			"field": "job",
+		})
+	}
+	return c.JSON(baselineDiff{
+		Baseline: baseline.Name,
+		JobID:    rec.ID,
+		DNSDiff:  tools.DiffDNS(baseline.Answers, answers),
+	})
+}
+
+func (h *Handler) baseline(name string) (*store.Baseline, error) {
+	baseline, err := h.history.Baseline(name)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, errBaselineNotFound
+	}
+	return baseline, err
+}
+
+// jobNameserver returns the nameserver a DNS job queried, empty for the
+// system resolver
+func jobNameserver(rec *store.JobRecord) string {
+	params := rec.Parameters
+	switch rec.Tool {
+	case "batch":
+		params, _ = params["parameters"].(map[string]interface{})
+	case "dns-diff":
+		// A comparison defaults to its baseline's nameserver
+		var r tools.DNSDiffResult
+		if data, err := json.Marshal(rec.Result); err == nil && json.Unmarshal(data, &r) == nil {
+			return r.Nameserver
+		}
+	}
+	nameserver, _ := params["nameserver"].(string)
+	return nameserver
+}
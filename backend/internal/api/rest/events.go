@@ -0,0 +1,101 @@
+// File: backend/internal/api/rest/events.go
+package rest
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	wsHandler "backend/internal/api/websocket"
+	"backend/internal/jobs"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// keepAliveInterval is how often an idle event stream sends a comment line
+const keepAliveInterval = 15 * time.Second
+
+// streamEvents follows a job as Server-Sent Events. Each event carries the
+// same frame the WebSocket API sends, with the job event sequence number as
+// its ID so clients can resume with the Last-Event-ID header.
+func (h *Handler) streamEvents(c *fiber.Ctx) error {
+	job, err := h.liveJob(c, c.Params("id"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	after, err := lastEventID(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid Last-Event-ID",
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		ticker := time.NewTicker(keepAliveInterval)
+		defer ticker.Stop()
+
+		for {
+			events, changed := job.Events(after)
+			for _, ev := range events {
+				if err := writeEvent(w, job, ev); err != nil {
+					return
+				}
+				after = ev.Seq
+				if ev.Type == jobs.EventCompleted {
+					return
+				}
+			}
+
+			select {
+			case <-changed:
+			case <-ticker.C:
+				// Comments keep proxies from timing out and detect disconnects
+				if _, err := w.WriteString(": keep-alive\n\n"); err != nil {
+					return
+				}
+				if err := w.Flush(); err != nil {
+					return
+				}
+			}
+		}
+	})
+	return nil
+}
+
+// lastEventID returns the sequence number a client wants to resume after,
+// from the Last-Event-ID header or the lastEventId query parameter
+func lastEventID(c *fiber.Ctx) (int, error) {
+	id := c.Get("Last-Event-ID")
+	if id == "" {
+		id = c.Query("lastEventId")
+	}
+	if id == "" {
+		return 0, nil
+	}
+	seq, err := strconv.Atoi(id)
+	if err != nil || seq < 0 {
+		return 0, fmt.Errorf("invalid event id %q", id)
+	}
+	return seq, nil
+}
+
+// writeEvent writes a single job event in text/event-stream format
+func writeEvent(w *bufio.Writer, job *jobs.Job, ev jobs.Event) error {
+	frame := wsHandler.EventFrame(job, ev)
+	data, err := json.Marshal(frame)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", ev.Seq, frame.Type, data); err != nil {
+		return err
+	}
+	return w.Flush()
+}
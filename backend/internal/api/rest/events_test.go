@@ -0,0 +1,72 @@
+// File: backend/internal/api/rest/events_test.go
+package rest
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStreamEvents(t *testing.T) {
+	app, h := newTestApp(t)
+	id := finishedJob(t, app, h, "alice")
+
+	tests := []struct {
+		name       string
+		query      string
+		lastID     string
+		wantStatus int
+		wantIDs    []string
+	}{
+		{"Whole stream", "", "", http.StatusOK, []string{"1", "2", "3"}},
+		{"Resume from header", "", "1", http.StatusOK, []string{"2", "3"}},
+		{"Resume from query", "?lastEventId=2", "", http.StatusOK, []string{"3"}},
+		{"Header wins over query", "?lastEventId=0", "2", http.StatusOK, []string{"3"}},
+		{"Invalid ID", "", "abc", http.StatusBadRequest, nil},
+		{"Negative ID", "?lastEventId=-1", "", http.StatusBadRequest, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/jobs/"+id+"/events"+tt.query, nil)
+			req.Header.Set("X-User", "alice")
+			if tt.lastID != "" {
+				req.Header.Set("Last-Event-ID", tt.lastID)
+			}
+			resp, err := app.Test(req, 5000)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status %d, want %d: %s", resp.StatusCode, tt.wantStatus, body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var ids []string
+			completed := 0
+			for _, line := range strings.Split(string(body), "\n") {
+				switch {
+				case strings.HasPrefix(line, "id: "):
+					ids = append(ids, strings.TrimPrefix(line, "id: "))
+				case line == "event: completed":
+					completed++
+				}
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("event IDs = %q, want %q", ids, tt.wantIDs)
+			}
+			if completed != 1 {
+				t.Errorf("got %d completed events, want 1", completed)
+			}
+		})
+	}
+}
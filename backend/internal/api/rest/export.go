@@ -0,0 +1,42 @@
+// File: backend/internal/api/rest/export.go
+package rest
+
+import (
+	"bytes"
+	"fmt"
+
+	"backend/internal/export"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// exportJob renders a finished job's result as a download. The format query
+// parameter selects json (the default), csv, markdown or text.
+func (h *Handler) exportJob(c *fiber.Ctx) error {
+	format, err := export.ParseFormat(c.Query("format"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+			"field": "format",
+		})
+	}
+	snap, output, err := h.lookupJob(c, c.Params("id"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	if !snap.State.Finished() {
+		return errorResponse(c, errNotFinished)
+	}
+
+	var body bytes.Buffer
+	if err := export.Render(&body, format, snap, output); err != nil {
+		return errorResponse(c, err)
+	}
+	name := snap.Tool + "-" + snap.ID
+	if len(snap.ID) > 8 {
+		name = snap.Tool + "-" + snap.ID[:8]
+	}
+	c.Set(fiber.HeaderContentType, format.ContentType())
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s.%s"`, name, format.Extension()))
+	return c.Send(body.Bytes())
+}
@@ -0,0 +1,96 @@
+// File: backend/internal/api/rest/health.go
+package rest
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"backend/internal/tools"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// toolStatusTTL is how long tool checks are cached, since each one runs the
+// tool's binary
+const toolStatusTTL = 30 * time.Second
+
+// HealthHandler serves liveness, readiness and tool status checks
+type HealthHandler struct {
+	registry func() *tools.Registry
+
+	listening atomic.Bool
+	draining  atomic.Bool
+
+	mu        sync.Mutex
+	checked   *tools.Registry
+	statuses  []tools.Status
+	checkedAt time.Time
+}
+
+// NewHealthHandler creates a health handler checking the tools in the
+// registry returned by registry
+func NewHealthHandler(registry func() *tools.Registry) *HealthHandler {
+	return &HealthHandler{registry: registry}
+}
+
+// SetListening records whether the server is accepting connections
+func (h *HealthHandler) SetListening(listening bool) {
+	h.listening.Store(listening)
+}
+
+// SetDraining records that the server is shutting down and should receive no
+// new traffic
+func (h *HealthHandler) SetDraining(draining bool) {
+	h.draining.Store(draining)
+}
+
+// RegisterProbes mounts /healthz and /readyz on router
+func (h *HealthHandler) RegisterProbes(router fiber.Router) {
+	router.Get("/healthz", h.healthz)
+	router.Get("/readyz", h.readyz)
+}
+
+// Register mounts the tool status route on router
+func (h *HealthHandler) Register(router fiber.Router) {
+	router.Get("/tools/status", h.toolStatus)
+}
+
+// healthz reports that the process is alive
+func (h *HealthHandler) healthz(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{"status": "ok"})
+}
+
+// readyz reports whether the server can take traffic
+func (h *HealthHandler) readyz(c *fiber.Ctx) error {
+	checks := map[string]bool{
+		"listener":  h.listening.Load(),
+		"accepting": !h.draining.Load(),
+	}
+	for _, ok := range checks {
+		if !ok {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"status": "unavailable",
+				"checks": checks,
+			})
+		}
+	}
+	return c.JSON(fiber.Map{"status": "ok", "checks": checks})
+}
+
+// toolStatus reports whether each enabled tool can run
+func (h *HealthHandler) toolStatus(c *fiber.Ctx) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	// A reload that enables or disables tools replaces the registry
+	registry := h.registry()
+	if registry != h.checked || time.Since(h.checkedAt) > toolStatusTTL {
+		h.statuses = registry.Status(c.Context())
+		h.checked = registry
+		h.checkedAt = time.Now()
+	}
+	return c.JSON(fiber.Map{
+		"tools":     h.statuses,
+		"checkedAt": h.checkedAt,
+	})
+}
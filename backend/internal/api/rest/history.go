@@ -0,0 +1,62 @@
+// File: backend/internal/api/rest/history.go
+package rest
+
+import (
+	"time"
+
+	"backend/internal/jobs"
+	"backend/internal/store"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// searchJobs lists finished jobs from the history, newest first. Filters:
+// tool, target (substring), user (identity or subject), state, schedule,
+// since and until (RFC 3339 times or durations before now, e.g. 24h) and
+// limit. Clients other than administrators only find their own and
+// anonymous jobs.
+func (h *Handler) searchJobs(c *fiber.Ctx) error {
+	q := store.JobQuery{
+		Tool:     c.Query("tool"),
+		Target:   c.Query("target"),
+		User:     c.Query("user"),
+		State:    jobs.State(c.Query("state")),
+		Schedule: c.Query("schedule"),
+		Limit:    c.QueryInt("limit"),
+	}
+	if !h.isAdmin(c) {
+		identity := requester(c)
+		q.VisibleTo = &identity
+	}
+	for name, dst := range map[string]*time.Time{"since": &q.Since, "until": &q.Until} {
+		t, ok := parseTimeQuery(c.Query(name))
+		if !ok {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": name + " must be an RFC 3339 time or a duration",
+				"field": name,
+			})
+		}
+		*dst = t
+	}
+
+	records, err := h.history.SearchJobs(q)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	return c.JSON(fiber.Map{"jobs": records})
+}
+
+// parseTimeQuery accepts an RFC 3339 time or a duration counted back from
+// now. An empty value is the zero time.
+func parseTimeQuery(value string) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, true
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, true
+	}
+	if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return time.Now().Add(-d), true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,226 @@
+// File: backend/internal/api/rest/jobs.go
+package rest
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"backend/internal/api/middleware"
+	"backend/internal/export"
+	"backend/internal/jobs"
+	"backend/internal/schedule"
+	"backend/internal/store"
+	"backend/internal/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// maxWait bounds how long a synchronous job request blocks
+const maxWait = time.Minute
+
+// errNotFinished is returned when sharing or exporting a job that is still
+// running
+var errNotFinished = errors.New("job has not finished")
+
+// JobRequest is the body of POST /api/v1/jobs
+type JobRequest struct {
+	Tool       string                 `json:"tool"`
+	Parameters map[string]interface{} `json:"parameters"`
+}
+
+// Handler serves the job REST API
+type Handler struct {
+	manager *jobs.Manager
+	limiter *middleware.IPRateLimiter
+	// history holds finished jobs, nil when job history is disabled
+	history *store.Store
+	// admin reports whether a request comes from an administrator, who may
+	// access every client's jobs
+	admin func(c *fiber.Ctx) bool
+}
+
+// NewHandler creates a REST handler backed by manager, rate limiting job
+// creation with limiter. Finished jobs are looked up in history once the
+// manager has forgotten them. Clients only see their own and anonymous jobs
+// unless admin reports them to be an administrator.
+func NewHandler(manager *jobs.Manager, limiter *middleware.IPRateLimiter, history *store.Store, admin func(c *fiber.Ctx) bool) *Handler {
+	return &Handler{manager: manager, limiter: limiter, history: history, admin: admin}
+}
+
+// Register mounts the job routes on router
+func (h *Handler) Register(router fiber.Router) {
+	router.Get("/jobs", h.requireHistory, h.searchJobs)
+	router.Post("/jobs", middleware.RateLimit(h.limiter), h.createJob)
+	router.Get("/jobs/:id", h.getJob)
+	router.Get("/jobs/:id/output", h.getOutput)
+	router.Get("/jobs/:id/events", h.streamEvents)
+	router.Get("/jobs/:id/export", h.exportJob)
+	router.Delete("/jobs/:id", h.cancelJob)
+	router.Post("/jobs/:id/links", h.requireHistory, h.createLink)
+	router.Get("/jobs/:id/links", h.requireHistory, h.listLinks)
+	router.Get("/links/:token", h.requireHistory, h.getLink)
+	router.Delete("/links/:token", h.requireHistory, h.revokeLink)
+	router.Get("/baselines", h.requireHistory, h.listBaselines)
+	router.Post("/baselines", h.requireHistory, h.createBaseline)
+	router.Get("/baselines/:name", h.requireHistory, h.getBaseline)
+	router.Delete("/baselines/:name", h.requireHistory, h.deleteBaseline)
+	router.Get("/baselines/:name/diff", h.requireHistory, h.diffBaseline)
+}
+
+// createJob starts a job. With ?wait=true it blocks until the job finishes
+// and responds with the final status, otherwise it responds immediately.
+func (h *Handler) createJob(c *fiber.Ctx) error {
+	var req JobRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	job, err := h.manager.Start(req.Tool, req.Parameters, restClient(c))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	c.Location("/api/v1/jobs/" + job.ID)
+	if !c.QueryBool("wait") {
+		return c.Status(fiber.StatusAccepted).JSON(job.Snapshot())
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), maxWait)
+	defer cancel()
+	select {
+	case <-job.Done():
+		return c.Status(fiber.StatusOK).JSON(job.Snapshot())
+	case <-ctx.Done():
+		return c.Status(fiber.StatusAccepted).JSON(job.Snapshot())
+	}
+}
+
+// restClient describes the client making a REST request
+func restClient(c *fiber.Ctx) jobs.Client {
+	client := jobs.Client{IP: middleware.ClientIP(c), Transport: "rest"}
+	if id := middleware.IdentityFrom(c); id != nil {
+		client.Identity = id.String()
+		client.Roles = id.Roles
+	}
+	return client
+}
+
+func (h *Handler) getJob(c *fiber.Ctx) error {
+	snap, _, err := h.lookupJob(c, c.Params("id"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	return c.JSON(snap)
+}
+
+func (h *Handler) getOutput(c *fiber.Ctx) error {
+	_, output, err := h.lookupJob(c, c.Params("id"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	c.Set(fiber.HeaderContentType, fiber.MIMETextPlainCharsetUTF8)
+	return c.SendString(output)
+}
+
+// lookupJob returns the status and output of a job the manager is tracking,
+// falling back to the history for jobs it has forgotten. Jobs the client may
+// not access are reported as unknown.
+func (h *Handler) lookupJob(c *fiber.Ctx, id string) (jobs.Snapshot, string, error) {
+	job, err := h.manager.Get(id)
+	if errors.Is(err, jobs.ErrNotFound) && h.history != nil {
+		rec, err := h.storedJob(id)
+		if err != nil {
+			return jobs.Snapshot{}, "", err
+		}
+		if !h.ownsJob(c, rec.Client) {
+			return jobs.Snapshot{}, "", jobs.ErrNotFound
+		}
+		return rec.Snapshot, rec.Output, nil
+	}
+	if err != nil {
+		return jobs.Snapshot{}, "", err
+	}
+	snap := job.Snapshot()
+	if !h.ownsJob(c, snap.Client) {
+		return jobs.Snapshot{}, "", jobs.ErrNotFound
+	}
+	return snap, job.Output(), nil
+}
+
+// liveJob returns a job the manager is tracking, reporting jobs the client
+// may not access as unknown
+func (h *Handler) liveJob(c *fiber.Ctx, id string) (*jobs.Job, error) {
+	job, err := h.manager.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if !h.ownsJob(c, job.Snapshot().Client) {
+		return nil, jobs.ErrNotFound
+	}
+	return job, nil
+}
+
+// storedJob looks a job up in the history, reporting a missing record as
+// an unknown job
+func (h *Handler) storedJob(id string) (*store.JobRecord, error) {
+	rec, err := h.history.Job(id)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, jobs.ErrNotFound
+	}
+	return rec, err
+}
+
+func (h *Handler) cancelJob(c *fiber.Ctx) error {
+	if _, err := h.liveJob(c, c.Params("id")); err != nil {
+		return errorResponse(c, err)
+	}
+	job, err := h.manager.Cancel(c.Params("id"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	<-job.Done()
+	return c.JSON(job.Snapshot())
+}
+
+// errorResponse maps job and validation errors to HTTP responses
+func errorResponse(c *fiber.Ctx, err error) error {
+	var valErr *validation.ValidationError
+	var toolErr *jobs.UnknownToolError
+	var forbidden *validation.ForbiddenError
+	var unsupported *export.UnsupportedError
+	switch {
+	case errors.As(err, &valErr):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+			"field": valErr.Field,
+		})
+	case errors.As(err, &forbidden):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error":      err.Error(),
+			"permission": forbidden.Permission,
+		})
+	case errors.As(err, &toolErr), errors.As(err, &unsupported):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, jobs.ErrNotFound), errors.Is(err, schedule.ErrNotFound), errors.Is(err, errBaselineNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, jobs.ErrFinished), errors.Is(err, errNotFinished), errors.Is(err, schedule.ErrLimitReached):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, jobs.ErrShuttingDown):
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+}
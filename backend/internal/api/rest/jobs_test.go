@@ -0,0 +1,182 @@
+// File: backend/internal/api/rest/jobs_test.go
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/internal/api/middleware"
+	"backend/internal/jobs"
+	"backend/internal/store"
+	"backend/internal/tools"
+	"backend/internal/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// echoTool finishes at once, returning its target
+type echoTool struct {
+	target string
+}
+
+func (echoTool) Name() string { return "echo" }
+
+func (echoTool) Prepare(_ *validation.Validator, params map[string]interface{}) (tools.Invocation, error) {
+	target, ok := params["target"].(string)
+	if !ok {
+		return nil, &validation.ValidationError{Field: "target", Message: "target is required"}
+	}
+	return echoTool{target: target}, nil
+}
+
+func (t echoTool) Argv() []string { return []string{"echo", t.target} }
+
+func (t echoTool) Run(_ context.Context, output chan<- string) (interface{}, error) {
+	output <- t.target
+	return t.target, nil
+}
+
+// newTestApp serves the job API, taking the client's identity from the
+// X-User header and treating X-Admin: yes as an administrator
+func newTestApp(t *testing.T) (*fiber.App, *Handler) {
+	t.Helper()
+	s, err := store.Open(filepath.Join(t.TempDir(), "nettools.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	v, err := validation.NewValidator(validation.DefaultPolicy())
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := jobs.NewManager(jobs.Settings{
+		Registry:  tools.NewRegistry(echoTool{}),
+		Validator: v,
+		Limits:    jobs.Limits{MaxConcurrent: 2, Timeout: time.Minute},
+	})
+	admin := func(c *fiber.Ctx) bool { return c.Get("X-Admin") == "yes" }
+	h := NewHandler(m, middleware.NewIPRateLimiter(100, 100), s, admin)
+
+	app := fiber.New()
+	api := app.Group("/api/v1", func(c *fiber.Ctx) error {
+		if user := c.Get("X-User"); user != "" {
+			c.Locals(middleware.LocalIdentity, &middleware.Identity{Method: "basic", Subject: user})
+		}
+		return c.Next()
+	})
+	h.Register(api)
+	return app, h
+}
+
+func doRequest(t *testing.T, app *fiber.App, method, path, user, body string) (int, []byte) {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if user == "admin" {
+		req.Header.Set("X-Admin", "yes")
+	} else if user != "" {
+		req.Header.Set("X-User", user)
+	}
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, data
+}
+
+// finishedJob runs an echo job as user and saves it to the history,
+// returning its ID
+func finishedJob(t *testing.T, app *fiber.App, h *Handler, user string) string {
+	t.Helper()
+	status, body := doRequest(t, app, http.MethodPost, "/api/v1/jobs?wait=true", user, `{"tool":"echo","parameters":{"target":"example.com"}}`)
+	if status != http.StatusOK {
+		t.Fatalf("creating job: status %d: %s", status, body)
+	}
+	var snap jobs.Snapshot
+	if err := json.Unmarshal(body, &snap); err != nil {
+		t.Fatal(err)
+	}
+	job, err := h.manager.Get(snap.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.history.SaveJob(store.NewJobRecord(job)); err != nil {
+		t.Fatal(err)
+	}
+	return snap.ID
+}
+
+func TestJobOwnership(t *testing.T) {
+	app, h := newTestApp(t)
+	id := finishedJob(t, app, h, "alice")
+
+	requests := []struct {
+		method, path, body string
+	}{
+		{http.MethodGet, "/api/v1/jobs/" + id, ""},
+		{http.MethodGet, "/api/v1/jobs/" + id + "/output", ""},
+		{http.MethodGet, "/api/v1/jobs/" + id + "/events", ""},
+		{http.MethodGet, "/api/v1/jobs/" + id + "/export?format=json", ""},
+		{http.MethodDelete, "/api/v1/jobs/" + id, ""},
+		{http.MethodPost, "/api/v1/jobs/" + id + "/links", ""},
+		{http.MethodGet, "/api/v1/jobs/" + id + "/links", ""},
+		{http.MethodPost, "/api/v1/baselines", `{"name":"base","jobId":"` + id + `"}`},
+	}
+	for _, r := range requests {
+		t.Run(r.method+" "+r.path, func(t *testing.T) {
+			if status, body := doRequest(t, app, r.method, r.path, "bob", r.body); status != http.StatusNotFound {
+				t.Errorf("other client: status %d, want 404: %s", status, body)
+			}
+		})
+	}
+
+	for _, user := range []string{"alice", "admin"} {
+		if status, body := doRequest(t, app, http.MethodGet, "/api/v1/jobs/"+id, user, ""); status != http.StatusOK {
+			t.Errorf("%s: status %d, want 200: %s", user, status, body)
+		}
+	}
+}
+
+func TestJobSearchOwnership(t *testing.T) {
+	app, h := newTestApp(t)
+	finishedJob(t, app, h, "alice")
+
+	tests := []struct {
+		name, user, query string
+		want              int
+	}{
+		{"Owner", "alice", "", 1},
+		{"Other client", "bob", "", 0},
+		{"Other client naming owner", "bob", "?user=basic:alice", 0},
+		{"Administrator", "admin", "", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := doRequest(t, app, http.MethodGet, "/api/v1/jobs"+tt.query, tt.user, "")
+			if status != http.StatusOK {
+				t.Fatalf("status %d: %s", status, body)
+			}
+			var resp struct {
+				Jobs []json.RawMessage `json:"jobs"`
+			}
+			if err := json.Unmarshal(body, &resp); err != nil {
+				t.Fatal(err)
+			}
+			if len(resp.Jobs) != tt.want {
+				t.Errorf("got %d jobs, want %d", len(resp.Jobs), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,323 @@
+// File: backend/internal/api/rest/links.go
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"html/template"
+	"strings"
+	"time"
+
+	"backend/internal/api/middleware"
+	"backend/internal/jobs"
+	"backend/internal/store"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// LinkRequest is the body of POST /api/v1/jobs/:id/links
+type LinkRequest struct {
+	// ExpiresIn is a duration such as 24h, empty for a link that never
+	// expires
+	ExpiresIn string `json:"expiresIn"`
+}
+
+// linkResponse is a link together with the URL of its read-only view
+type linkResponse struct {
+	store.Link
+	URL string `json:"url"`
+}
+
+// sharedJob is the view of a job given to anyone holding a permalink. It
+// leaves out the client's address, roles and transport.
+type sharedJob struct {
+	ID         string      `json:"id"`
+	Tool       string      `json:"tool"`
+	Target     string      `json:"target,omitempty"`
+	Argv       []string    `json:"argv"`
+	Identity   string      `json:"identity,omitempty"`
+	State      jobs.State  `json:"state"`
+	CreatedAt  time.Time   `json:"createdAt"`
+	StartedAt  *time.Time  `json:"startedAt,omitempty"`
+	FinishedAt *time.Time  `json:"finishedAt,omitempty"`
+	ExitCode   *int        `json:"exitCode,omitempty"`
+	Error      string      `json:"error,omitempty"`
+	Output     string      `json:"output"`
+	Result     interface{} `json:"result,omitempty"`
+}
+
+func newSharedJob(rec *store.JobRecord) sharedJob {
+	return sharedJob{
+		ID:         rec.ID,
+		Tool:       rec.Tool,
+		Target:     rec.Target,
+		Argv:       rec.Argv,
+		Identity:   rec.Client.Identity,
+		State:      rec.State,
+		CreatedAt:  rec.CreatedAt,
+		StartedAt:  rec.StartedAt,
+		FinishedAt: rec.FinishedAt,
+		ExitCode:   rec.ExitCode,
+		Error:      rec.Error,
+		Output:     rec.Output,
+		Result:     rec.Result,
+	}
+}
+
+// RegisterShare mounts the read-only permalink view on router
+func (h *Handler) RegisterShare(router fiber.Router) {
+	router.Get("/:token", h.requireHistory, h.shareView)
+}
+
+// requireHistory rejects requests when the job history is disabled
+func (h *Handler) requireHistory(c *fiber.Ctx) error {
+	if h.history == nil {
+		return c.Status(fiber.StatusNotImplemented).JSON(fiber.Map{
+			"error": "job history is disabled",
+		})
+	}
+	return c.Next()
+}
+
+// createLink creates a permalink to a finished job. Only a client that may
+// access the job may share it.
+func (h *Handler) createLink(c *fiber.Ctx) error {
+	var req LinkRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid request body",
+			})
+		}
+	}
+	var expiry time.Duration
+	if req.ExpiresIn != "" {
+		d, err := time.ParseDuration(req.ExpiresIn)
+		if err != nil || d <= 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "expiresIn must be a positive duration",
+				"field": "expiresIn",
+			})
+		}
+		expiry = d
+	}
+
+	rec, err := h.finishedJob(c, c.Params("id"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	link, err := h.history.CreateLink(rec.ID, requester(c), expiry)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(linkResponse{Link: *link, URL: shareURL(c, link.Token)})
+}
+
+// listLinks returns the active permalinks to a job
+func (h *Handler) listLinks(c *fiber.Ctx) error {
+	rec, err := h.storedJob(c.Params("id"))
+	if err == nil && !h.ownsJob(c, rec.Client) {
+		err = jobs.ErrNotFound
+	}
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	links, err := h.history.JobLinks(rec.ID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	resp := make([]linkResponse, len(links))
+	for i, link := range links {
+		resp[i] = linkResponse{Link: link, URL: shareURL(c, link.Token)}
+	}
+	return c.JSON(fiber.Map{"links": resp})
+}
+
+// getLink returns the job behind a permalink as JSON, including its output
+func (h *Handler) getLink(c *fiber.Ctx) error {
+	_, rec, err := h.resolveLink(c.Params("token"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	noStore(c)
+	return c.JSON(newSharedJob(rec))
+}
+
+// revokeLink deletes a permalink. The link's creator and the client that ran
+// the job may revoke it; links created anonymously only by the latter.
+func (h *Handler) revokeLink(c *fiber.Ctx) error {
+	link, err := h.history.Link(c.Params("token"))
+	if errors.Is(err, store.ErrNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "link not found",
+		})
+	}
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	allowed := link.CreatedBy != "" && link.CreatedBy == requester(c)
+	if !allowed {
+		if rec, err := h.history.Job(link.JobID); err == nil {
+			allowed = h.ownsJob(c, rec.Client)
+		}
+	}
+	if !allowed {
+		return forbidden(c, "only the link's creator or the job's owner may revoke it")
+	}
+	if err := h.history.RevokeLink(link.Token); err != nil && !errors.Is(err, store.ErrNotFound) {
+		return errorResponse(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// shareView renders a permalink as a read-only HTML page
+func (h *Handler) shareView(c *fiber.Ctx) error {
+	link, rec, err := h.resolveLink(c.Params("token"))
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		if errors.Is(err, jobs.ErrNotFound) {
+			status = fiber.StatusNotFound
+		}
+		return c.Status(status).SendString("This link is invalid, expired or has been revoked.")
+	}
+
+	view := shareViewData{Link: link, Job: rec, Command: strings.Join(rec.Argv, " ")}
+	if rec.StartedAt != nil && rec.FinishedAt != nil {
+		view.Duration = rec.FinishedAt.Sub(*rec.StartedAt).Round(time.Millisecond).String()
+	}
+	if rec.Result != nil {
+		if out, err := json.MarshalIndent(rec.Result, "", "  "); err == nil {
+			view.Result = string(out)
+		}
+	}
+
+	var page strings.Builder
+	if err := shareTemplate.Execute(&page, view); err != nil {
+		return errorResponse(c, err)
+	}
+	noStore(c)
+	c.Set("X-Robots-Tag", "noindex")
+	c.Set("Referrer-Policy", "no-referrer")
+	c.Set(fiber.HeaderContentSecurityPolicy, "default-src 'none'; style-src 'unsafe-inline'")
+	c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	return c.SendString(page.String())
+}
+
+// resolveLink returns a permalink and the job it points to. Unknown, expired
+// and dangling links are all reported as jobs.ErrNotFound.
+func (h *Handler) resolveLink(token string) (*store.Link, *store.JobRecord, error) {
+	link, err := h.history.Link(token)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, nil, jobs.ErrNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	rec, err := h.storedJob(link.JobID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return link, rec, nil
+}
+
+// finishedJob returns the history record of a finished job, saving it first
+// if the job has only just finished. Jobs the client may not access are
+// reported as unknown.
+func (h *Handler) finishedJob(c *fiber.Ctx, id string) (*store.JobRecord, error) {
+	rec, err := h.history.Job(id)
+	if err == nil && !h.ownsJob(c, rec.Client) {
+		return nil, jobs.ErrNotFound
+	}
+	if err == nil || !errors.Is(err, store.ErrNotFound) {
+		return rec, err
+	}
+	job, err := h.liveJob(c, id)
+	if err != nil {
+		return nil, err
+	}
+	if !job.State().Finished() {
+		return nil, errNotFinished
+	}
+	saved := store.NewJobRecord(job)
+	if err := h.history.SaveJob(saved); err != nil {
+		return nil, err
+	}
+	return &saved, nil
+}
+
+// requester returns the identity of the client making the request, empty for
+// anonymous clients
+func requester(c *fiber.Ctx) string {
+	if id := middleware.IdentityFrom(c); id != nil {
+		return id.String()
+	}
+	return ""
+}
+
+// ownsJob reports whether the requesting client may access a job run by
+// client: its own jobs and anonymous ones, which belong to everyone, or
+// every job for administrators
+func (h *Handler) ownsJob(c *fiber.Ctx, client jobs.Client) bool {
+	return client.Identity == "" || client.Identity == requester(c) || h.isAdmin(c)
+}
+
+func (h *Handler) isAdmin(c *fiber.Ctx) bool {
+	return h.admin != nil && h.admin(c)
+}
+
+func forbidden(c *fiber.Ctx, message string) error {
+	return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+		"error": message,
+	})
+}
+
+// shareURL is the absolute URL of a permalink's read-only view
+func shareURL(c *fiber.Ctx, token string) string {
+	return c.BaseURL() + "/share/" + token
+}
+
+func noStore(c *fiber.Ctx) {
+	c.Set(fiber.HeaderCacheControl, "no-store")
+}
+
+// shareViewData is rendered by shareTemplate
+type shareViewData struct {
+	Link     *store.Link
+	Job      *store.JobRecord
+	Command  string
+	Duration string
+	Result   string
+}
+
+var shareTemplate = template.Must(template.New("share").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Job.Tool}} {{.Job.Target}} - Network Tools</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2rem; color: #1f2933; }
+table { border-collapse: collapse; margin-bottom: 1.5rem; }
+th { text-align: left; padding-right: 1.5rem; color: #52606d; font-weight: normal; }
+pre { background: #1f2933; color: #e4e7eb; padding: 1rem; overflow-x: auto; border-radius: 4px; }
+.state-succeeded { color: #147d64; }
+.state-failed, .state-cancelled { color: #ab091e; }
+</style>
+</head>
+<body>
+<h1>{{.Job.Tool}} {{.Job.Target}}</h1>
+<table>
+<tr><th>Command</th><td><code>{{.Command}}</code></td></tr>
+<tr><th>State</th><td class="state-{{.Job.State}}">{{.Job.State}}{{with .Job.ExitCode}} (exit code {{.}}){{end}}</td></tr>
+{{with .Job.Error}}<tr><th>Error</th><td>{{.}}</td></tr>{{end}}
+<tr><th>Started</th><td>{{.Job.CreatedAt.UTC.Format "2006-01-02 15:04:05 MST"}}</td></tr>
+{{with .Duration}}<tr><th>Duration</th><td>{{.}}</td></tr>{{end}}
+{{with .Job.Client.Identity}}<tr><th>Run by</th><td>{{.}}</td></tr>{{end}}
+{{with .Link.ExpiresAt}}<tr><th>Link expires</th><td>{{.UTC.Format "2006-01-02 15:04:05 MST"}}</td></tr>{{end}}
+</table>
+<h2>Output</h2>
+<pre>{{.Job.Output}}</pre>
+{{with .Result}}<h2>Result</h2>
+<pre>{{.}}</pre>{{end}}
+</body>
+</html>
+`))
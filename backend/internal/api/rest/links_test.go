@@ -0,0 +1,61 @@
+// File: backend/internal/api/rest/links_test.go
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestSharedLink(t *testing.T) {
+	app, h := newTestApp(t)
+	id := finishedJob(t, app, h, "alice")
+
+	status, body := doRequest(t, app, http.MethodPost, "/api/v1/jobs/"+id+"/links", "alice", "")
+	if status != http.StatusCreated {
+		t.Fatalf("creating link: status %d: %s", status, body)
+	}
+	var link linkResponse
+	if err := json.Unmarshal(body, &link); err != nil {
+		t.Fatal(err)
+	}
+
+	// Anyone holding the token sees the job, but not the client details
+	status, body = doRequest(t, app, http.MethodGet, "/api/v1/links/"+link.Token, "bob", "")
+	if status != http.StatusOK {
+		t.Fatalf("viewing link: status %d: %s", status, body)
+	}
+	var shared map[string]interface{}
+	if err := json.Unmarshal(body, &shared); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := shared["client"]; ok {
+		t.Errorf("shared job includes the client: %s", body)
+	}
+	if shared["identity"] != "basic:alice" || shared["output"] == "" || shared["argv"] == nil {
+		t.Errorf("shared job = %s", body)
+	}
+
+	// A link created before authentication was enabled has no creator, and
+	// only the job's owner may revoke it
+	legacy, err := h.history.CreateLink(id, "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name, user, token string
+		want              int
+	}{
+		{"Other client", "bob", link.Token, http.StatusForbidden},
+		{"Other client, anonymous link", "bob", legacy.Token, http.StatusForbidden},
+		{"Owner, anonymous link", "alice", legacy.Token, http.StatusNoContent},
+		{"Creator", "alice", link.Token, http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if status, body := doRequest(t, app, http.MethodDelete, "/api/v1/links/"+tt.token, tt.user, ""); status != tt.want {
+				t.Errorf("status %d, want %d: %s", status, tt.want, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+// File: backend/internal/api/rest/schedules.go
+package rest
+
+import (
+	"backend/internal/schedule"
+	"backend/internal/store"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ScheduleRequest is the body of POST /api/v1/schedules and
+// PUT /api/v1/schedules/:id
+type ScheduleRequest struct {
+	Name       string                 `json:"name"`
+	Tool       string                 `json:"tool"`
+	Parameters map[string]interface{} `json:"parameters"`
+	// Cron is a five field cron expression or a macro such as @hourly
+	Cron string `json:"cron"`
+	// Interval is a duration such as 5m, used instead of Cron
+	Interval string `json:"interval"`
+	// Timezone is the IANA zone Cron is evaluated in, UTC when empty
+	Timezone string `json:"timezone"`
+	Paused   bool   `json:"paused"`
+}
+
+// ScheduleHandler serves the scheduled job REST API
+type ScheduleHandler struct {
+	// scheduler is nil when scheduled jobs are disabled
+	scheduler *schedule.Scheduler
+}
+
+// NewScheduleHandler creates a handler managing the schedules of scheduler
+func NewScheduleHandler(scheduler *schedule.Scheduler) *ScheduleHandler {
+	return &ScheduleHandler{scheduler: scheduler}
+}
+
+// Register mounts the schedule routes on router. The jobs a schedule ran
+// are listed by GET /jobs?schedule=:id.
+func (h *ScheduleHandler) Register(router fiber.Router) {
+	router.Get("/schedules", h.requireScheduler, h.listSchedules)
+	router.Post("/schedules", h.requireScheduler, h.createSchedule)
+	router.Get("/schedules/:id", h.requireScheduler, h.getSchedule)
+	router.Put("/schedules/:id", h.requireScheduler, h.updateSchedule)
+	router.Delete("/schedules/:id", h.requireScheduler, h.deleteSchedule)
+	router.Post("/schedules/:id/pause", h.requireScheduler, h.pauseSchedule)
+	router.Post("/schedules/:id/resume", h.requireScheduler, h.resumeSchedule)
+}
+
+// requireScheduler rejects requests when scheduled jobs are disabled
+func (h *ScheduleHandler) requireScheduler(c *fiber.Ctx) error {
+	if h.scheduler == nil {
+		return c.Status(fiber.StatusNotImplemented).JSON(fiber.Map{
+			"error": "scheduled jobs are disabled",
+		})
+	}
+	return c.Next()
+}
+
+// listSchedules returns the schedules owned by the requesting client
+func (h *ScheduleHandler) listSchedules(c *fiber.Ctx) error {
+	all, err := h.scheduler.List()
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	owned := []store.Schedule{}
+	for i := range all {
+		if ownsSchedule(c, &all[i]) {
+			owned = append(owned, all[i])
+		}
+	}
+	return c.JSON(fiber.Map{"schedules": owned})
+}
+
+// createSchedule stores a new schedule owned by the requesting client. Its
+// job is validated now and again every time it runs.
+func (h *ScheduleHandler) createSchedule(c *fiber.Ctx) error {
+	req, ok := parseScheduleRequest(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+	sched := req.schedule()
+	sched.Owner = restClient(c)
+	if err := h.scheduler.Create(&sched); err != nil {
+		return errorResponse(c, err)
+	}
+	c.Location("/api/v1/schedules/" + sched.ID)
+	return c.Status(fiber.StatusCreated).JSON(sched)
+}
+
+func (h *ScheduleHandler) getSchedule(c *fiber.Ctx) error {
+	sched, err := h.ownedSchedule(c)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	return c.JSON(sched)
+}
+
+// updateSchedule replaces what a schedule runs and when
+func (h *ScheduleHandler) updateSchedule(c *fiber.Ctx) error {
+	req, ok := parseScheduleRequest(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+	sched, err := h.ownedSchedule(c)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	updated, err := h.scheduler.Update(sched.ID, req.schedule())
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	return c.JSON(updated)
+}
+
+func (h *ScheduleHandler) deleteSchedule(c *fiber.Ctx) error {
+	sched, err := h.ownedSchedule(c)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	if err := h.scheduler.Delete(sched.ID); err != nil {
+		return errorResponse(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *ScheduleHandler) pauseSchedule(c *fiber.Ctx) error {
+	return h.setPaused(c, true)
+}
+
+func (h *ScheduleHandler) resumeSchedule(c *fiber.Ctx) error {
+	return h.setPaused(c, false)
+}
+
+func (h *ScheduleHandler) setPaused(c *fiber.Ctx, paused bool) error {
+	sched, err := h.ownedSchedule(c)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	updated, err := h.scheduler.SetPaused(sched.ID, paused)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	return c.JSON(updated)
+}
+
+// ownedSchedule returns the schedule named in the path, reporting schedules
+// of other clients as not found
+func (h *ScheduleHandler) ownedSchedule(c *fiber.Ctx) (*store.Schedule, error) {
+	sched, err := h.scheduler.Get(c.Params("id"))
+	if err != nil {
+		return nil, err
+	}
+	if !ownsSchedule(c, sched) {
+		return nil, schedule.ErrNotFound
+	}
+	return sched, nil
+}
+
+// ownsSchedule reports whether the requesting client created the schedule.
+// Anonymous schedules belong to everyone.
+func ownsSchedule(c *fiber.Ctx, sched *store.Schedule) bool {
+	return sched.Owner.Identity == "" || sched.Owner.Identity == requester(c)
+}
+
+func parseScheduleRequest(c *fiber.Ctx) (*ScheduleRequest, bool) {
+	var req ScheduleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return nil, false
+	}
+	return &req, true
+}
+
+// schedule converts the request into the schedule fields a client controls
+func (r *ScheduleRequest) schedule() store.Schedule {
+	return store.Schedule{
+		Name:       r.Name,
+		Tool:       r.Tool,
+		Parameters: r.Parameters,
+		Cron:       r.Cron,
+		Interval:   r.Interval,
+		Timezone:   r.Timezone,
+		Paused:     r.Paused,
+	}
+}
@@ -1,190 +1,228 @@
 package websocket
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
-	"os/exec"
 	"sync"
 	"time"
 
+	"backend/internal/api/middleware"
+	"backend/internal/jobs"
+	"backend/internal/tools"
 	"backend/internal/validation"
 
 	"github.com/gofiber/websocket/v2"
 )
 
+// errRateLimited is sent when a client starts jobs faster than the rate
+// limit allows
+var errRateLimited = errors.New("too many requests")
+
 // CommandRequest represents the incoming WebSocket message structure
 type CommandRequest struct {
 	Type       string                 `json:"type"`
 	Command    string                 `json:"command"`
 	Parameters map[string]interface{} `json:"parameters"`
+	// JobID identifies the job to act on for "cancel" requests
+	JobID string `json:"jobId,omitempty"`
 }
 
+// Frame types sent in CommandResponse.Type
+const (
+	FrameStarted   = "started"
+	FrameOutput    = "output"
+	FrameCompleted = "completed"
+	FrameError     = "error"
+	// FrameShutdown tells clients the server is shutting down. Jobs already
+	// running continue to stream until they finish or the grace period ends.
+	FrameShutdown = "shutdown"
+)
+
 // CommandResponse represents the outgoing WebSocket message structure
 type CommandResponse struct {
-	Output string `json:"output,omitempty"`
-	Error  string `json:"error,omitempty"`
+	Type     string      `json:"type,omitempty"`
+	JobID    string      `json:"jobId,omitempty"`
+	Seq      int         `json:"seq,omitempty"`
+	Output   string      `json:"output,omitempty"`
+	Error    string      `json:"error,omitempty"`
+	Command  []string    `json:"command,omitempty"`
+	State    jobs.State  `json:"state,omitempty"`
+	ExitCode *int        `json:"exitCode,omitempty"`
+	Result   interface{} `json:"result,omitempty"`
 }
 
 // PingParams represents the expected parameters for ping command
-type PingParams struct {
-	Target string `json:"target"`
-	Count  int    `json:"count"`
-}
+type PingParams = tools.PingParams
 
 // DigParams represents the expected parameters for dig command
-type DigParams struct {
-	Domain     string                 `json:"domain"`
-	RecordType string                 `json:"recordType"`
-	Nameserver string                 `json:"nameserver,omitempty"`
-	Parameters map[string]interface{} `json:"parameters"`
-}
+type DigParams = tools.DigParams
 
-// validatePingParams validates ping command parameters
-func validatePingParams(params map[string]interface{}) (*PingParams, error) {
-	var p PingParams
-
-	// Extract and validate target
-	targetRaw, ok := params["target"]
-	if !ok {
-		return nil, &validation.ValidationError{Field: "target", Message: "target is required"}
-	}
-	if target, ok := targetRaw.(string); ok {
-		if err := validation.ValidateTarget(target); err != nil {
-			return nil, err
-		}
-		p.Target = target
-	} else {
-		return nil, &validation.ValidationError{Field: "target", Message: "invalid target format"}
-	}
-
-	// Extract and validate count
-	countRaw, ok := params["count"]
-	if !ok {
-		return nil, &validation.ValidationError{Field: "count", Message: "count is required"}
+// ErrorMessage formats an error for clients, using the field name for
+// validation errors
+func ErrorMessage(err error) string {
+	if valErr, ok := err.(*validation.ValidationError); ok {
+		return fmt.Sprintf("%s: %s", valErr.Field, valErr.Message)
 	}
+	return err.Error()
+}
 
-	// Handle JSON number type conversion
-	var count int
-	switch v := countRaw.(type) {
-	case float64:
-		count = int(v)
-	case int:
-		count = v
-	default:
-		return nil, &validation.ValidationError{Field: "count", Message: "invalid count format"}
+// EventFrame converts a job event into the frame sent to clients
+func EventFrame(job *jobs.Job, ev jobs.Event) CommandResponse {
+	frame := CommandResponse{JobID: ev.JobID, Seq: ev.Seq}
+	switch ev.Type {
+	case jobs.EventStarted:
+		frame.Type = FrameStarted
+		frame.Command = job.Argv
+	case jobs.EventOutput:
+		frame.Type = FrameOutput
+		frame.Output = ev.Data
+	case jobs.EventCompleted:
+		snap := job.Snapshot()
+		frame.Type = FrameCompleted
+		frame.State = snap.State
+		frame.ExitCode = snap.ExitCode
+		frame.Error = snap.Error
+		frame.Result = snap.Result
 	}
+	return frame
+}
 
-	if err := validation.ValidatePingCount(count); err != nil {
-		return nil, err
-	}
-	p.Count = count
+// Handler runs commands received over WebSocket connections and tracks the
+// open connections so they can be notified on shutdown
+type Handler struct {
+	manager *jobs.Manager
+	// limiter bounds how fast each client starts jobs, shared with the REST
+	// API
+	limiter *middleware.IPRateLimiter
+
+	mu    sync.Mutex
+	conns map[*conn]struct{}
+	// streams counts goroutines still forwarding job events to clients
+	streams sync.WaitGroup
+}
 
-	return &p, nil
+// NewHandler returns a WebSocket handler that runs commands through manager,
+// rate limiting each client's jobs with limiter
+func NewHandler(manager *jobs.Manager, limiter *middleware.IPRateLimiter) *Handler {
+	return &Handler{manager: manager, limiter: limiter, conns: make(map[*conn]struct{})}
 }
 
-// validateDigParams validates dig command parameters
-func validateDigParams(params map[string]interface{}) (*DigParams, error) {
-	var d DigParams
+// conn serialises writes to a single WebSocket connection
+type conn struct {
+	ws      *websocket.Conn
+	writeMu sync.Mutex
+}
 
-	// Extract and validate domain
-	domainRaw, ok := params["domain"]
-	if !ok {
-		return nil, &validation.ValidationError{Field: "domain", Message: "domain is required"}
-	}
-	if domain, ok := domainRaw.(string); ok {
-		if err := validation.ValidateDomain(domain); err != nil {
-			return nil, err
-		}
-		d.Domain = domain
-	} else {
-		return nil, &validation.ValidationError{Field: "domain", Message: "invalid domain format"}
+func (c *conn) send(resp CommandResponse) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if err := c.ws.WriteJSON(resp); err != nil {
+		log.Printf("Error writing to websocket: %v", err)
+		return err
 	}
+	return nil
+}
 
-	// Extract and validate record type
-	recordTypeRaw, ok := params["recordType"]
-	if !ok {
-		return nil, &validation.ValidationError{Field: "recordType", Message: "record type is required"}
+// Broadcast sends resp to every open connection
+func (h *Handler) Broadcast(resp CommandResponse) {
+	for _, c := range h.connections() {
+		c.send(resp)
 	}
-	if recordType, ok := recordTypeRaw.(string); ok {
-		if err := validation.ValidateRecordType(recordType); err != nil {
-			return nil, err
-		}
-		d.RecordType = recordType
-	} else {
-		return nil, &validation.ValidationError{Field: "recordType", Message: "invalid record type format"}
+}
+
+// CloseAll waits until ctx is done for job events still being forwarded, then
+// sends a close frame with the given code and reason to every open
+// connection, which ends their read loops
+func (h *Handler) CloseAll(ctx context.Context, code int, reason string) {
+	flushed := make(chan struct{})
+	go func() {
+		h.streams.Wait()
+		close(flushed)
+	}()
+	select {
+	case <-flushed:
+	case <-ctx.Done():
 	}
 
-	// Extract and validate nameserver (optional)
-	if nameserverRaw, ok := params["nameserver"]; ok {
-		if nameserver, ok := nameserverRaw.(string); ok && nameserver != "" {
-			if err := validation.ValidateTarget(nameserver); err != nil {
-				return nil, &validation.ValidationError{Field: "nameserver", Message: "invalid nameserver format"}
-			}
-			d.Nameserver = nameserver
-		}
+	msg := websocket.FormatCloseMessage(code, reason)
+	for _, c := range h.connections() {
+		c.writeMu.Lock()
+		c.ws.WriteControl(websocket.CloseMessage, msg, time.Now().Add(time.Second))
+		c.writeMu.Unlock()
 	}
+}
 
-	// Extract and validate additional parameters
-	if paramsRaw, ok := params["parameters"]; ok {
-		if parameters, ok := paramsRaw.(map[string]interface{}); ok {
-			if err := validation.ValidateDigParameters(parameters); err != nil {
-				return nil, err
-			}
-			d.Parameters = parameters
-		}
+// Connections returns the number of open connections
+func (h *Handler) Connections() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.conns)
+}
+
+func (h *Handler) connections() []*conn {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	conns := make([]*conn, 0, len(h.conns))
+	for c := range h.conns {
+		conns = append(conns, c)
 	}
+	return conns
+}
+
+// Serve handles a single WebSocket connection until it closes
+func (h *Handler) Serve(ws *websocket.Conn) {
+	c := &conn{ws: ws}
+	h.mu.Lock()
+	h.conns[c] = struct{}{}
+	h.mu.Unlock()
+	defer func() {
+		h.mu.Lock()
+		delete(h.conns, c)
+		h.mu.Unlock()
+	}()
 
-	return &d, nil
+	h.handle(c)
 }
 
-// Handler handles WebSocket connections
-func Handler(c *websocket.Conn) {
-	var writeMu sync.Mutex
+// handle serves a single WebSocket connection
+func (h *Handler) handle(conn *conn) {
+	manager := h.manager
+	c := conn.ws
+	ctx, cancel := context.WithCancel(context.Background())
+	client := jobs.Client{Transport: "websocket"}
+	client.IP, _ = c.Locals(middleware.LocalClientIP).(string)
+	if id, ok := c.Locals(middleware.LocalIdentity).(*middleware.Identity); ok {
+		client.Identity = id.String()
+		client.Roles = id.Roles
+	}
+	// Jobs are limited per client like REST requests, by identity when
+	// authenticated and by address otherwise
+	limitKey := client.Identity
+	if limitKey == "" {
+		limitKey = client.IP
+	}
 
-	// Create channels for command output and errors
-	outputChan := make(chan string)
-	errorChan := make(chan error)
-	done := make(chan struct{})
+	// Jobs started on this connection, cancelled when it closes
+	var jobsMu sync.Mutex
+	owned := make(map[string]*jobs.Job)
 
 	defer func() {
-		close(outputChan)
-		close(errorChan)
-		close(done)
+		cancel()
+		jobsMu.Lock()
+		for _, job := range owned {
+			job.Cancel()
+		}
+		jobsMu.Unlock()
 		c.Close()
 	}()
 
-	// Start a goroutine to handle sending messages back to the client
-	go func() {
-		for {
-			select {
-			case <-done:
-				return
-			case output := <-outputChan:
-				writeMu.Lock()
-				if err := c.WriteJSON(CommandResponse{Output: output}); err != nil {
-					log.Printf("Error writing to websocket: %v", err)
-					writeMu.Unlock()
-					return
-				}
-				writeMu.Unlock()
-			case err := <-errorChan:
-				writeMu.Lock()
-				var errMsg string
-				if valErr, ok := err.(*validation.ValidationError); ok {
-					errMsg = fmt.Sprintf("%s: %s", valErr.Field, valErr.Message)
-				} else {
-					errMsg = err.Error()
-				}
-				if err := c.WriteJSON(CommandResponse{Error: errMsg}); err != nil {
-					log.Printf("Error writing error to websocket: %v", err)
-					writeMu.Unlock()
-					return
-				}
-				writeMu.Unlock()
-			}
-		}
-	}()
+	send := conn.send
+	sendError := func(err error) {
+		send(CommandResponse{Type: FrameError, Error: ErrorMessage(err)})
+	}
 
 	// Handle pings
 	go func() {
@@ -193,16 +231,16 @@ func Handler(c *websocket.Conn) {
 
 		for {
 			select {
-			case <-done:
+			case <-ctx.Done():
 				return
 			case <-ticker.C:
-				writeMu.Lock()
+				conn.writeMu.Lock()
 				if err := c.WriteMessage(websocket.PingMessage, nil); err != nil {
 					log.Printf("Error sending ping: %v", err)
-					writeMu.Unlock()
+					conn.writeMu.Unlock()
 					return
 				}
-				writeMu.Unlock()
+				conn.writeMu.Unlock()
 			}
 		}
 	}()
@@ -219,133 +257,49 @@ func Handler(c *websocket.Conn) {
 
 		var cmd CommandRequest
 		if err := json.Unmarshal(msg, &cmd); err != nil {
-			errorChan <- fmt.Errorf("invalid message format: %v", err)
+			sendError(fmt.Errorf("invalid message format: %v", err))
 			continue
 		}
 
-		// Handle the command in a goroutine
-		go func(cmd CommandRequest) {
-			switch cmd.Type {
-			case "ping":
-				params, err := validatePingParams(cmd.Parameters)
-				if err != nil {
-					errorChan <- err
-					return
-				}
-				handlePingCommand(params, outputChan, errorChan)
-			case "dig":
-				params, err := validateDigParams(cmd.Parameters)
-				if err != nil {
-					errorChan <- err
-					return
-				}
-				handleDigCommand(params, outputChan, errorChan)
-			default:
-				errorChan <- fmt.Errorf("unknown command type: %s", cmd.Type)
+		if cmd.Type == "cancel" {
+			jobsMu.Lock()
+			job, ok := owned[cmd.JobID]
+			jobsMu.Unlock()
+			if !ok {
+				sendError(jobs.ErrNotFound)
+				continue
 			}
-		}(cmd)
-	}
-}
-
-func handlePingCommand(params *PingParams, output chan<- string, errChan chan<- error) {
-	// Check if ping command is available
-	pingPath, err := exec.LookPath("ping")
-	if err != nil {
-		errChan <- fmt.Errorf("ping command not available")
-		return
-	}
-
-	// Construct the ping command
-	args := []string{"-c", fmt.Sprintf("%d", params.Count), params.Target}
-	cmd := exec.Command(pingPath, args...)
-
-	// Get command output
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		errChan <- err
-		return
-	}
-
-	// Start the command
-	if err := cmd.Start(); err != nil {
-		errChan <- err
-		return
-	}
-
-	// Read output
-	buf := make([]byte, 1024)
-	for {
-		n, err := stdout.Read(buf)
-		if n > 0 {
-			output <- string(buf[:n])
-		}
-		if err != nil {
-			break
-		}
-	}
-
-	// Wait for command to complete
-	if err := cmd.Wait(); err != nil {
-		errChan <- err
-	}
-}
-
-func handleDigCommand(params *DigParams, output chan<- string, errChan chan<- error) {
-	// Check if dig command is available
-	digPath, err := exec.LookPath("dig")
-	if err != nil {
-		errChan <- fmt.Errorf("dig command not available")
-		return
-	}
-
-	// Start with base arguments
-	args := []string{}
-
-	// Add nameserver if provided
-	if params.Nameserver != "" {
-		args = append(args, fmt.Sprintf("@%s", params.Nameserver))
-	}
-
-	// Add domain and record type
-	args = append(args, params.Domain, params.RecordType)
-
-	// Add additional parameters
-	for key, value := range params.Parameters {
-		if boolVal, ok := value.(bool); ok && boolVal {
-			args = append(args, fmt.Sprintf("+%s", key))
+			job.Cancel()
+			continue
 		}
-	}
-
-	// Execute command
-	cmd := exec.Command(digPath, args...)
-
-	// Get command output
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		errChan <- err
-		return
-	}
-
-	// Start the command
-	if err := cmd.Start(); err != nil {
-		errChan <- err
-		return
-	}
 
-	// Read output
-	buf := make([]byte, 1024)
-	for {
-		n, err := stdout.Read(buf)
-		if n > 0 {
-			output <- string(buf[:n])
+		if !h.limiter.Allow(limitKey) {
+			sendError(errRateLimited)
+			continue
 		}
+		job, err := manager.Start(cmd.Type, cmd.Parameters, client)
 		if err != nil {
-			break
+			sendError(err)
+			continue
 		}
-	}
 
-	// Wait for command to complete
-	if err := cmd.Wait(); err != nil {
-		errChan <- err
+		jobsMu.Lock()
+		owned[job.ID] = job
+		jobsMu.Unlock()
+
+		// Stream the job's events back to the client
+		h.streams.Add(1)
+		go func(job *jobs.Job) {
+			defer h.streams.Done()
+			err := job.Follow(ctx, 0, func(ev jobs.Event) error {
+				return send(EventFrame(job, ev))
+			})
+			if err != nil {
+				job.Cancel()
+			}
+			jobsMu.Lock()
+			delete(owned, job.ID)
+			jobsMu.Unlock()
+		}(job)
 	}
 }
@@ -0,0 +1,629 @@
+// File: backend/internal/config/config.go
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix is prepended to every environment variable override
+const EnvPrefix = "NT"
+
+// Config is the complete server configuration
+type Config struct {
+	Server    ServerConfig    `yaml:"server"`
+	Logging   LoggingConfig   `yaml:"logging"`
+	CORS      CORSConfig      `yaml:"cors"`
+	RateLimit RateLimitConfig `yaml:"rateLimit"`
+	Jobs      JobsConfig      `yaml:"jobs"`
+	Tools     ToolsConfig     `yaml:"tools"`
+	Policy    PolicyConfig    `yaml:"policy"`
+	Admin     AdminConfig     `yaml:"admin"`
+	Reload    ReloadConfig    `yaml:"reload"`
+	Metrics   MetricsConfig   `yaml:"metrics"`
+	Auth      AuthConfig      `yaml:"auth"`
+	RBAC      RBACConfig      `yaml:"rbac"`
+	History   HistoryConfig   `yaml:"history"`
+	Scheduler SchedulerConfig `yaml:"scheduler"`
+	Alerts    AlertsConfig    `yaml:"alerts"`
+}
+
+// ServerConfig controls the HTTP listener
+type ServerConfig struct {
+	Listen       string        `yaml:"listen"`
+	ReadTimeout  time.Duration `yaml:"readTimeout"`
+	WriteTimeout time.Duration `yaml:"writeTimeout"`
+	// ShutdownGrace is how long running jobs may continue after SIGINT or
+	// SIGTERM before they are cancelled
+	ShutdownGrace time.Duration `yaml:"shutdownGrace"`
+	TLS           TLSConfig     `yaml:"tls"`
+	// TrustedProxies lists the addresses or CIDRs of reverse proxies whose
+	// ProxyHeader identifies the client
+	TrustedProxies []string `yaml:"trustedProxies"`
+	// ProxyHeader is the one header the trusted proxies set, X-Forwarded-For
+	// or Forwarded; the other is ignored
+	ProxyHeader string `yaml:"proxyHeader"`
+	// ProxyProtocol accepts a PROXY protocol v1 or v2 header on connections
+	// from trusted proxies
+	ProxyProtocol bool `yaml:"proxyProtocol"`
+}
+
+// TLSConfig enables HTTPS on the listener. The certificate, key and client
+// CA files are reloaded whenever they change.
+type TLSConfig struct {
+	Enabled  bool   `yaml:"enabled"`
+	CertFile string `yaml:"certFile"`
+	KeyFile  string `yaml:"keyFile"`
+	// ClientAuth is none, optional or require. Client certificates must be
+	// issued by a CA in ClientCAFile; the system roots are never trusted.
+	ClientAuth   string `yaml:"clientAuth"`
+	ClientCAFile string `yaml:"clientCAFile"`
+}
+
+// LoggingConfig controls the server log output
+type LoggingConfig struct {
+	// Level is one of debug, info, warn or error
+	Level string `yaml:"level"`
+	// Format is text or json
+	Format string `yaml:"format"`
+	// Audit controls where the command execution log is written
+	Audit AuditConfig `yaml:"audit"`
+}
+
+// AuditConfig writes the command execution log to rotated, hash chained
+// files. When Dir is empty the log is written to stdout instead.
+type AuditConfig struct {
+	Dir         string        `yaml:"dir"`
+	MaxSizeMB   int           `yaml:"maxSizeMB"`
+	RotateEvery time.Duration `yaml:"rotateEvery"`
+	MaxFiles    int           `yaml:"maxFiles"`
+	MaxAge      time.Duration `yaml:"maxAge"`
+}
+
+// CORSConfig controls which web origins may use the HTTP API and open
+// WebSocket connections. Pages served from the same host are always allowed.
+type CORSConfig struct {
+	// AllowOrigins lists exact origins, origins with a wildcard subdomain
+	// such as https://*.example.com, or "*" for any origin
+	AllowOrigins     []string      `yaml:"allowOrigins"`
+	AllowMethods     []string      `yaml:"allowMethods"`
+	AllowHeaders     []string      `yaml:"allowHeaders"`
+	ExposeHeaders    []string      `yaml:"exposeHeaders"`
+	AllowCredentials bool          `yaml:"allowCredentials"`
+	MaxAge           time.Duration `yaml:"maxAge"`
+}
+
+// RateLimitConfig limits how often each client may start jobs
+type RateLimitConfig struct {
+	Requests int           `yaml:"requests"`
+	Period   time.Duration `yaml:"period"`
+	Burst    int           `yaml:"burst"`
+}
+
+// JobsConfig limits job execution across all tools
+type JobsConfig struct {
+	MaxConcurrent int           `yaml:"maxConcurrent"`
+	Timeout       time.Duration `yaml:"timeout"`
+}
+
+// ToolsConfig holds per tool limits
+type ToolsConfig struct {
+	Ping       PingConfig       `yaml:"ping"`
+	Dig        DigConfig        `yaml:"dig"`
+	Batch      BatchConfig      `yaml:"batch"`
+	Sweep      SweepConfig      `yaml:"sweep"`
+	DNSDiff    DNSDiffConfig    `yaml:"dnsDiff"`
+	DNSCompare DNSCompareConfig `yaml:"dnsCompare"`
+	ZoneCheck  ZoneCheckConfig  `yaml:"zoneCheck"`
+}
+
+// PingConfig limits ping invocations
+type PingConfig struct {
+	Enabled  bool `yaml:"enabled"`
+	MinCount int  `yaml:"minCount"`
+	MaxCount int  `yaml:"maxCount"`
+}
+
+// DigConfig limits dig invocations
+type DigConfig struct {
+	Enabled     bool     `yaml:"enabled"`
+	RecordTypes []string `yaml:"recordTypes"`
+	Parameters  []string `yaml:"parameters"`
+}
+
+// BatchConfig limits batch jobs, which run ping or dig against many targets
+type BatchConfig struct {
+	Enabled    bool `yaml:"enabled"`
+	MaxTargets int  `yaml:"maxTargets"`
+	// Concurrency is how many targets of one batch run at once at most,
+	// each beyond the first taking a free job slot
+	Concurrency int `yaml:"concurrency"`
+}
+
+// SweepConfig limits ping sweeps, which ping every host of an IPv4 range
+type SweepConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// MaxHosts caps the size of a swept range, e.g. 254 for a /24
+	MaxHosts int `yaml:"maxHosts"`
+	// Concurrency is how many hosts of one sweep are probed at once at
+	// most, each beyond the first taking a free job slot
+	Concurrency int `yaml:"concurrency"`
+	// Timeout is how long to wait for each host's reply, in whole seconds
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// DNSDiffConfig limits dns-diff jobs, which snapshot DNS answers or compare
+// them against a stored baseline
+type DNSDiffConfig struct {
+	Enabled    bool `yaml:"enabled"`
+	MaxQueries int  `yaml:"maxQueries"`
+	// Concurrency is how many queries of one job run at once at most,
+	// each beyond the first taking a free job slot
+	Concurrency int `yaml:"concurrency"`
+}
+
+// DNSCompareConfig limits dns-compare jobs, which query one name against
+// several nameservers at once
+type DNSCompareConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// MaxServers caps the presets and client supplied nameservers queried
+	// by one job
+	MaxServers int `yaml:"maxServers"`
+	// Presets are nameservers clients can select by name; every preset is
+	// queried unless the client names some
+	Presets []NameserverPreset `yaml:"presets"`
+}
+
+// ZoneCheckConfig limits zone-check jobs, which compare the authoritative
+// nameservers of a zone
+type ZoneCheckConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// MaxServers caps the nameservers queried by one job; a zone's parent
+	// and child NS sets together rarely list more than a dozen
+	MaxServers int `yaml:"maxServers"`
+}
+
+// NameserverPreset is a named nameserver address or host name
+type NameserverPreset struct {
+	Name    string `yaml:"name"`
+	Address string `yaml:"address"`
+}
+
+// PolicyConfig restricts which targets tools may be pointed at. Entries are
+// IP addresses, CIDR ranges or domain names; a domain entry also matches its
+// subdomains. An empty allow list allows every target not denied.
+type PolicyConfig struct {
+	AllowTargets []string `yaml:"allowTargets"`
+	DenyTargets  []string `yaml:"denyTargets"`
+}
+
+// AdminConfig protects the administrative endpoints
+type AdminConfig struct {
+	// Token, when set, must be presented as a bearer token
+	Token string `yaml:"token" secret:"true"`
+}
+
+// ReloadConfig controls reloading the configuration file while running.
+// A reload can always be triggered with SIGHUP.
+type ReloadConfig struct {
+	// Watch reloads the file whenever its contents change
+	Watch bool `yaml:"watch"`
+	// Interval is how often the file is checked for changes
+	Interval time.Duration `yaml:"interval"`
+}
+
+// AuthConfig controls client authentication for the REST API and WebSocket.
+// A client may use any configured method; a verified TLS client certificate
+// also identifies it.
+type AuthConfig struct {
+	// Required rejects anonymous clients
+	Required bool `yaml:"required"`
+	// APIKeys maps the name of each key's owner to the key
+	APIKeys map[string]string `yaml:"apiKeys" secret:"true"`
+	// HtpasswdFile enables HTTP basic authentication against bcrypt hashes
+	HtpasswdFile string    `yaml:"htpasswdFile"`
+	JWT          JWTConfig `yaml:"jwt"`
+}
+
+// JWTConfig validates OIDC/JWT bearer tokens against a provider's JWKS
+type JWTConfig struct {
+	JWKSURL         string        `yaml:"jwksURL"`
+	Issuer          string        `yaml:"issuer"`
+	Audience        string        `yaml:"audience"`
+	SubjectClaim    string        `yaml:"subjectClaim"`
+	RolesClaim      string        `yaml:"rolesClaim"`
+	RefreshInterval time.Duration `yaml:"refreshInterval"`
+}
+
+// RBACConfig limits what each client may run according to its roles
+type RBACConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// DefaultRoles apply to anonymous clients and identities without roles
+	DefaultRoles []string `yaml:"defaultRoles"`
+	// Assignments maps identities, written method:subject as in the audit
+	// log (e.g. jwt:alice@example.com), to role names
+	Assignments map[string][]string   `yaml:"assignments"`
+	Roles       map[string]RoleConfig `yaml:"roles"`
+}
+
+// RoleConfig is the set of permissions granted by a role. Limits here apply
+// on top of the tools and policy sections.
+type RoleConfig struct {
+	// Tools the role may run, or "*" for all
+	Tools []string `yaml:"tools"`
+	// MaxPingCount caps ping counts, 0 for the tools.ping.maxCount limit
+	MaxPingCount int `yaml:"maxPingCount"`
+	// RecordTypes the role may query, empty for all allowed types
+	RecordTypes []string `yaml:"recordTypes"`
+	// DigParameters the role may use, or "*" for all
+	DigParameters []string `yaml:"digParameters"`
+	AllowTargets  []string `yaml:"allowTargets"`
+	DenyTargets   []string `yaml:"denyTargets"`
+}
+
+// MetricsConfig controls the Prometheus endpoint
+type MetricsConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Path    string `yaml:"path"`
+}
+
+// HistoryConfig keeps finished jobs in an embedded database so they can be
+// searched and retrieved after the server has forgotten them
+type HistoryConfig struct {
+	// Path is the database file, empty to disable the history
+	Path string `yaml:"path"`
+	// Retention removes jobs older than this, 0 keeps them forever
+	Retention time.Duration `yaml:"retention"`
+}
+
+// SchedulerConfig controls scheduled jobs, which are kept in the job
+// history database and need it enabled
+type SchedulerConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// MinInterval is the shortest time allowed between two runs of a schedule
+	MinInterval  time.Duration `yaml:"minInterval"`
+	MaxSchedules int           `yaml:"maxSchedules"`
+}
+
+// AlertsConfig raises alerts when the results of finished jobs meet rule
+// conditions and posts them to webhooks
+type AlertsConfig struct {
+	Enabled  bool                     `yaml:"enabled"`
+	Webhooks map[string]WebhookConfig `yaml:"webhooks"`
+	Rules    []AlertRuleConfig        `yaml:"rules"`
+	// RepeatInterval re-sends alerts that are still firing after this long,
+	// 0 to send them once
+	RepeatInterval time.Duration `yaml:"repeatInterval"`
+	// Retries is how often a failed delivery is retried, waiting
+	// RetryBackoff and then twice as long each time
+	Retries      int           `yaml:"retries"`
+	RetryBackoff time.Duration `yaml:"retryBackoff"`
+}
+
+// WebhookConfig is an endpoint alerts are posted to
+type WebhookConfig struct {
+	// URL often embeds a token, as with Slack incoming webhooks
+	URL string `yaml:"url" secret:"true"`
+	// Format is json for the generic payload or slack
+	Format  string            `yaml:"format"`
+	Headers map[string]string `yaml:"headers" secret:"true"`
+	Timeout time.Duration     `yaml:"timeout"`
+}
+
+// AlertRuleConfig is a condition checked against finished jobs
+type AlertRuleConfig struct {
+	Name string `yaml:"name"`
+	// Tool and Target restrict the jobs checked, empty for all
+	Tool   string `yaml:"tool"`
+	Target string `yaml:"target"`
+	// Condition is e.g. "packetLoss > 20%", "avgMs > 150ms",
+	// "status != NOERROR" or "answer changed"
+	Condition string   `yaml:"condition"`
+	Webhooks  []string `yaml:"webhooks"`
+}
+
+var (
+	digParamRegex   = regexp.MustCompile(`^[a-z]+$`)
+	recordTypeRegex = regexp.MustCompile(`^[A-Z0-9]+$`)
+	presetNameRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+	hostnameRegex   = regexp.MustCompile(`^([A-Za-z0-9]([A-Za-z0-9-]*[A-Za-z0-9])?\.)*[A-Za-z0-9]([A-Za-z0-9-]*[A-Za-z0-9])?\.?$`)
+)
+
+// Default returns the built-in configuration
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Listen:        ":8080",
+			ReadTimeout:   time.Minute,
+			WriteTimeout:  time.Minute,
+			ShutdownGrace: 30 * time.Second,
+			ProxyHeader:   "X-Forwarded-For",
+			TLS: TLSConfig{
+				ClientAuth: "none",
+			},
+		},
+		Logging: LoggingConfig{
+			Level:  "info",
+			Format: "text",
+			Audit: AuditConfig{
+				MaxSizeMB:   100,
+				RotateEvery: 24 * time.Hour,
+				MaxFiles:    90,
+				MaxAge:      90 * 24 * time.Hour,
+			},
+		},
+		CORS: CORSConfig{
+			AllowMethods: []string{"GET", "POST", "DELETE", "OPTIONS"},
+			AllowHeaders: []string{"Content-Type", "Authorization", "X-API-Key", "X-Admin-Token"},
+			MaxAge:       10 * time.Minute,
+		},
+		RateLimit: RateLimitConfig{
+			Requests: 10,
+			Period:   time.Minute,
+			Burst:    1,
+		},
+		Jobs: JobsConfig{
+			MaxConcurrent: 10,
+			Timeout:       2 * time.Minute,
+		},
+		Tools: ToolsConfig{
+			Ping: PingConfig{Enabled: true, MinCount: 1, MaxCount: 30},
+			Dig: DigConfig{
+				Enabled:     true,
+				RecordTypes: []string{"A", "AAAA", "MX", "NS", "TXT", "CNAME", "SOA", "PTR"},
+				Parameters:  []string{"short", "trace", "answer"},
+			},
+			Batch:   BatchConfig{Enabled: true, MaxTargets: 50, Concurrency: 5},
+			Sweep:   SweepConfig{Enabled: true, MaxHosts: 254, Concurrency: 32, Timeout: time.Second},
+			DNSDiff: DNSDiffConfig{Enabled: true, MaxQueries: 50, Concurrency: 8},
+			DNSCompare: DNSCompareConfig{Enabled: true, MaxServers: 10, Presets: []NameserverPreset{
+				{Name: "google", Address: "8.8.8.8"},
+				{Name: "cloudflare", Address: "1.1.1.1"},
+				{Name: "quad9", Address: "9.9.9.9"},
+			}},
+			ZoneCheck: ZoneCheckConfig{Enabled: true, MaxServers: 20},
+		},
+		Reload: ReloadConfig{
+			Watch:    true,
+			Interval: 5 * time.Second,
+		},
+		Metrics: MetricsConfig{
+			Enabled: true,
+			Path:    "/metrics",
+		},
+		Auth: AuthConfig{
+			JWT: JWTConfig{
+				SubjectClaim:    "sub",
+				RefreshInterval: time.Hour,
+			},
+		},
+		History: HistoryConfig{
+			Retention: 30 * 24 * time.Hour,
+		},
+		Scheduler: SchedulerConfig{
+			Enabled:      true,
+			MinInterval:  time.Minute,
+			MaxSchedules: 100,
+		},
+		Alerts: AlertsConfig{
+			Retries:      3,
+			RetryBackoff: 2 * time.Second,
+		},
+	}
+}
+
+// Load builds the configuration from the defaults, the YAML file at path (if
+// path is not empty) and NT_* environment variables, then validates it
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading config: %w", err)
+		}
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("parsing config %s: %w", path, err)
+		}
+	}
+
+	if err := applyEnv(cfg, EnvPrefix, os.LookupEnv); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Validate checks the configuration for values the server cannot run with
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.Server.Listen != "", "server.listen must be set")
+	check(c.Server.ReadTimeout > 0, "server.readTimeout must be positive")
+	check(c.Server.WriteTimeout > 0, "server.writeTimeout must be positive")
+	check(c.Server.ShutdownGrace >= 0, "server.shutdownGrace must not be negative")
+	if c.Server.TLS.Enabled {
+		check(c.Server.TLS.CertFile != "", "server.tls.certFile is required when TLS is enabled")
+		check(c.Server.TLS.KeyFile != "", "server.tls.keyFile is required when TLS is enabled")
+		switch c.Server.TLS.ClientAuth {
+		case "none":
+		case "optional", "require":
+			check(c.Server.TLS.ClientCAFile != "", "server.tls.clientCAFile is required when server.tls.clientAuth is %s", c.Server.TLS.ClientAuth)
+		default:
+			check(false, "server.tls.clientAuth must be none, optional or require")
+		}
+	}
+
+	for _, entry := range c.Server.TrustedProxies {
+		_, _, err := net.ParseCIDR(entry)
+		check(err == nil || net.ParseIP(entry) != nil, "server.trustedProxies: %q is not an IP address or CIDR", entry)
+	}
+	check(!c.Server.ProxyProtocol || len(c.Server.TrustedProxies) > 0, "server.proxyProtocol requires server.trustedProxies")
+	check(strings.EqualFold(c.Server.ProxyHeader, "X-Forwarded-For") || strings.EqualFold(c.Server.ProxyHeader, "Forwarded"),
+		"server.proxyHeader must be X-Forwarded-For or Forwarded")
+
+	switch c.Logging.Level {
+	case "debug", "info", "warn", "error":
+	default:
+		check(false, "logging.level must be debug, info, warn or error")
+	}
+	check(c.Logging.Format == "text" || c.Logging.Format == "json", "logging.format must be text or json")
+	check(c.Logging.Audit.MaxSizeMB >= 0, "logging.audit.maxSizeMB must not be negative")
+	check(c.Logging.Audit.RotateEvery >= 0, "logging.audit.rotateEvery must not be negative")
+	check(c.Logging.Audit.MaxFiles >= 0, "logging.audit.maxFiles must not be negative")
+	check(c.Logging.Audit.MaxAge >= 0, "logging.audit.maxAge must not be negative")
+
+	for _, origin := range c.CORS.AllowOrigins {
+		check(origin == "*" || validOrigin(origin), "cors.allowOrigins: %q is not a scheme://host[:port] origin", origin)
+		check(!(origin == "*" && c.CORS.AllowCredentials), "cors.allowCredentials cannot be used with a wildcard origin")
+	}
+	check(len(c.CORS.AllowMethods) > 0, "cors.allowMethods must not be empty")
+	check(c.CORS.MaxAge >= 0, "cors.maxAge must not be negative")
+
+	check(c.RateLimit.Requests > 0, "rateLimit.requests must be positive")
+	check(c.RateLimit.Period > 0, "rateLimit.period must be positive")
+	check(c.RateLimit.Burst > 0, "rateLimit.burst must be positive")
+
+	check(c.Jobs.MaxConcurrent > 0, "jobs.maxConcurrent must be positive")
+	check(c.Jobs.Timeout > 0, "jobs.timeout must be positive")
+
+	check(c.Tools.Ping.MinCount >= 1, "tools.ping.minCount must be at least 1")
+	check(c.Tools.Ping.MaxCount >= c.Tools.Ping.MinCount, "tools.ping.maxCount must not be less than minCount")
+	check(len(c.Tools.Dig.RecordTypes) > 0, "tools.dig.recordTypes must not be empty")
+	check(c.Tools.Batch.MaxTargets >= 1, "tools.batch.maxTargets must be at least 1")
+	check(c.Tools.Batch.Concurrency >= 1, "tools.batch.concurrency must be at least 1")
+	check(c.Tools.Sweep.MaxHosts >= 1 && c.Tools.Sweep.MaxHosts <= 65534, "tools.sweep.maxHosts must be between 1 and 65534")
+	check(c.Tools.Sweep.Concurrency >= 1, "tools.sweep.concurrency must be at least 1")
+	check(c.Tools.Sweep.Timeout >= time.Second && c.Tools.Sweep.Timeout%time.Second == 0, "tools.sweep.timeout must be a whole number of seconds")
+	check(c.Tools.DNSDiff.MaxQueries >= 1, "tools.dnsDiff.maxQueries must be at least 1")
+	check(c.Tools.DNSDiff.Concurrency >= 1, "tools.dnsDiff.concurrency must be at least 1")
+	check(c.Tools.DNSCompare.MaxServers >= 1, "tools.dnsCompare.maxServers must be at least 1")
+	presets := make(map[string]bool)
+	for i, p := range c.Tools.DNSCompare.Presets {
+		check(presetNameRegex.MatchString(p.Name), "tools.dnsCompare.presets[%d]: invalid name %q", i, p.Name)
+		check(!presets[strings.ToLower(p.Name)], "tools.dnsCompare.presets[%d]: duplicate name %q", i, p.Name)
+		presets[strings.ToLower(p.Name)] = true
+		check(net.ParseIP(p.Address) != nil || hostnameRegex.MatchString(p.Address), "tools.dnsCompare.presets[%d]: %q is not an IP address or host name", i, p.Address)
+	}
+	check(c.Tools.ZoneCheck.MaxServers >= 1, "tools.zoneCheck.maxServers must be at least 1")
+	for _, t := range c.Tools.Dig.RecordTypes {
+		check(recordTypeRegex.MatchString(t), "tools.dig.recordTypes: invalid record type %q", t)
+	}
+	for _, p := range c.Tools.Dig.Parameters {
+		check(digParamRegex.MatchString(p), "tools.dig.parameters: invalid parameter %q", p)
+	}
+
+	for _, entry := range c.Policy.AllowTargets {
+		check(validTargetEntry(entry), "policy.allowTargets: invalid entry %q", entry)
+	}
+	for _, entry := range c.Policy.DenyTargets {
+		check(validTargetEntry(entry), "policy.denyTargets: invalid entry %q", entry)
+	}
+
+	check(c.Reload.Interval > 0, "reload.interval must be positive")
+	check(!c.Metrics.Enabled || strings.HasPrefix(c.Metrics.Path, "/"), "metrics.path must start with /")
+	check(c.History.Retention >= 0, "history.retention must not be negative")
+	check(c.Scheduler.MinInterval >= time.Second, "scheduler.minInterval must be at least 1s")
+	check(c.Scheduler.MaxSchedules >= 1, "scheduler.maxSchedules must be at least 1")
+	if c.Auth.Required {
+		clientCerts := c.Server.TLS.Enabled && c.Server.TLS.ClientAuth != "none"
+		check(clientCerts || len(c.Auth.APIKeys) > 0 || c.Auth.HtpasswdFile != "" || c.Auth.JWT.JWKSURL != "",
+			"auth.required needs at least one authentication method")
+	}
+	for name, key := range c.Auth.APIKeys {
+		check(len(key) >= 16, "auth.apiKeys.%s must be at least 16 characters", name)
+	}
+	if c.RBAC.Enabled {
+		for _, name := range c.RBAC.DefaultRoles {
+			_, ok := c.RBAC.Roles[name]
+			check(ok, "rbac.defaultRoles: role %s is not defined", name)
+		}
+		for identity, names := range c.RBAC.Assignments {
+			check(strings.Contains(identity, ":"), "rbac.assignments: %s must be written method:subject", identity)
+			for _, name := range names {
+				_, ok := c.RBAC.Roles[name]
+				check(ok, "rbac.assignments.%s: role %s is not defined", identity, name)
+			}
+		}
+		for name, role := range c.RBAC.Roles {
+			check(role.MaxPingCount >= 0, "rbac.roles.%s.maxPingCount must not be negative", name)
+			for _, entry := range append(append([]string{}, role.AllowTargets...), role.DenyTargets...) {
+				check(validTargetEntry(entry), "rbac.roles.%s: invalid target %q", name, entry)
+			}
+		}
+	}
+	if c.Alerts.Enabled {
+		for name, w := range c.Alerts.Webhooks {
+			u, err := url.Parse(w.URL)
+			check(err == nil && (u.Scheme == "https" || u.Scheme == "http") && u.Host != "", "alerts.webhooks.%s.url must be an http(s) URL", name)
+			check(w.Format == "" || w.Format == "json" || w.Format == "slack", "alerts.webhooks.%s.format must be json or slack", name)
+			check(w.Timeout >= 0, "alerts.webhooks.%s.timeout must not be negative", name)
+		}
+		names := make(map[string]bool)
+		for i, rule := range c.Alerts.Rules {
+			check(rule.Name != "", "alerts.rules[%d].name must be set", i)
+			check(!names[rule.Name], "alerts.rules[%d]: duplicate rule name %q", i, rule.Name)
+			names[rule.Name] = true
+			check(rule.Condition != "", "alerts.rules[%d].condition must be set", i)
+			check(len(rule.Webhooks) > 0, "alerts.rules[%d].webhooks must not be empty", i)
+			for _, name := range rule.Webhooks {
+				_, ok := c.Alerts.Webhooks[name]
+				check(ok, "alerts.rules[%d]: webhook %s is not defined", i, name)
+			}
+		}
+		check(c.Alerts.RepeatInterval >= 0, "alerts.repeatInterval must not be negative")
+		check(c.Alerts.Retries >= 0, "alerts.retries must not be negative")
+		check(c.Alerts.RetryBackoff > 0, "alerts.retryBackoff must be positive")
+	}
+	if c.Auth.JWT.JWKSURL != "" {
+		u, err := url.Parse(c.Auth.JWT.JWKSURL)
+		check(err == nil && (u.Scheme == "https" || u.Scheme == "http") && u.Host != "", "auth.jwt.jwksURL must be an http(s) URL")
+		check(c.Auth.JWT.SubjectClaim != "", "auth.jwt.subjectClaim must be set")
+		check(c.Auth.JWT.RefreshInterval >= 0, "auth.jwt.refreshInterval must not be negative")
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// validTargetEntry reports whether a policy entry is an IP, CIDR or domain
+func validTargetEntry(entry string) bool {
+	if net.ParseIP(entry) != nil {
+		return true
+	}
+	if _, _, err := net.ParseCIDR(entry); err == nil {
+		return true
+	}
+	domain := strings.TrimPrefix(strings.TrimPrefix(entry, "*"), ".")
+	return domain != "" && !strings.ContainsAny(domain, " /*")
+}
+
+// validOrigin reports whether entry is a scheme://host[:port] origin, where
+// the host may start with a "*." wildcard label
+func validOrigin(entry string) bool {
+	u, err := url.Parse(strings.Replace(entry, "://*.", "://wildcard.", 1))
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" &&
+		(u.Path == "" || u.Path == "/") && u.RawQuery == "" && u.User == nil
+}
@@ -0,0 +1,168 @@
+// File: backend/internal/config/config_test.go
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := "server:\n  listen: \":9090\"\ntools:\n  ping:\n    maxCount: 10\n"
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("NT_RATE_LIMIT_PERIOD", "30s")
+	t.Setenv("NT_POLICY_DENY_TARGETS", "10.0.0.0/8, internal.example.com")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Server.Listen != ":9090" {
+		t.Errorf("Server.Listen = %q, want %q", cfg.Server.Listen, ":9090")
+	}
+	if cfg.Tools.Ping.MaxCount != 10 {
+		t.Errorf("Tools.Ping.MaxCount = %d, want 10", cfg.Tools.Ping.MaxCount)
+	}
+	if cfg.RateLimit.Period != 30*time.Second {
+		t.Errorf("RateLimit.Period = %v, want 30s", cfg.RateLimit.Period)
+	}
+	if len(cfg.Policy.DenyTargets) != 2 || cfg.Policy.DenyTargets[1] != "internal.example.com" {
+		t.Errorf("Policy.DenyTargets = %v", cfg.Policy.DenyTargets)
+	}
+}
+
+// TestExample keeps the documented example configuration loadable
+func TestExample(t *testing.T) {
+	cfg, err := Load(filepath.Join("..", "..", "config.example.yaml"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if got, want := cfg.Tools.DNSCompare.Presets, Default().Tools.DNSCompare.Presets; !reflect.DeepEqual(got, want) {
+		t.Errorf("Tools.DNSCompare.Presets = %v, want %v", got, want)
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	tests := map[string]string{
+		"listen":       "LISTEN",
+		"rateLimit":    "RATE_LIMIT",
+		"clientCAFile": "CLIENT_CA_FILE",
+		"maxSizeMB":    "MAX_SIZE_MB",
+	}
+	for key, want := range tests {
+		if got := envName(key); got != want {
+			t.Errorf("envName(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*Config)
+		wantErr bool
+	}{
+		{"Defaults", func(*Config) {}, false},
+		{"Empty listen", func(c *Config) { c.Server.Listen = "" }, true},
+		{"TLS without cert", func(c *Config) { c.Server.TLS.Enabled = true }, true},
+		{"Client auth without CA", func(c *Config) {
+			c.Server.TLS = TLSConfig{Enabled: true, CertFile: "c.pem", KeyFile: "k.pem", ClientAuth: "require"}
+		}, true},
+		{"Bad trusted proxy", func(c *Config) { c.Server.TrustedProxies = []string{"proxy.internal"} }, true},
+		{"PROXY protocol without trusted proxies", func(c *Config) { c.Server.ProxyProtocol = true }, true},
+		{"Unknown proxy header", func(c *Config) { c.Server.ProxyHeader = "X-Real-IP" }, true},
+		{"Bad log level", func(c *Config) { c.Logging.Level = "loud" }, true},
+		{"Wildcard with credentials", func(c *Config) {
+			c.CORS.AllowOrigins = []string{"*"}
+			c.CORS.AllowCredentials = true
+		}, true},
+		{"Origin with path", func(c *Config) { c.CORS.AllowOrigins = []string{"https://tools.example.com/app"} }, true},
+		{"Wildcard subdomain origin", func(c *Config) { c.CORS.AllowOrigins = []string{"https://*.example.com"} }, false},
+		{"Inverted ping range", func(c *Config) { c.Tools.Ping.MinCount = 5; c.Tools.Ping.MaxCount = 2 }, true},
+		{"Unsafe dig parameter", func(c *Config) { c.Tools.Dig.Parameters = []string{"bufsize=1"} }, true},
+		{"Invalid policy entry", func(c *Config) { c.Policy.DenyTargets = []string{"10.0.0.0/99"} }, true},
+		{"Alert rule with unknown webhook", func(c *Config) {
+			c.Alerts.Enabled = true
+			c.Alerts.Rules = []AlertRuleConfig{{Name: "loss", Condition: "packetLoss > 20%", Webhooks: []string{"ops"}}}
+		}, true},
+		{"Alert webhook without scheme", func(c *Config) {
+			c.Alerts.Enabled = true
+			c.Alerts.Webhooks = map[string]WebhookConfig{"ops": {URL: "alerts.example.com/hook"}}
+		}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			tt.modify(cfg)
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	cfg := Default()
+	cfg.Admin.Token = "s3cret"
+
+	redacted := cfg.Redacted()
+	if redacted.Admin.Token != redactedValue {
+		t.Errorf("Admin.Token = %q, want %q", redacted.Admin.Token, redactedValue)
+	}
+	if cfg.Admin.Token != "s3cret" {
+		t.Errorf("Redacted() modified the original configuration")
+	}
+	redacted.Tools.Dig.RecordTypes[0] = "X"
+	if cfg.Tools.Dig.RecordTypes[0] != "A" {
+		t.Errorf("Redacted() shares slices with the original configuration")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	old := Default()
+	old.Admin.Token = "old-token"
+	old.Alerts.Webhooks = map[string]WebhookConfig{
+		"ops": {URL: "https://hooks.example.com/old", Format: "json", Headers: map[string]string{"Authorization": "Bearer old"}},
+	}
+	new := Default()
+	new.Admin.Token = "new-token"
+	new.Alerts.Webhooks = map[string]WebhookConfig{
+		"ops":   {URL: "https://hooks.example.com/new", Format: "slack", Headers: map[string]string{"Authorization": "Bearer new"}},
+		"pager": {URL: "https://pager.example.com/s3cret"},
+	}
+
+	changes := make(map[string]Change)
+	for _, c := range Diff(old, new) {
+		changes[c.Path] = c
+	}
+	want := map[string][2]string{
+		"admin.token":                               {redactedValue, redactedValue},
+		"alerts.webhooks.ops.url":                   {redactedValue, redactedValue},
+		"alerts.webhooks.ops.format":                {"json", "slack"},
+		"alerts.webhooks.ops.headers.Authorization": {redactedValue, redactedValue},
+		"alerts.webhooks.pager.url":                 {redactedValue, redactedValue},
+	}
+	if len(changes) != len(want) {
+		t.Errorf("got %d changes, want %d: %v", len(changes), len(want), changes)
+	}
+	for path, values := range want {
+		c, ok := changes[path]
+		if !ok {
+			t.Errorf("missing change for %s", path)
+			continue
+		}
+		if c.Old != values[0] || c.New != values[1] {
+			t.Errorf("%s: got %q -> %q, want %q -> %q", path, c.Old, c.New, values[0], values[1])
+		}
+	}
+}
@@ -0,0 +1,100 @@
+// File: backend/internal/config/diff.go
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Change is a single configuration value that differs between two configs
+type Change struct {
+	Path string
+	Old  string
+	New  string
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %s -> %s", c.Path, c.Old, c.New)
+}
+
+// Diff lists the values that differ from old to new, keyed by their YAML
+// path. Map entries are compared one by one under path.key. Secret values,
+// including every entry and field below a secret map, are reported as
+// changed without revealing them.
+func Diff(old, new *Config) []Change {
+	var changes []Change
+	diffValue(&changes, "", reflect.ValueOf(*old), reflect.ValueOf(*new), false)
+	return changes
+}
+
+func diffValue(changes *[]Change, path string, a, b reflect.Value, secret bool) {
+	if a.Kind() == reflect.Struct {
+		t := a.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+			if name == "" || name == "-" {
+				continue
+			}
+			if path != "" {
+				name = path + "." + name
+			}
+			diffValue(changes, name, a.Field(i), b.Field(i), secret || field.Tag.Get("secret") == "true")
+		}
+		return
+	}
+	if a.Kind() == reflect.Map {
+		diffMap(changes, path, a, b, secret)
+		return
+	}
+
+	if reflect.DeepEqual(a.Interface(), b.Interface()) {
+		return
+	}
+	change := Change{Path: path, Old: formatValue(a), New: formatValue(b)}
+	if secret {
+		change.Old, change.New = redactedValue, redactedValue
+	}
+	*changes = append(*changes, change)
+}
+
+// diffMap compares the entries of two maps, treating a missing entry as the
+// zero value
+func diffMap(changes *[]Change, path string, a, b reflect.Value, secret bool) {
+	keys := make(map[string]reflect.Value)
+	for _, m := range []reflect.Value{a, b} {
+		for _, k := range m.MapKeys() {
+			keys[fmt.Sprint(k.Interface())] = k
+		}
+	}
+	names := make([]string, 0, len(keys))
+	for name := range keys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	zero := reflect.Zero(a.Type().Elem())
+	for _, name := range names {
+		va, vb := a.MapIndex(keys[name]), b.MapIndex(keys[name])
+		if !va.IsValid() {
+			va = zero
+		}
+		if !vb.IsValid() {
+			vb = zero
+		}
+		diffValue(changes, path+"."+name, va, vb, secret)
+	}
+}
+
+func formatValue(v reflect.Value) string {
+	if v.Kind() == reflect.Slice {
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = fmt.Sprint(v.Index(i).Interface())
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	}
+	return fmt.Sprint(v.Interface())
+}
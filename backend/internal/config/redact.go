@@ -0,0 +1,65 @@
+// File: backend/internal/config/redact.go
+package config
+
+import (
+	"reflect"
+)
+
+// redactedValue replaces secret values in redacted output
+const redactedValue = "[REDACTED]"
+
+// Redacted returns a deep copy of the configuration with every field tagged
+// secret:"true" replaced, so it can be shown to operators
+func (c *Config) Redacted() *Config {
+	out := reflect.New(reflect.TypeOf(*c)).Elem()
+	redactValue(out, reflect.ValueOf(*c), false)
+	cfg := out.Interface().(Config)
+	return &cfg
+}
+
+// redactValue copies src into dst, masking secret strings
+func redactValue(dst, src reflect.Value, secret bool) {
+	switch src.Kind() {
+	case reflect.Struct:
+		t := src.Type()
+		for i := 0; i < t.NumField(); i++ {
+			redactValue(dst.Field(i), src.Field(i), t.Field(i).Tag.Get("secret") == "true")
+		}
+	case reflect.String:
+		if secret && src.String() != "" {
+			dst.SetString(redactedValue)
+		} else {
+			dst.SetString(src.String())
+		}
+	case reflect.Slice:
+		if src.IsNil() {
+			return
+		}
+		s := reflect.MakeSlice(src.Type(), src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			redactValue(s.Index(i), src.Index(i), secret)
+		}
+		dst.Set(s)
+	case reflect.Map:
+		if src.IsNil() {
+			return
+		}
+		m := reflect.MakeMapWithSize(src.Type(), src.Len())
+		iter := src.MapRange()
+		for iter.Next() {
+			v := reflect.New(src.Type().Elem()).Elem()
+			redactValue(v, iter.Value(), secret)
+			m.SetMapIndex(iter.Key(), v)
+		}
+		dst.Set(m)
+	case reflect.Pointer:
+		if src.IsNil() {
+			return
+		}
+		p := reflect.New(src.Type().Elem())
+		redactValue(p.Elem(), src.Elem(), secret)
+		dst.Set(p)
+	default:
+		dst.Set(src)
+	}
+}
@@ -0,0 +1,215 @@
+// File: backend/internal/export/export.go
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"backend/internal/jobs"
+)
+
+// Format is an export file format
+type Format string
+
+const (
+	FormatJSON     Format = "json"
+	FormatCSV      Format = "csv"
+	FormatMarkdown Format = "markdown"
+	FormatText     Format = "text"
+)
+
+// ParseFormat returns the format with the given name or common alias
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(name) {
+	case "", "json":
+		return FormatJSON, nil
+	case "csv":
+		return FormatCSV, nil
+	case "markdown", "md":
+		return FormatMarkdown, nil
+	case "text", "txt":
+		return FormatText, nil
+	default:
+		return "", fmt.Errorf("unknown export format %q, expected json, csv, markdown or text", name)
+	}
+}
+
+// ContentType returns the MIME type of the format
+func (f Format) ContentType() string {
+	switch f {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatMarkdown:
+		return "text/markdown; charset=utf-8"
+	case FormatText:
+		return "text/plain; charset=utf-8"
+	default:
+		return "application/json"
+	}
+}
+
+// Extension returns the file name extension of the format
+func (f Format) Extension() string {
+	switch f {
+	case FormatMarkdown:
+		return "md"
+	case FormatText:
+		return "txt"
+	default:
+		return string(f)
+	}
+}
+
+// UnsupportedError is returned when a tool's results cannot be rendered in
+// the requested format
+type UnsupportedError struct {
+	Tool   string
+	Format Format
+}
+
+func (e *UnsupportedError) Error() string {
+	return fmt.Sprintf("%s export is not supported for %s jobs", e.Format, e.Tool)
+}
+
+// report is the tabular form of a job's result
+type report struct {
+	title   string
+	summary []string
+	columns []string
+	rows    [][]string
+}
+
+// exporter renders the results of one tool
+type exporter struct {
+	// report builds the table used by the CSV, Markdown and text formats
+	report func(snap jobs.Snapshot, output string) report
+	// text, when set, replaces the tabular text rendering
+	text func(w io.Writer, snap jobs.Snapshot, output string) error
+	// result decodes the structured result for the JSON format
+	result func(snap jobs.Snapshot, output string) interface{}
+}
+
+// exporters holds the tools whose results have a tabular form
+var exporters = map[string]exporter{
+	"ping":        pingExporter,
+	"dig":         digExporter,
+	"batch":       batchExporter,
+	"sweep":       sweepExporter,
+	"dns-diff":    dnsDiffExporter,
+	"dns-compare": dnsCompareExporter,
+	"zone-check":  zoneCheckExporter,
+}
+
+// Render writes a job's result in the given format. JSON and text work for
+// every tool; CSV and Markdown need a tool with a tabular result. Results
+// missing from the snapshot are parsed from the output.
+func Render(w io.Writer, format Format, snap jobs.Snapshot, output string) error {
+	exp, ok := exporters[snap.Tool]
+	switch format {
+	case FormatJSON:
+		if ok {
+			snap.Result = exp.result(snap, output)
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(snap)
+	case FormatText:
+		switch {
+		case ok && exp.text != nil:
+			return exp.text(w, snap, output)
+		case ok:
+			return writeText(w, exp.report(snap, output))
+		default:
+			_, err := io.WriteString(w, output)
+			return err
+		}
+	case FormatCSV:
+		if !ok {
+			return &UnsupportedError{Tool: snap.Tool, Format: format}
+		}
+		return writeCSV(w, exp.report(snap, output))
+	case FormatMarkdown:
+		if !ok {
+			return &UnsupportedError{Tool: snap.Tool, Format: format}
+		}
+		return writeMarkdown(w, exp.report(snap, output))
+	default:
+		return &UnsupportedError{Tool: snap.Tool, Format: format}
+	}
+}
+
+func writeCSV(w io.Writer, r report) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(r.columns); err != nil {
+		return err
+	}
+	if err := cw.WriteAll(r.rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+func writeMarkdown(w io.Writer, r report) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s\n\n", r.title)
+	for _, line := range r.summary {
+		fmt.Fprintf(&b, "- %s\n", markdownEscape(line))
+	}
+	if len(r.summary) > 0 {
+		b.WriteString("\n")
+	}
+	if len(r.rows) == 0 {
+		b.WriteString("_No results_\n")
+	} else {
+		b.WriteString("| " + strings.Join(r.columns, " | ") + " |\n")
+		b.WriteString("|" + strings.Repeat(" --- |", len(r.columns)) + "\n")
+		for _, row := range r.rows {
+			cells := make([]string, len(row))
+			for i, cell := range row {
+				cells[i] = markdownEscape(cell)
+			}
+			b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// markdownEscape keeps cell contents from breaking the table or being read
+// as markup
+func markdownEscape(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ", "*", `\*`, "_", `\_`, "`", "\\`").Replace(s)
+}
+
+func writeText(w io.Writer, r report) error {
+	var b strings.Builder
+	b.WriteString(r.title + "\n")
+	for _, line := range r.summary {
+		b.WriteString(line + "\n")
+	}
+	b.WriteString("\n")
+	tw := tabwriter.NewWriter(&b, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.ToUpper(strings.Join(r.columns, "\t")))
+	for _, row := range r.rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// decodeResult converts a structured result into dst. Results read back from
+// the job history are generic JSON values rather than the tool's own type.
+func decodeResult(result interface{}, dst interface{}) bool {
+	if result == nil {
+		return false
+	}
+	data, err := json.Marshal(result)
+	return err == nil && json.Unmarshal(data, dst) == nil
+}
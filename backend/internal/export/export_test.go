@@ -0,0 +1,199 @@
+package export
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/internal/jobs"
+	"backend/internal/tools"
+)
+
+const pingOutput = `PING example.com (93.184.216.34) 56(84) bytes of data.
+64 bytes from 93.184.216.34: icmp_seq=1 ttl=56 time=11.2 ms
+64 bytes from 93.184.216.34: icmp_seq=2 ttl=56 time=10.8 ms
+
+--- example.com ping statistics ---
+2 packets transmitted, 2 received, 0% packet loss, time 1001ms
+rtt min/avg/max/mdev = 10.800/11.000/11.200/0.200 ms
+`
+
+var digResultFixture = &tools.DigResult{
+	Domain:     "example.com",
+	RecordType: "MX",
+	Status:     "NOERROR",
+	Flags:      []string{"qr", "rd", "ra"},
+	Answer: []tools.DigRecord{
+		{Name: "example.com.", TTL: 300, Class: "IN", Type: "MX", Data: "10 mail.example.com."},
+		{Name: "example.com.", TTL: 300, Class: "IN", Type: "MX", Data: "20 mx|backup.example.com."},
+	},
+	Additional:  []tools.DigRecord{{Name: "mail.example.com.", TTL: 60, Class: "IN", Type: "A", Data: "192.0.2.25"}},
+	QueryTimeMs: 12,
+	Server:      "192.0.2.53",
+}
+
+func snapshot(t *testing.T, tool string, result interface{}) jobs.Snapshot {
+	t.Helper()
+	snap := jobs.Snapshot{
+		ID:        "0123456789abcdef",
+		Tool:      tool,
+		State:     jobs.StateSucceeded,
+		CreatedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+	}
+	switch tool {
+	case "ping":
+		snap.Parameters = map[string]interface{}{"target": "example.com", "count": 2}
+	case "dig":
+		snap.Parameters = map[string]interface{}{"domain": "example.com", "recordType": "MX"}
+	}
+	// Results read back from the history are generic JSON values
+	if result != nil {
+		data, err := json.Marshal(result)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var generic interface{}
+		json.Unmarshal(data, &generic)
+		snap.Result = generic
+	}
+	return snap
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		snap   jobs.Snapshot
+		output string
+		want   string
+	}{
+		{
+			name:   "Ping CSV parsed from output",
+			format: FormatCSV,
+			snap:   snapshot(t, "ping", nil),
+			output: pingOutput,
+			want: "seq,from,ttl,time_ms\n" +
+				"1,93.184.216.34,56,11.2\n" +
+				"2,93.184.216.34,56,10.8\n",
+		},
+		{
+			name:   "Ping Markdown",
+			format: FormatMarkdown,
+			snap:   snapshot(t, "ping", tools.ParsePingOutput(pingOutput)),
+			output: pingOutput,
+			want: "## ping example.com (93.184.216.34)\n\n" +
+				"- 2 transmitted, 2 received, 0% packet loss\n" +
+				"- rtt min/avg/max/mdev = 10.8/11/11.2/0.2 ms\n\n" +
+				"| seq | from | ttl | time_ms |\n" +
+				"| --- | --- | --- | --- |\n" +
+				"| 1 | 93.184.216.34 | 56 | 11.2 |\n" +
+				"| 2 | 93.184.216.34 | 56 | 10.8 |\n",
+		},
+		{
+			name:   "Ping text",
+			format: FormatText,
+			snap:   snapshot(t, "ping", nil),
+			output: pingOutput,
+			want: "ping example.com (93.184.216.34)\n" +
+				"2 transmitted, 2 received, 0% packet loss\n" +
+				"rtt min/avg/max/mdev = 10.8/11/11.2/0.2 ms\n\n" +
+				"SEQ  FROM           TTL  TIME_MS\n" +
+				"1    93.184.216.34  56   11.2\n" +
+				"2    93.184.216.34  56   10.8\n",
+		},
+		{
+			name:   "Dig CSV",
+			format: FormatCSV,
+			snap:   snapshot(t, "dig", digResultFixture),
+			want: "section,name,ttl,class,type,data\n" +
+				"answer,example.com.,300,IN,MX,10 mail.example.com.\n" +
+				"answer,example.com.,300,IN,MX,20 mx|backup.example.com.\n" +
+				"additional,mail.example.com.,60,IN,A,192.0.2.25\n",
+		},
+		{
+			name:   "Dig Markdown",
+			format: FormatMarkdown,
+			snap:   snapshot(t, "dig", digResultFixture),
+			want: "## dig example.com MX\n\n" +
+				"- status: NOERROR\n" +
+				"- flags: qr rd ra\n" +
+				"- server: 192.0.2.53\n" +
+				"- query time: 12 ms\n\n" +
+				"| section | name | ttl | class | type | data |\n" +
+				"| --- | --- | --- | --- | --- | --- |\n" +
+				"| answer | example.com. | 300 | IN | MX | 10 mail.example.com. |\n" +
+				"| answer | example.com. | 300 | IN | MX | 20 mx\\|backup.example.com. |\n" +
+				"| additional | mail.example.com. | 60 | IN | A | 192.0.2.25 |\n",
+		},
+		{
+			name:   "Dig zone text",
+			format: FormatText,
+			snap:   snapshot(t, "dig", digResultFixture),
+			want: "; dig example.com MX\n" +
+				"; status: NOERROR\n" +
+				"; flags: qr rd ra\n" +
+				"; server: 192.0.2.53\n" +
+				"; query time: 12 ms\n\n" +
+				";; ANSWER\n" +
+				"example.com.\t300\tIN\tMX\t10 mail.example.com.\n" +
+				"example.com.\t300\tIN\tMX\t20 mx|backup.example.com.\n\n" +
+				";; ADDITIONAL\n" +
+				"mail.example.com.\t60\tIN\tA\t192.0.2.25\n",
+		},
+		{
+			name:   "Dig short text",
+			format: FormatText,
+			snap:   snapshot(t, "dig", &tools.DigResult{Domain: "example.com", RecordType: "A", Short: []string{"93.184.216.34"}}),
+			want:   "; dig example.com A\n\n;; SHORT\n93.184.216.34\n",
+		},
+		{
+			name:   "Unknown tool text is the raw output",
+			format: FormatText,
+			snap:   jobs.Snapshot{Tool: "custom"},
+			output: "line one\nline two\n",
+			want:   "line one\nline two\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			if err := Render(&b, tt.format, tt.snap, tt.output); err != nil {
+				t.Fatalf("Render: %v", err)
+			}
+			if b.String() != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", b.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderJSON(t *testing.T) {
+	var b strings.Builder
+	if err := Render(&b, FormatJSON, snapshot(t, "ping", nil), pingOutput); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	var got struct {
+		ID     string           `json:"id"`
+		Result tools.PingResult `json:"result"`
+	}
+	if err := json.Unmarshal([]byte(b.String()), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, b.String())
+	}
+	if got.ID != "0123456789abcdef" || got.Result.Target != "example.com" || len(got.Result.Replies) != 2 || got.Result.AvgMs != 11 {
+		t.Errorf("result = %+v", got)
+	}
+}
+
+func TestRenderUnsupported(t *testing.T) {
+	var unsupported *UnsupportedError
+	err := Render(&strings.Builder{}, FormatCSV, jobs.Snapshot{Tool: "custom"}, "")
+	if !errors.As(err, &unsupported) {
+		t.Errorf("err = %v, want UnsupportedError", err)
+	}
+	if _, err := ParseFormat("xlsx"); err == nil {
+		t.Error("ParseFormat(xlsx) succeeded")
+	}
+}
@@ -0,0 +1,385 @@
+// File: backend/internal/export/tools.go
+package export
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"backend/internal/jobs"
+	"backend/internal/tools"
+)
+
+var pingExporter = exporter{
+	report: func(snap jobs.Snapshot, output string) report {
+		return pingReport(pingResult(snap, output))
+	},
+	result: func(snap jobs.Snapshot, output string) interface{} {
+		return pingResult(snap, output)
+	},
+}
+
+var digExporter = exporter{
+	report: func(snap jobs.Snapshot, output string) report {
+		return digReport(digResult(snap, output))
+	},
+	text: func(w io.Writer, snap jobs.Snapshot, output string) error {
+		return writeZone(w, digResult(snap, output))
+	},
+	result: func(snap jobs.Snapshot, output string) interface{} {
+		return digResult(snap, output)
+	},
+}
+
+var batchExporter = exporter{
+	report: func(snap jobs.Snapshot, _ string) report {
+		return batchReport(batchResult(snap))
+	},
+	result: func(snap jobs.Snapshot, _ string) interface{} {
+		return batchResult(snap)
+	},
+}
+
+var sweepExporter = exporter{
+	report: func(snap jobs.Snapshot, _ string) report {
+		return sweepReport(sweepResult(snap))
+	},
+	result: func(snap jobs.Snapshot, _ string) interface{} {
+		return sweepResult(snap)
+	},
+}
+
+var dnsDiffExporter = exporter{
+	report: func(snap jobs.Snapshot, _ string) report {
+		return dnsDiffReport(dnsDiffResult(snap))
+	},
+	result: func(snap jobs.Snapshot, _ string) interface{} {
+		return dnsDiffResult(snap)
+	},
+}
+
+var dnsCompareExporter = exporter{
+	report: func(snap jobs.Snapshot, _ string) report {
+		return dnsCompareReport(dnsCompareResult(snap))
+	},
+	result: func(snap jobs.Snapshot, _ string) interface{} {
+		return dnsCompareResult(snap)
+	},
+}
+
+var zoneCheckExporter = exporter{
+	report: func(snap jobs.Snapshot, _ string) report {
+		return zoneCheckReport(zoneCheckResult(snap))
+	},
+	result: func(snap jobs.Snapshot, _ string) interface{} {
+		return zoneCheckResult(snap)
+	},
+}
+
+// pingResult returns the job's ping result, parsing the output when the
+// snapshot has none
+func pingResult(snap jobs.Snapshot, output string) *tools.PingResult {
+	r := &tools.PingResult{}
+	if !decodeResult(snap.Result, r) {
+		r = tools.ParsePingOutput(output)
+	}
+	if r.Target == "" {
+		r.Target, _ = snap.Parameters["target"].(string)
+	}
+	return r
+}
+
+// digResult returns the job's dig result, parsing the output when the
+// snapshot has none
+func digResult(snap jobs.Snapshot, output string) *tools.DigResult {
+	r := &tools.DigResult{}
+	if !decodeResult(snap.Result, r) {
+		r = tools.ParseDigOutput(output)
+	}
+	if r.Domain == "" {
+		r.Domain, _ = snap.Parameters["domain"].(string)
+	}
+	if r.RecordType == "" {
+		recordType, _ := snap.Parameters["recordType"].(string)
+		r.RecordType = strings.ToUpper(recordType)
+	}
+	return r
+}
+
+func formatMs(ms float64) string {
+	return strconv.FormatFloat(ms, 'f', -1, 64)
+}
+
+// pingReport lists one row per echo reply
+func pingReport(r *tools.PingResult) report {
+	rep := report{
+		title:   "ping " + r.Target,
+		columns: []string{"seq", "from", "ttl", "time_ms"},
+		rows:    [][]string{},
+	}
+	if r.Address != "" && r.Address != r.Target {
+		rep.title += " (" + r.Address + ")"
+	}
+	rep.summary = append(rep.summary, fmt.Sprintf("%d transmitted, %d received, %s%% packet loss",
+		r.Transmitted, r.Received, formatMs(r.PacketLoss)))
+	if r.Received > 0 {
+		rep.summary = append(rep.summary, fmt.Sprintf("rtt min/avg/max/mdev = %s/%s/%s/%s ms",
+			formatMs(r.MinMs), formatMs(r.AvgMs), formatMs(r.MaxMs), formatMs(r.MdevMs)))
+	}
+	for _, reply := range r.Replies {
+		rep.rows = append(rep.rows, []string{
+			strconv.Itoa(reply.Seq), reply.From, strconv.Itoa(reply.TTL), formatMs(reply.TimeMs),
+		})
+	}
+	return rep
+}
+
+// digSummary describes the response header of a dig result
+func digSummary(r *tools.DigResult) []string {
+	var summary []string
+	if r.Status != "" {
+		summary = append(summary, "status: "+r.Status)
+	}
+	if len(r.Flags) > 0 {
+		summary = append(summary, "flags: "+strings.Join(r.Flags, " "))
+	}
+	if r.Server != "" {
+		summary = append(summary, "server: "+r.Server)
+	}
+	if r.QueryTimeMs > 0 {
+		summary = append(summary, fmt.Sprintf("query time: %d ms", r.QueryTimeMs))
+	}
+	return summary
+}
+
+// digSection is one record section of a dig response
+type digSection struct {
+	name    string
+	records []tools.DigRecord
+}
+
+// digSections returns the record sections of a dig result in response
+// order. +short output has only record data, reported as a short section.
+func digSections(r *tools.DigResult) []digSection {
+	sections := []digSection{
+		{"answer", r.Answer},
+		{"authority", r.Authority},
+		{"additional", r.Additional},
+	}
+	if len(r.Short) > 0 {
+		short := make([]tools.DigRecord, len(r.Short))
+		for i, data := range r.Short {
+			short[i] = tools.DigRecord{Data: data}
+		}
+		sections = append(sections, digSection{"short", short})
+	}
+	return sections
+}
+
+// digReport lists one row per resource record, tagged with its section
+func digReport(r *tools.DigResult) report {
+	rep := report{
+		title:   strings.TrimSpace("dig " + r.Domain + " " + r.RecordType),
+		summary: digSummary(r),
+		columns: []string{"section", "name", "ttl", "class", "type", "data"},
+		rows:    [][]string{},
+	}
+	for _, section := range digSections(r) {
+		for _, rec := range section.records {
+			ttl := ""
+			if rec.Name != "" {
+				ttl = strconv.Itoa(rec.TTL)
+			}
+			rep.rows = append(rep.rows, []string{section.name, rec.Name, ttl, rec.Class, rec.Type, rec.Data})
+		}
+	}
+	return rep
+}
+
+// writeZone renders a dig result as zone file style text, with the response
+// header and section names as comments
+func writeZone(w io.Writer, r *tools.DigResult) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "; %s\n", strings.TrimSpace("dig "+r.Domain+" "+r.RecordType))
+	for _, line := range digSummary(r) {
+		fmt.Fprintf(&b, "; %s\n", line)
+	}
+	for _, section := range digSections(r) {
+		if len(section.records) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n;; %s\n", strings.ToUpper(section.name))
+		for _, rec := range section.records {
+			if rec.Name == "" {
+				fmt.Fprintf(&b, "%s\n", rec.Data)
+				continue
+			}
+			fmt.Fprintf(&b, "%s\t%d\t%s\t%s\t%s\n", rec.Name, rec.TTL, rec.Class, rec.Type, rec.Data)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// batchResult returns the job's batch result. Batches have no parsable
+// output, so a job without a result exports an empty batch.
+func batchResult(snap jobs.Snapshot) *tools.BatchResult {
+	r := &tools.BatchResult{Targets: []tools.BatchTarget{}}
+	if !decodeResult(snap.Result, r) {
+		r.Tool, _ = snap.Parameters["tool"].(string)
+	}
+	return r
+}
+
+// batchReport lists one row per target with its outcome
+func batchReport(r *tools.BatchResult) report {
+	rep := report{
+		title:   fmt.Sprintf("batch %s, %d targets", r.Tool, len(r.Targets)),
+		columns: []string{"target", "status", "exit_code", "result"},
+		rows:    [][]string{},
+	}
+	rep.summary = append(rep.summary, fmt.Sprintf("%d succeeded, %d failed", r.Succeeded, r.Failed))
+	if r.Reachable+r.Unreachable > 0 {
+		line := fmt.Sprintf("%d reachable, %d unreachable", r.Reachable, r.Unreachable)
+		if r.Reachable > 0 {
+			line += fmt.Sprintf(", avg rtt %.3f ms", r.AvgMs)
+		}
+		rep.summary = append(rep.summary, line)
+	}
+	for _, t := range r.Targets {
+		rep.rows = append(rep.rows, []string{t.Target, t.Status, strconv.Itoa(t.ExitCode), t.Summary()})
+	}
+	return rep
+}
+
+// sweepResult returns the job's sweep result, empty when it has none
+func sweepResult(snap jobs.Snapshot) *tools.SweepResult {
+	r := &tools.SweepResult{Responders: []tools.SweepHost{}}
+	if !decodeResult(snap.Result, r) {
+		r.Network, _ = snap.Parameters["cidr"].(string)
+	}
+	return r
+}
+
+// sweepReport lists one row per responding host
+func sweepReport(r *tools.SweepResult) report {
+	rep := report{
+		title:   "sweep " + r.Network,
+		summary: []string{fmt.Sprintf("%d hosts, %d up, %d down", r.Hosts, r.Up, r.Down)},
+		columns: []string{"address", "time_ms"},
+		rows:    [][]string{},
+	}
+	for _, h := range r.Responders {
+		rep.rows = append(rep.rows, []string{h.Address, formatMs(h.TimeMs)})
+	}
+	return rep
+}
+
+// dnsDiffResult returns the job's dns-diff result, empty when it has none
+func dnsDiffResult(snap jobs.Snapshot) *tools.DNSDiffResult {
+	r := &tools.DNSDiffResult{Answers: []tools.DNSAnswer{}}
+	if !decodeResult(snap.Result, r) {
+		r.Baseline, _ = snap.Parameters["baseline"].(string)
+	}
+	return r
+}
+
+// dnsDiffReport lists the changes of a comparison, or the records of a
+// snapshot
+func dnsDiffReport(r *tools.DNSDiffResult) report {
+	if r.Diff != nil {
+		rep := report{
+			title:   "dns-diff against baseline " + r.Baseline,
+			summary: []string{r.Diff.Summary()},
+			columns: []string{"kind", "domain", "record_type", "name", "type", "data", "old", "new"},
+			rows:    [][]string{},
+		}
+		for _, c := range r.Diff.Changes {
+			rep.rows = append(rep.rows, []string{c.Kind, c.Domain, c.RecordType, c.Name, c.Type, c.Data, c.Old, c.New})
+		}
+		return rep
+	}
+	rep := report{
+		title:   fmt.Sprintf("dns snapshot, %d queries", len(r.Answers)),
+		columns: []string{"domain", "record_type", "status", "name", "ttl", "type", "data"},
+		rows:    [][]string{},
+	}
+	for _, a := range r.Answers {
+		if len(a.Records) == 0 {
+			rep.rows = append(rep.rows, []string{a.Domain, a.RecordType, a.Status, "", "", "", a.Error})
+			continue
+		}
+		for _, rec := range a.Records {
+			rep.rows = append(rep.rows, []string{a.Domain, a.RecordType, a.Status, rec.Name, strconv.Itoa(rec.TTL), rec.Type, rec.Data})
+		}
+	}
+	return rep
+}
+
+// dnsCompareResult returns the job's dns-compare result, empty when it has
+// none
+func dnsCompareResult(snap jobs.Snapshot) *tools.DNSCompareResult {
+	r := &tools.DNSCompareResult{Servers: []tools.DNSCompareServer{}}
+	if !decodeResult(snap.Result, r) {
+		r.Domain, _ = snap.Parameters["domain"].(string)
+		recordType, _ := snap.Parameters["recordType"].(string)
+		r.RecordType = strings.ToUpper(recordType)
+	}
+	return r
+}
+
+// dnsCompareReport lists one row per nameserver
+func dnsCompareReport(r *tools.DNSCompareResult) report {
+	rep := report{
+		title:   fmt.Sprintf("dns-compare %s %s, %d servers", r.Domain, r.RecordType, len(r.Servers)),
+		columns: []string{"server", "preset", "status", "query_time_ms", "answer", "mismatch"},
+		rows:    [][]string{},
+	}
+	if r.Consistent {
+		rep.summary = []string{"all servers agree"}
+	} else if len(r.Servers) > 0 {
+		rep.summary = []string{fmt.Sprintf("%d different answers", r.DistinctAnswers)}
+	}
+	for _, s := range r.Servers {
+		status := s.Status
+		if s.Error != "" {
+			status = "FAILED: " + s.Error
+		}
+		rep.rows = append(rep.rows, []string{s.Server, s.Preset, status, strconv.Itoa(s.QueryTimeMs), strings.Join(s.Answer, ", "), strconv.FormatBool(s.Mismatch)})
+	}
+	return rep
+}
+
+// zoneCheckResult returns the job's zone-check result, empty when it has
+// none
+func zoneCheckResult(snap jobs.Snapshot) *tools.ZoneCheckResult {
+	r := &tools.ZoneCheckResult{ParentNS: []string{}, ChildNS: []string{}, Servers: []tools.ZoneServer{}, Problems: []tools.ZoneProblem{}}
+	if !decodeResult(snap.Result, r) {
+		r.Zone, _ = snap.Parameters["zone"].(string)
+		recordType, _ := snap.Parameters["recordType"].(string)
+		r.RecordType = strings.ToUpper(recordType)
+	}
+	return r
+}
+
+// zoneCheckReport lists one row per nameserver, with the problems found as
+// the summary
+func zoneCheckReport(r *tools.ZoneCheckResult) report {
+	rep := report{
+		title:   fmt.Sprintf("zone-check %s, %d nameservers", r.Zone, len(r.Servers)),
+		columns: []string{"nameserver", "in_parent", "in_zone", "serial", "lame", "status", "authoritative", "answer", "error"},
+		rows:    [][]string{},
+	}
+	if r.Healthy {
+		rep.summary = []string{"no problems found"}
+	}
+	for _, p := range r.Problems {
+		rep.summary = append(rep.summary, p.String())
+	}
+	for _, s := range r.Servers {
+		rep.rows = append(rep.rows, []string{s.Name, strconv.FormatBool(s.InParent), strconv.FormatBool(s.InChild), s.Serial, strconv.FormatBool(s.Lame),
+			s.Status, strconv.FormatBool(s.Authoritative), strings.Join(s.Answer, ", "), s.Error})
+	}
+	return rep
+}
@@ -0,0 +1,249 @@
+// File: backend/internal/jobs/job.go
+package jobs
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// State is the lifecycle state of a job
+type State string
+
+const (
+	StateQueued    State = "queued"
+	StateRunning   State = "running"
+	StateSucceeded State = "succeeded"
+	StateFailed    State = "failed"
+	StateCancelled State = "cancelled"
+)
+
+// Finished reports whether the state is terminal
+func (s State) Finished() bool {
+	return s == StateSucceeded || s == StateFailed || s == StateCancelled
+}
+
+// EventType identifies the kind of a job event
+type EventType string
+
+const (
+	EventStarted   EventType = "started"
+	EventOutput    EventType = "output"
+	EventCompleted EventType = "completed"
+)
+
+// Event is a single entry in a job's lifecycle and output stream.
+// Seq starts at 1 and increases by one for every event of the job.
+type Event struct {
+	Seq   int       `json:"seq"`
+	Type  EventType `json:"type"`
+	JobID string    `json:"jobId"`
+	Time  time.Time `json:"time"`
+	Data  string    `json:"data,omitempty"`
+}
+
+// Snapshot is a point in time view of a job, safe to serialise
+type Snapshot struct {
+	ID          string                 `json:"id"`
+	Tool        string                 `json:"tool"`
+	Parameters  map[string]interface{} `json:"parameters"`
+	Argv        []string               `json:"argv"`
+	Client      Client                 `json:"client"`
+	State       State                  `json:"state"`
+	CreatedAt   time.Time              `json:"createdAt"`
+	StartedAt   *time.Time             `json:"startedAt,omitempty"`
+	FinishedAt  *time.Time             `json:"finishedAt,omitempty"`
+	ExitCode    *int                   `json:"exitCode,omitempty"`
+	Error       string                 `json:"error,omitempty"`
+	OutputBytes int                    `json:"outputBytes"`
+	Result      interface{}            `json:"result,omitempty"`
+}
+
+// targetParams are the parameters naming what a job was run against
+var targetParams = []string{"target", "domain", "cidr", "zone"}
+
+// Target returns the host, domain or range a job with params was run
+// against, empty for jobs without a single target
+func Target(params map[string]interface{}) string {
+	for _, name := range targetParams {
+		if target, ok := params[name].(string); ok {
+			return target
+		}
+	}
+	return ""
+}
+
+// Job is a single tool execution tracked by a Manager
+type Job struct {
+	ID     string
+	Tool   string
+	Params map[string]interface{}
+	Argv   []string
+	Client Client
+
+	mu         sync.Mutex
+	state      State
+	createdAt  time.Time
+	startedAt  time.Time
+	finishedAt time.Time
+	exitCode   int
+	err        string
+	result     interface{}
+	output     strings.Builder
+	events     []Event
+	changed    chan struct{}
+	cancel     context.CancelFunc
+	done       chan struct{}
+}
+
+func newJob(id, tool string, params map[string]interface{}, argv []string, client Client, cancel context.CancelFunc) *Job {
+	return &Job{
+		ID:        id,
+		Tool:      tool,
+		Params:    params,
+		Argv:      argv,
+		Client:    client,
+		state:     StateQueued,
+		createdAt: time.Now(),
+		changed:   make(chan struct{}),
+		cancel:    cancel,
+		done:      make(chan struct{}),
+	}
+}
+
+// appendEvent records an event and wakes any waiting subscribers.
+// The caller must hold j.mu.
+func (j *Job) appendEvent(typ EventType, data string) {
+	j.events = append(j.events, Event{
+		Seq:   len(j.events) + 1,
+		Type:  typ,
+		JobID: j.ID,
+		Time:  time.Now(),
+		Data:  data,
+	})
+	close(j.changed)
+	j.changed = make(chan struct{})
+}
+
+func (j *Job) start() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.state = StateRunning
+	j.startedAt = time.Now()
+	j.appendEvent(EventStarted, "")
+}
+
+func (j *Job) write(line string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.output.WriteString(line)
+	j.output.WriteByte('\n')
+	j.appendEvent(EventOutput, line)
+}
+
+func (j *Job) finish(state State, exitCode int, err error, result interface{}) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.state = state
+	j.finishedAt = time.Now()
+	j.exitCode = exitCode
+	if err != nil {
+		j.err = err.Error()
+	}
+	j.result = result
+	j.appendEvent(EventCompleted, string(state))
+	close(j.done)
+}
+
+// State returns the job's current state
+func (j *Job) State() State {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.state
+}
+
+// Output returns everything the job has written so far
+func (j *Job) Output() string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.output.String()
+}
+
+// Done returns a channel that is closed when the job finishes
+func (j *Job) Done() <-chan struct{} {
+	return j.done
+}
+
+// Cancel stops the job if it is still queued or running
+func (j *Job) Cancel() {
+	j.cancel()
+}
+
+// Events returns the events with a sequence number greater than after, and a
+// channel that is closed when further events are appended
+func (j *Job) Events(after int) ([]Event, <-chan struct{}) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if after < 0 {
+		after = 0
+	}
+	if after >= len(j.events) {
+		return nil, j.changed
+	}
+	events := make([]Event, len(j.events)-after)
+	copy(events, j.events[after:])
+	return events, j.changed
+}
+
+// Follow delivers every event after the given sequence number to fn, in order,
+// until the job completes, ctx is cancelled or fn returns an error
+func (j *Job) Follow(ctx context.Context, after int, fn func(Event) error) error {
+	for {
+		events, changed := j.Events(after)
+		for _, ev := range events {
+			if err := fn(ev); err != nil {
+				return err
+			}
+			after = ev.Seq
+			if ev.Type == EventCompleted {
+				return nil
+			}
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Snapshot returns a copy of the job's current status
+func (j *Job) Snapshot() Snapshot {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	s := Snapshot{
+		ID:          j.ID,
+		Tool:        j.Tool,
+		Parameters:  j.Params,
+		Argv:        j.Argv,
+		Client:      j.Client,
+		State:       j.state,
+		CreatedAt:   j.createdAt,
+		Error:       j.err,
+		OutputBytes: j.output.Len(),
+		Result:      j.result,
+	}
+	if !j.startedAt.IsZero() {
+		startedAt := j.startedAt
+		s.StartedAt = &startedAt
+	}
+	if j.state.Finished() {
+		finishedAt := j.finishedAt
+		exitCode := j.exitCode
+		s.FinishedAt = &finishedAt
+		s.ExitCode = &exitCode
+	}
+	return s
+}
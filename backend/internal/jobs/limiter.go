@@ -0,0 +1,96 @@
+// File: backend/internal/jobs/limiter.go
+package jobs
+
+import (
+	"context"
+	"sync"
+)
+
+// limiter bounds the number of jobs running at once, queueing the rest in
+// arrival order. It implements tools.Slots, lending free slots to running
+// jobs for their parallel sub-invocations.
+type limiter struct {
+	mu      sync.Mutex
+	max     int
+	running int
+	waiters []chan struct{}
+}
+
+func newLimiter(max int) *limiter {
+	return &limiter{max: max}
+}
+
+// acquire blocks until a slot is free or ctx is done
+func (l *limiter) acquire(ctx context.Context) error {
+	l.mu.Lock()
+	if l.running < l.max && len(l.waiters) == 0 {
+		l.running++
+		l.mu.Unlock()
+		return nil
+	}
+	ready := make(chan struct{})
+	l.waiters = append(l.waiters, ready)
+	l.mu.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		for i, w := range l.waiters {
+			if w == ready {
+				l.waiters = append(l.waiters[:i], l.waiters[i+1:]...)
+				return ctx.Err()
+			}
+		}
+		// The slot was handed over while we were cancelled; give it back
+		l.running--
+		l.wakeLocked()
+		return ctx.Err()
+	}
+}
+
+// TryAcquire takes a free slot if no job is waiting for one
+func (l *limiter) TryAcquire() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.running < l.max && len(l.waiters) == 0 {
+		l.running++
+		return true
+	}
+	return false
+}
+
+// Release frees a slot taken by acquire or TryAcquire
+func (l *limiter) Release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.running--
+	l.wakeLocked()
+}
+
+// setMax changes the number of concurrent slots, waking queued jobs if the
+// limit grew
+func (l *limiter) setMax(max int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.max = max
+	l.wakeLocked()
+}
+
+// queued returns the number of jobs waiting for a slot
+func (l *limiter) queued() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.waiters)
+}
+
+// wakeLocked hands free slots to waiters. The caller must hold l.mu.
+func (l *limiter) wakeLocked() {
+	for l.running < l.max && len(l.waiters) > 0 {
+		l.running++
+		close(l.waiters[0])
+		l.waiters = l.waiters[1:]
+	}
+}
@@ -0,0 +1,329 @@
+// File: backend/internal/jobs/manager.go
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"backend/internal/tools"
+	"backend/internal/validation"
+)
+
+// retention is how long finished jobs remain available after completion
+const retention = time.Hour
+
+var (
+	// ErrNotFound is returned when a job ID is unknown
+	ErrNotFound = errors.New("job not found")
+	// ErrFinished is returned when cancelling a job that already finished
+	ErrFinished = errors.New("job already finished")
+	// ErrShuttingDown is returned for new jobs once the manager is draining
+	ErrShuttingDown = errors.New("server shutting down")
+)
+
+// UnknownToolError is returned when a job requests a tool that is not registered
+type UnknownToolError struct {
+	Tool string
+}
+
+func (e *UnknownToolError) Error() string {
+	return fmt.Sprintf("unknown command type: %s", e.Tool)
+}
+
+// Limits bounds job execution across all tools
+type Limits struct {
+	// MaxConcurrent is the number of jobs that may run at once; further
+	// jobs stay queued until a slot frees up
+	MaxConcurrent int
+	// Timeout is the longest a job may run before it is stopped
+	Timeout time.Duration
+}
+
+// Settings is the reloadable configuration a Manager runs jobs with. Each
+// job is validated and limited by the single Settings value current when it
+// is started.
+type Settings struct {
+	Registry  *tools.Registry
+	Validator *validation.Validator
+	// RBAC, when set, limits each client to what its roles permit
+	RBAC   *validation.RBAC
+	Limits Limits
+}
+
+// Manager validates, runs and tracks tool executions
+type Manager struct {
+	settings atomic.Pointer[Settings]
+	slots    *limiter
+
+	mu        sync.RWMutex
+	jobs      map[string]*Job
+	observers []Observer
+	draining  bool
+	// running counts jobs whose observers have not yet been notified of
+	// their completion
+	running sync.WaitGroup
+}
+
+// NewManager creates a job manager running with settings
+func NewManager(settings Settings) *Manager {
+	m := &Manager{
+		slots: newLimiter(settings.Limits.MaxConcurrent),
+		jobs:  make(map[string]*Job),
+	}
+	m.settings.Store(&settings)
+	return m
+}
+
+// Apply atomically replaces the manager's settings. Running jobs keep the
+// settings they were started with.
+func (m *Manager) Apply(settings Settings) {
+	m.settings.Store(&settings)
+	m.slots.setMax(settings.Limits.MaxConcurrent)
+}
+
+// AddObserver registers o to be notified of job lifecycle changes. It must
+// be called before the manager starts any jobs.
+func (m *Manager) AddObserver(o Observer) {
+	m.observers = append(m.observers, o)
+}
+
+// Registry returns the tools currently available
+func (m *Manager) Registry() *tools.Registry {
+	return m.settings.Load().Registry
+}
+
+// Limits returns the limits new jobs run with
+func (m *Manager) Limits() Limits {
+	return m.settings.Load().Limits
+}
+
+// QueueDepth returns the number of jobs waiting for a free slot
+func (m *Manager) QueueDepth() int {
+	return m.slots.queued()
+}
+
+// Start validates the parameters for the named tool and runs it in the
+// background on behalf of client. Validation failures, and requests the
+// client's roles do not permit, are returned before any job is created.
+func (m *Manager) Start(tool string, params map[string]interface{}, client Client) (*Job, error) {
+	m.mu.RLock()
+	draining := m.draining
+	m.mu.RUnlock()
+	if draining {
+		m.rejected(tool, params, client, ErrShuttingDown)
+		return nil, ErrShuttingDown
+	}
+
+	settings := m.settings.Load()
+	inv, err := settings.prepare(tool, params, &client)
+	if err != nil {
+		m.rejected(tool, params, client, err)
+		return nil, err
+	}
+
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	job := newJob(id, tool, params, inv.Argv(), client, cancel)
+
+	m.mu.Lock()
+	if m.draining {
+		m.mu.Unlock()
+		cancel()
+		m.rejected(tool, params, client, ErrShuttingDown)
+		return nil, ErrShuttingDown
+	}
+	m.pruneLocked()
+	m.jobs[id] = job
+	m.running.Add(1)
+	m.mu.Unlock()
+
+	for _, o := range m.observers {
+		o.JobStarted(job)
+	}
+
+	go m.run(ctx, job, inv, settings.Limits.Timeout)
+	return job, nil
+}
+
+// Validate checks a request the way Start does, without running it or
+// notifying observers
+func (m *Manager) Validate(tool string, params map[string]interface{}, client Client) error {
+	_, err := m.settings.Load().prepare(tool, params, &client)
+	return err
+}
+
+// prepare validates a request against the settings and the client's roles,
+// recording the roles the client was authorised with
+func (s *Settings) prepare(tool string, params map[string]interface{}, client *Client) (tools.Invocation, error) {
+	t, ok := s.Registry.Lookup(tool)
+	if !ok {
+		return nil, &UnknownToolError{Tool: tool}
+	}
+	v := s.Validator
+	if s.RBAC != nil {
+		client.Roles = s.RBAC.RolesFor(client.Identity, client.Roles)
+		v = v.WithRoles(s.RBAC, client.Roles)
+		if err := v.CheckTool(tool); err != nil {
+			return nil, err
+		}
+	}
+	return t.Prepare(v, params)
+}
+
+// run executes an invocation and records its output and outcome on job
+func (m *Manager) run(ctx context.Context, job *Job, inv tools.Invocation, timeout time.Duration) {
+	defer m.running.Done()
+	defer job.cancel()
+	defer func() {
+		for _, o := range m.observers {
+			o.JobFinished(job)
+		}
+	}()
+
+	if err := m.slots.acquire(ctx); err != nil {
+		job.finish(StateCancelled, -1, errors.New("job cancelled"), nil)
+		return
+	}
+	defer m.slots.Release()
+
+	runCtx, cancel := context.WithTimeout(tools.WithSlots(ctx, m.slots), timeout)
+	defer cancel()
+	job.start()
+
+	output := make(chan string)
+	forwarded := make(chan struct{})
+	go func() {
+		defer close(forwarded)
+		for line := range output {
+			job.write(line)
+		}
+	}()
+
+	result, err := inv.Run(runCtx, output)
+	close(output)
+	<-forwarded
+
+	state := StateSucceeded
+	switch {
+	case ctx.Err() == nil && runCtx.Err() != nil:
+		state = StateFailed
+		err = fmt.Errorf("job timed out after %s", timeout)
+	case ctx.Err() != nil:
+		state = StateCancelled
+		err = errors.New("job cancelled")
+	case err != nil:
+		state = StateFailed
+	}
+	job.finish(state, tools.ExitCode(err), err, result)
+}
+
+// rejected notifies observers of a request that did not become a job
+func (m *Manager) rejected(tool string, params map[string]interface{}, client Client, err error) {
+	for _, o := range m.observers {
+		o.JobRejected(tool, params, client, err)
+	}
+}
+
+// Get returns the job with the given ID
+func (m *Manager) Get(id string) (*Job, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	job, ok := m.jobs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return job, nil
+}
+
+// Cancel stops the job with the given ID
+func (m *Manager) Cancel(id string) (*Job, error) {
+	job, err := m.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if job.State().Finished() {
+		return job, ErrFinished
+	}
+	job.Cancel()
+	return job, nil
+}
+
+// Drain stops the manager from accepting new jobs. Jobs already started keep
+// running.
+func (m *Manager) Drain() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.draining = true
+}
+
+// Wait blocks until every job has finished and observers have been notified,
+// or ctx is done. New jobs must not be started while waiting.
+func (m *Manager) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		m.running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// CancelAll stops every job that is still queued or running and returns how
+// many were cancelled
+func (m *Manager) CancelAll() int {
+	active := m.active()
+	for _, job := range active {
+		job.Cancel()
+	}
+	return len(active)
+}
+
+// active returns the jobs that have not finished
+func (m *Manager) active() []*Job {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var active []*Job
+	for _, job := range m.jobs {
+		if !job.State().Finished() {
+			active = append(active, job)
+		}
+	}
+	return active
+}
+
+// pruneLocked forgets jobs that finished longer ago than the retention period.
+// The caller must hold m.mu.
+func (m *Manager) pruneLocked() {
+	cutoff := time.Now().Add(-retention)
+	for id, job := range m.jobs {
+		job.mu.Lock()
+		expired := job.state.Finished() && job.finishedAt.Before(cutoff)
+		job.mu.Unlock()
+		if expired {
+			delete(m.jobs, id)
+		}
+	}
+}
+
+// newID returns a random, unguessable job identifier
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,246 @@
+// File: backend/internal/jobs/manager_test.go
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"backend/internal/tools"
+	"backend/internal/validation"
+)
+
+// fakeTool writes its target and finishes, or blocks until released or
+// cancelled when release is set
+type fakeTool struct {
+	target  string
+	release chan struct{}
+}
+
+func (fakeTool) Name() string { return "fake" }
+
+func (t fakeTool) Prepare(_ *validation.Validator, params map[string]interface{}) (tools.Invocation, error) {
+	target, ok := params["target"].(string)
+	if !ok {
+		return nil, &validation.ValidationError{Field: "target", Message: "target is required"}
+	}
+	t.target = target
+	return t, nil
+}
+
+func (t fakeTool) Argv() []string { return []string{"fake", t.target} }
+
+func (t fakeTool) Run(ctx context.Context, output chan<- string) (interface{}, error) {
+	output <- t.target
+	if t.release != nil {
+		select {
+		case <-t.release:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return t.target, nil
+}
+
+// recorder counts observer notifications
+type recorder struct {
+	mu                          sync.Mutex
+	rejected, started, finished int
+}
+
+func (r *recorder) JobRejected(string, map[string]interface{}, Client, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rejected++
+}
+
+func (r *recorder) JobStarted(*Job) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.started++
+}
+
+func (r *recorder) JobFinished(*Job) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.finished++
+}
+
+func (r *recorder) counts() (int, int, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rejected, r.started, r.finished
+}
+
+func newTestManager(t *testing.T, tool fakeTool, maxConcurrent int) (*Manager, *recorder) {
+	t.Helper()
+	v, err := validation.NewValidator(validation.DefaultPolicy())
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewManager(Settings{
+		Registry:  tools.NewRegistry(tool),
+		Validator: v,
+		Limits:    Limits{MaxConcurrent: maxConcurrent, Timeout: time.Minute},
+	})
+	rec := &recorder{}
+	m.AddObserver(rec)
+	return m, rec
+}
+
+func waitDone(t *testing.T, job *Job) {
+	t.Helper()
+	select {
+	case <-job.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatalf("job %s did not finish", job.ID)
+	}
+}
+
+func TestManagerStart(t *testing.T) {
+	m, rec := newTestManager(t, fakeTool{}, 1)
+
+	var unknown *UnknownToolError
+	if _, err := m.Start("nope", nil, Client{}); !errors.As(err, &unknown) {
+		t.Errorf("unknown tool error = %v, want UnknownToolError", err)
+	}
+	var invalid *validation.ValidationError
+	if _, err := m.Start("fake", map[string]interface{}{}, Client{}); !errors.As(err, &invalid) {
+		t.Errorf("invalid parameters error = %v, want ValidationError", err)
+	}
+
+	job, err := m.Start("fake", map[string]interface{}{"target": "example.com"}, Client{Identity: "basic:alice"})
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	waitDone(t, job)
+	snap := job.Snapshot()
+	if snap.State != StateSucceeded || snap.Result != "example.com" || job.Output() != "example.com\n" {
+		t.Errorf("finished job = %+v, output %q", snap, job.Output())
+	}
+	if got, err := m.Get(job.ID); err != nil || got != job {
+		t.Errorf("Get = %v, %v", got, err)
+	}
+	if _, err := m.Get("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get unknown error = %v, want ErrNotFound", err)
+	}
+
+	events, _ := job.Events(0)
+	var types []EventType
+	for _, ev := range events {
+		types = append(types, ev.Type)
+	}
+	if len(types) != 3 || types[0] != EventStarted || types[1] != EventOutput || types[2] != EventCompleted {
+		t.Errorf("events = %v, want started, output, completed", types)
+	}
+
+	if err := m.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if rejected, started, finished := rec.counts(); rejected != 2 || started != 1 || finished != 1 {
+		t.Errorf("observed %d rejected, %d started, %d finished, want 2, 1, 1", rejected, started, finished)
+	}
+
+	m.Drain()
+	if _, err := m.Start("fake", map[string]interface{}{"target": "example.com"}, Client{}); !errors.Is(err, ErrShuttingDown) {
+		t.Errorf("Start while draining error = %v, want ErrShuttingDown", err)
+	}
+}
+
+func TestManagerValidate(t *testing.T) {
+	m, rec := newTestManager(t, fakeTool{}, 1)
+
+	if err := m.Validate("fake", map[string]interface{}{"target": "example.com"}, Client{}); err != nil {
+		t.Errorf("Validate: %v", err)
+	}
+	var invalid *validation.ValidationError
+	if err := m.Validate("fake", map[string]interface{}{}, Client{}); !errors.As(err, &invalid) {
+		t.Errorf("Validate invalid parameters error = %v, want ValidationError", err)
+	}
+	if rejected, started, _ := rec.counts(); rejected != 0 || started != 0 {
+		t.Errorf("Validate notified observers: %d rejected, %d started", rejected, started)
+	}
+	if len(m.active()) != 0 {
+		t.Errorf("Validate created a job")
+	}
+}
+
+func TestManagerCancel(t *testing.T) {
+	tool := fakeTool{release: make(chan struct{})}
+	m, _ := newTestManager(t, tool, 1)
+	params := map[string]interface{}{"target": "example.com"}
+
+	running, err := m.Start("fake", params, Client{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for running.State() != StateRunning && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	queued, err := m.Start("fake", params, Client{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for m.QueueDepth() != 1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if depth := m.QueueDepth(); depth != 1 {
+		t.Fatalf("QueueDepth = %d, want 1", depth)
+	}
+
+	// A queued job is cancelled without ever starting
+	if _, err := m.Cancel(queued.ID); err != nil {
+		t.Fatalf("Cancel queued: %v", err)
+	}
+	waitDone(t, queued)
+	if snap := queued.Snapshot(); snap.State != StateCancelled || snap.StartedAt != nil {
+		t.Errorf("cancelled queued job = %+v", snap)
+	}
+
+	if _, err := m.Cancel(running.ID); err != nil {
+		t.Fatalf("Cancel running: %v", err)
+	}
+	waitDone(t, running)
+	if state := running.State(); state != StateCancelled {
+		t.Errorf("cancelled running job state = %s", state)
+	}
+
+	if _, err := m.Cancel(running.ID); !errors.Is(err, ErrFinished) {
+		t.Errorf("Cancel finished job error = %v, want ErrFinished", err)
+	}
+	if _, err := m.Cancel("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Cancel unknown job error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestLimiterLendsFreeSlots(t *testing.T) {
+	l := newLimiter(2)
+	if err := l.acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !l.TryAcquire() {
+		t.Fatal("TryAcquire failed with a free slot")
+	}
+	if l.TryAcquire() {
+		t.Fatal("TryAcquire succeeded with every slot taken")
+	}
+
+	// A queued job gets the next free slot, not a borrower
+	acquired := make(chan struct{})
+	go func() {
+		l.acquire(context.Background())
+		close(acquired)
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for l.queued() != 1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	l.Release()
+	<-acquired
+	if l.TryAcquire() {
+		t.Error("TryAcquire took the slot handed to a queued job")
+	}
+}
@@ -0,0 +1,105 @@
+// File: backend/internal/jobs/observer.go
+package jobs
+
+import (
+	"errors"
+
+	"backend/internal/validation"
+	"backend/pkg/logger"
+)
+
+// Client identifies who requested a job
+type Client struct {
+	IP string `json:"ip"`
+	// Transport is the API the job was requested through, e.g. "websocket"
+	Transport string `json:"transport"`
+	// Identity is the authenticated client, empty for anonymous requests
+	Identity string `json:"identity,omitempty"`
+	// Roles are the roles claimed by the client's credentials and, once the
+	// job is started, the roles it was authorised with
+	Roles []string `json:"roles,omitempty"`
+	// Schedule is the ID of the schedule that started the job, if any
+	Schedule string `json:"schedule,omitempty"`
+}
+
+// Observer is notified of job lifecycle changes
+type Observer interface {
+	// JobRejected is called when a request fails validation or policy checks
+	JobRejected(tool string, params map[string]interface{}, client Client, err error)
+	// JobStarted is called once a job has been accepted
+	JobStarted(job *Job)
+	// JobFinished is called after a job reaches a terminal state
+	JobFinished(job *Job)
+}
+
+// auditObserver writes every job to the command execution log
+type auditObserver struct {
+	log *logger.Logger
+}
+
+// NewAuditObserver returns an Observer recording jobs in the command log
+func NewAuditObserver(log *logger.Logger) Observer {
+	return &auditObserver{log: log}
+}
+
+func (a *auditObserver) JobRejected(tool string, params map[string]interface{}, client Client, err error) {
+	reason := err.Error()
+	var valErr *validation.ValidationError
+	if errors.As(err, &valErr) {
+		reason = valErr.Error()
+	}
+	a.log.LogCommand(logger.CommandRecord{
+		Event:      logger.EventRejected,
+		Tool:       tool,
+		Parameters: params,
+		ClientIP:   client.IP,
+		Identity:   client.Identity,
+		Roles:      client.Roles,
+		Transport:  client.Transport,
+		Decision:   logger.DecisionDenied,
+		Reason:     reason,
+	})
+}
+
+func (a *auditObserver) JobStarted(job *Job) {
+	a.log.LogCommand(logger.CommandRecord{
+		Event:      logger.EventStarted,
+		JobID:      job.ID,
+		Tool:       job.Tool,
+		Argv:       job.Argv,
+		Parameters: job.Params,
+		ClientIP:   job.Client.IP,
+		Identity:   job.Client.Identity,
+		Roles:      job.Client.Roles,
+		Transport:  job.Client.Transport,
+		Decision:   logger.DecisionAllowed,
+	})
+}
+
+func (a *auditObserver) JobFinished(job *Job) {
+	snap := job.Snapshot()
+	rec := logger.CommandRecord{
+		Event:       logger.EventCompleted,
+		JobID:       job.ID,
+		Tool:        job.Tool,
+		Argv:        job.Argv,
+		ClientIP:    job.Client.IP,
+		Identity:    job.Client.Identity,
+		Roles:       job.Client.Roles,
+		Transport:   job.Client.Transport,
+		Decision:    logger.DecisionAllowed,
+		Reason:      snap.Error,
+		State:       string(snap.State),
+		ExitCode:    snap.ExitCode,
+		OutputBytes: snap.OutputBytes,
+	}
+	if snap.StartedAt != nil && snap.FinishedAt != nil {
+		rec.Duration = snap.FinishedAt.Sub(*snap.StartedAt)
+	}
+	a.log.LogCommand(rec)
+
+	// A failure without an exit code never reached the tool itself
+	if snap.State == StateFailed && snap.ExitCode != nil && *snap.ExitCode < 0 {
+		a.log.LogError(errors.New(snap.Error), "job "+job.ID)
+	}
+}
@@ -0,0 +1,134 @@
+// File: backend/internal/metrics/metrics.go
+package metrics
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/gofiber/websocket/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"backend/internal/jobs"
+	"backend/internal/validation"
+)
+
+const namespace = "nettools"
+
+// Outcome label for requests rejected before a job was created
+const outcomeRejected = "rejected"
+
+// Metrics holds the Prometheus collectors for the service
+type Metrics struct {
+	registry *prometheus.Registry
+
+	jobs               *prometheus.CounterVec
+	jobDuration        *prometheus.HistogramVec
+	jobOutputBytes     *prometheus.HistogramVec
+	validationFailures *prometheus.CounterVec
+	rateLimited        prometheus.Counter
+	wsConnections      prometheus.Gauge
+}
+
+// New creates the collectors, including Go runtime and process metrics, and
+// reports the queue depth of manager
+func New(manager *jobs.Manager) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		jobs: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "jobs_total",
+			Help:      "Jobs by tool and outcome (succeeded, failed, cancelled or rejected).",
+		}, []string{"tool", "outcome"}),
+		jobDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "job_duration_seconds",
+			Help:      "Time from a job starting to run until it finished.",
+			Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120},
+		}, []string{"tool"}),
+		jobOutputBytes: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "job_output_bytes",
+			Help:      "Bytes of output written by a job.",
+			Buckets:   prometheus.ExponentialBuckets(256, 4, 8),
+		}, []string{"tool"}),
+		validationFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "validation_failures_total",
+			Help:      "Job requests rejected by validation or target policy, by tool and field.",
+		}, []string{"tool", "field"}),
+		rateLimited: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "rate_limit_rejections_total",
+			Help:      "Requests rejected by the per IP rate limiter.",
+		}),
+		wsConnections: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "websocket_connections",
+			Help:      "Open WebSocket connections.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.jobs,
+		m.jobDuration,
+		m.jobOutputBytes,
+		m.validationFailures,
+		m.rateLimited,
+		m.wsConnections,
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "job_queue_depth",
+			Help:      "Jobs waiting for a free execution slot.",
+		}, func() float64 { return float64(manager.QueueDepth()) }),
+	)
+	manager.AddObserver(m)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus text format
+func (m *Metrics) Handler() fiber.Handler {
+	return adaptor.HTTPHandler(promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{}))
+}
+
+// RateLimited counts a request rejected by the rate limiter
+func (m *Metrics) RateLimited(key string) {
+	m.rateLimited.Inc()
+}
+
+// WebSocket wraps a WebSocket handler to track open connections
+func (m *Metrics) WebSocket(handler func(*websocket.Conn)) func(*websocket.Conn) {
+	return func(c *websocket.Conn) {
+		m.wsConnections.Inc()
+		defer m.wsConnections.Dec()
+		handler(c)
+	}
+}
+
+func (m *Metrics) JobRejected(tool string, params map[string]interface{}, client jobs.Client, err error) {
+	// Tool names come from the client, so keep unknown ones out of the labels
+	var unknown *jobs.UnknownToolError
+	if errors.As(err, &unknown) {
+		tool = "unknown"
+	}
+	m.jobs.WithLabelValues(tool, outcomeRejected).Inc()
+	var valErr *validation.ValidationError
+	if errors.As(err, &valErr) {
+		m.validationFailures.WithLabelValues(tool, valErr.Field).Inc()
+	}
+}
+
+func (m *Metrics) JobStarted(job *jobs.Job) {}
+
+func (m *Metrics) JobFinished(job *jobs.Job) {
+	snap := job.Snapshot()
+	m.jobs.WithLabelValues(job.Tool, string(snap.State)).Inc()
+	m.jobOutputBytes.WithLabelValues(job.Tool).Observe(float64(snap.OutputBytes))
+	if snap.StartedAt != nil && snap.FinishedAt != nil {
+		m.jobDuration.WithLabelValues(job.Tool).Observe(snap.FinishedAt.Sub(*snap.StartedAt).Seconds())
+	}
+}
@@ -0,0 +1,43 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"backend/internal/jobs"
+	"backend/internal/tools"
+	"backend/internal/validation"
+)
+
+func TestJobRejected(t *testing.T) {
+	manager := jobs.NewManager(jobs.Settings{
+		Registry:  tools.DefaultRegistry(),
+		Validator: validation.Current(),
+		Limits:    jobs.Limits{MaxConcurrent: 1},
+	})
+	m := New(manager)
+
+	m.JobRejected("ping", nil, jobs.Client{}, &validation.ValidationError{Field: "count", Message: "too many"})
+	m.JobRejected("ping", nil, jobs.Client{}, errors.New("denied"))
+	m.JobRejected("bogus", nil, jobs.Client{}, &jobs.UnknownToolError{Tool: "bogus"})
+
+	tests := []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{"ping rejections", testutil.ToFloat64(m.jobs.WithLabelValues("ping", outcomeRejected)), 2},
+		{"unknown tool rejections", testutil.ToFloat64(m.jobs.WithLabelValues("unknown", outcomeRejected)), 1},
+		{"count validation failures", testutil.ToFloat64(m.validationFailures.WithLabelValues("ping", "count")), 1},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+	if n := testutil.CollectAndCount(m.jobs); n != 2 {
+		t.Errorf("jobs_total has %d series, want 2", n)
+	}
+}
@@ -0,0 +1,73 @@
+// File: backend/internal/schedule/cron_test.go
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	// A Wednesday
+	from := time.Date(2024, time.January, 10, 10, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"* * * * *", "2024-01-10 10:08"},
+		{"*/5 * * * *", "2024-01-10 10:10"},
+		{"0 * * * *", "2024-01-10 11:00"},
+		{"@daily", "2024-01-11 00:00"},
+		{"30 9 * * mon-fri", "2024-01-11 09:30"},
+		{"0 12 * * 0", "2024-01-14 12:00"},
+		{"0 12 * * 7", "2024-01-14 12:00"},
+		{"15 6 1,15 * *", "2024-01-15 06:15"},
+		{"0 0 1 mar *", "2024-03-01 00:00"},
+		{"0 0 29 2 *", "2024-02-29 00:00"},
+		{"10/20 8 * * *", "2024-01-11 08:10"},
+		// Both day fields restricted: either may match
+		{"0 0 20 * fri", "2024-01-12 00:00"},
+		// A day field starting with * restricts together with the other
+		{"0 0 */2 * fri", "2024-01-19 00:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			c, err := ParseCron(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseCron(%q): %v", tt.expr, err)
+			}
+			if got := c.Next(from).Format("2006-01-02 15:04"); got != tt.want {
+				t.Errorf("Next() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	never, err := ParseCron("0 0 30 2 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := never.Next(from); !got.IsZero() {
+		t.Errorf("Next() of an impossible date = %s, want zero", got)
+	}
+}
+
+func TestParseCronErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"* * * foo *",
+	} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q) succeeded, want error", expr)
+		}
+	}
+}
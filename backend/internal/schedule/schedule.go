@@ -0,0 +1,421 @@
+// File: backend/internal/schedule/schedule.go
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"backend/internal/jobs"
+	"backend/internal/store"
+	"backend/internal/validation"
+)
+
+const (
+	// tick is how often the scheduler looks for due schedules
+	tick = time.Second
+	// maxNameLength bounds a schedule's display name
+	maxNameLength = 100
+	// cronGapSamples is how many consecutive runs of a cron expression are
+	// checked against the minimum interval
+	cronGapSamples = 60
+)
+
+var (
+	// ErrNotFound is returned when a schedule ID is unknown
+	ErrNotFound = errors.New("schedule not found")
+	// ErrLimitReached is returned when creating more schedules than allowed
+	ErrLimitReached = errors.New("schedule limit reached")
+)
+
+// Limits bounds the schedules clients may create
+type Limits struct {
+	// MinInterval is the shortest time allowed between two runs
+	MinInterval time.Duration
+	// MaxSchedules caps the number of stored schedules
+	MaxSchedules int
+}
+
+// Scheduler starts the jobs of stored schedules when they are due. Jobs are
+// started through the job manager on behalf of the schedule's owner, so they
+// are validated, authorised and limited like interactive jobs at the time
+// they run.
+type Scheduler struct {
+	store   *store.Store
+	manager *jobs.Manager
+	limits  atomic.Pointer[Limits]
+
+	mu sync.Mutex
+	// next holds when each active schedule is due
+	next map[string]time.Time
+	// last holds the most recent job of each schedule, so a run is skipped
+	// while the previous one is still going
+	last map[string]*jobs.Job
+}
+
+// New creates a scheduler for the schedules in s, starting jobs with m
+func New(s *store.Store, m *jobs.Manager, limits Limits) *Scheduler {
+	sch := &Scheduler{
+		store:   s,
+		manager: m,
+		next:    make(map[string]time.Time),
+		last:    make(map[string]*jobs.Job),
+	}
+	sch.limits.Store(&limits)
+	return sch
+}
+
+// Apply replaces the limits checked when schedules are created or changed.
+// Existing schedules are not revalidated.
+func (s *Scheduler) Apply(limits Limits) {
+	s.limits.Store(&limits)
+}
+
+// Run loads the stored schedules and starts their jobs as they fall due
+// until ctx is done. Runs missed while the server was down are skipped.
+func (s *Scheduler) Run(ctx context.Context) {
+	if err := s.load(time.Now()); err != nil {
+		slog.Error("loading schedules failed", "error", err)
+	}
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			for _, id := range s.due(now) {
+				s.fire(id, now)
+			}
+		}
+	}
+}
+
+// load schedules the next run of every active schedule after now
+func (s *Scheduler) load(now time.Time) error {
+	schedules, err := s.store.Schedules()
+	if err != nil {
+		return err
+	}
+	for _, sched := range schedules {
+		if sched.Paused {
+			continue
+		}
+		next := nextRun(&sched, now)
+		updated, err := s.store.UpdateSchedule(sched.ID, func(st *store.Schedule) error {
+			st.NextRunAt = timePtr(next)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		s.track(updated)
+	}
+	return nil
+}
+
+// due returns the schedules whose next run is at or before now
+func (s *Scheduler) due(now time.Time) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var ids []string
+	for id, next := range s.next {
+		if !next.After(now) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// fire starts a schedule's job and records the outcome
+func (s *Scheduler) fire(id string, now time.Time) {
+	sched, err := s.store.Schedule(id)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			s.forget(id)
+		} else {
+			slog.Error("loading schedule failed", "schedule", id, "error", err)
+		}
+		return
+	}
+	if sched.Paused {
+		s.forget(id)
+		return
+	}
+
+	s.mu.Lock()
+	prev := s.last[id]
+	s.mu.Unlock()
+
+	var job *jobs.Job
+	if prev != nil && !prev.State().Finished() {
+		err = fmt.Errorf("skipped, previous job %s is still running", prev.ID)
+	} else {
+		client := sched.Owner
+		client.Roles = nil
+		client.Transport = "schedule"
+		client.Schedule = sched.ID
+		job, err = s.manager.Start(sched.Tool, sched.Parameters, client)
+	}
+	if err != nil {
+		slog.Warn("scheduled job not started", "schedule", id, "tool", sched.Tool, "error", err)
+	} else {
+		slog.Info("scheduled job started", "schedule", id, "tool", sched.Tool, "jobId", job.ID)
+	}
+
+	next := nextRun(sched, now)
+	updated, updateErr := s.store.UpdateSchedule(id, func(st *store.Schedule) error {
+		st.LastRunAt = &now
+		if job != nil {
+			st.LastJobID = job.ID
+			st.LastError = ""
+		} else {
+			st.LastError = err.Error()
+		}
+		if !st.Paused {
+			st.NextRunAt = timePtr(next)
+		}
+		return nil
+	})
+	if updateErr != nil {
+		slog.Error("saving schedule failed", "schedule", id, "error", updateErr)
+		s.forget(id)
+		return
+	}
+
+	s.mu.Lock()
+	if job != nil {
+		s.last[id] = job
+	}
+	s.mu.Unlock()
+	s.track(updated)
+}
+
+// track records when a schedule is next due
+func (s *Scheduler) track(sched *store.Schedule) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if sched.Paused || sched.NextRunAt == nil {
+		delete(s.next, sched.ID)
+		return
+	}
+	s.next[sched.ID] = *sched.NextRunAt
+}
+
+func (s *Scheduler) forget(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.next, id)
+	delete(s.last, id)
+}
+
+// List returns every schedule, oldest first
+func (s *Scheduler) List() ([]store.Schedule, error) {
+	return s.store.Schedules()
+}
+
+// Get returns the schedule with the given ID
+func (s *Scheduler) Get(id string) (*store.Schedule, error) {
+	sched, err := s.store.Schedule(id)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, ErrNotFound
+	}
+	return sched, err
+}
+
+// Create validates and stores a new schedule, filling in its ID and next
+// run. The job it runs is validated on behalf of sched.Owner. Roles claimed
+// by the owner's credentials are dropped, as a credential only vouches for
+// them while it is valid; each run gets the roles assigned to the owner's
+// identity at that time.
+func (s *Scheduler) Create(sched *store.Schedule) error {
+	sched.Owner.Roles = nil
+	if err := s.validate(sched); err != nil {
+		return err
+	}
+	if !sched.Paused {
+		sched.NextRunAt = timePtr(nextRun(sched, time.Now()))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	existing, err := s.store.Schedules()
+	if err != nil {
+		return err
+	}
+	if len(existing) >= s.limits.Load().MaxSchedules {
+		return ErrLimitReached
+	}
+	if err := s.store.CreateSchedule(sched); err != nil {
+		return err
+	}
+	if sched.NextRunAt != nil {
+		s.next[sched.ID] = *sched.NextRunAt
+	}
+	return nil
+}
+
+// Update replaces what a schedule runs and when, keeping its owner and run
+// history
+func (s *Scheduler) Update(id string, spec store.Schedule) (*store.Schedule, error) {
+	current, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	spec.Owner = current.Owner
+	spec.Owner.Roles = nil
+	if err := s.validate(&spec); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	updated, err := s.store.UpdateSchedule(id, func(st *store.Schedule) error {
+		st.Name = spec.Name
+		st.Tool = spec.Tool
+		st.Parameters = spec.Parameters
+		st.Cron = spec.Cron
+		st.Interval = spec.Interval
+		st.Timezone = spec.Timezone
+		st.Paused = spec.Paused
+		st.UpdatedAt = now
+		st.NextRunAt = nil
+		if !st.Paused {
+			st.NextRunAt = timePtr(nextRun(st, now))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, s.storeErr(err)
+	}
+	s.track(updated)
+	return updated, nil
+}
+
+// SetPaused pauses or resumes a schedule. A resumed schedule next runs at
+// its first due time after now.
+func (s *Scheduler) SetPaused(id string, paused bool) (*store.Schedule, error) {
+	now := time.Now()
+	updated, err := s.store.UpdateSchedule(id, func(st *store.Schedule) error {
+		if st.Paused == paused {
+			return nil
+		}
+		st.Paused = paused
+		st.UpdatedAt = now
+		st.NextRunAt = nil
+		if !paused {
+			st.NextRunAt = timePtr(nextRun(st, now))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, s.storeErr(err)
+	}
+	s.track(updated)
+	return updated, nil
+}
+
+// Delete removes a schedule. Jobs it already ran stay in the history.
+func (s *Scheduler) Delete(id string) error {
+	if err := s.store.DeleteSchedule(id); err != nil {
+		return s.storeErr(err)
+	}
+	s.forget(id)
+	return nil
+}
+
+func (s *Scheduler) storeErr(err error) error {
+	if errors.Is(err, store.ErrNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// validate checks a schedule's timing against the limits and its job
+// against the manager's current validation, policy and roles
+func (s *Scheduler) validate(sched *store.Schedule) error {
+	if len(sched.Name) > maxNameLength {
+		return &validation.ValidationError{Field: "name", Message: fmt.Sprintf("name must be at most %d characters", maxNameLength)}
+	}
+	if err := validateTiming(sched, s.limits.Load().MinInterval); err != nil {
+		return err
+	}
+	return s.manager.Validate(sched.Tool, sched.Parameters, sched.Owner)
+}
+
+// validateTiming checks that exactly one of cron and interval is set, that
+// it parses, and that runs are at least minInterval apart
+func validateTiming(sched *store.Schedule, minInterval time.Duration) error {
+	switch {
+	case sched.Cron == "" && sched.Interval == "":
+		return &validation.ValidationError{Field: "cron", Message: "either cron or interval is required"}
+	case sched.Cron != "" && sched.Interval != "":
+		return &validation.ValidationError{Field: "interval", Message: "only one of cron and interval may be set"}
+	case sched.Interval != "":
+		if sched.Timezone != "" {
+			return &validation.ValidationError{Field: "timezone", Message: "timezone only applies to cron schedules"}
+		}
+		d, err := time.ParseDuration(sched.Interval)
+		if err != nil {
+			return &validation.ValidationError{Field: "interval", Message: "interval must be a duration such as 5m"}
+		}
+		if d < minInterval {
+			return &validation.ValidationError{Field: "interval", Message: fmt.Sprintf("interval must be at least %s", minInterval)}
+		}
+		return nil
+	}
+
+	c, err := ParseCron(sched.Cron)
+	if err != nil {
+		return &validation.ValidationError{Field: "cron", Message: err.Error()}
+	}
+	loc, err := time.LoadLocation(sched.Timezone)
+	if err != nil {
+		return &validation.ValidationError{Field: "timezone", Message: fmt.Sprintf("unknown timezone %q", sched.Timezone)}
+	}
+	prev := c.Next(time.Now().In(loc))
+	if prev.IsZero() {
+		return &validation.ValidationError{Field: "cron", Message: "cron expression never matches"}
+	}
+	for i := 0; i < cronGapSamples; i++ {
+		next := c.Next(prev)
+		if next.IsZero() {
+			break
+		}
+		if next.Sub(prev) < minInterval {
+			return &validation.ValidationError{Field: "cron", Message: fmt.Sprintf("cron expression runs more often than every %s", minInterval)}
+		}
+		prev = next
+	}
+	return nil
+}
+
+// nextRun returns when a validated schedule is next due after t, or the zero
+// time if it never is
+func nextRun(sched *store.Schedule, t time.Time) time.Time {
+	if sched.Interval != "" {
+		d, err := time.ParseDuration(sched.Interval)
+		if err != nil || d <= 0 {
+			return time.Time{}
+		}
+		return t.Add(d)
+	}
+	c, err := ParseCron(sched.Cron)
+	if err != nil {
+		return time.Time{}
+	}
+	loc, err := time.LoadLocation(sched.Timezone)
+	if err != nil {
+		return time.Time{}
+	}
+	return c.Next(t.In(loc))
+}
+
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
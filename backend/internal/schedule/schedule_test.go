@@ -0,0 +1,164 @@
+// File: backend/internal/schedule/schedule_test.go
+package schedule
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/internal/jobs"
+	"backend/internal/store"
+	"backend/internal/tools"
+	"backend/internal/validation"
+)
+
+// blockingTool runs until released, so overlapping runs can be observed
+type blockingTool struct {
+	release chan struct{}
+}
+
+func (blockingTool) Name() string { return "block" }
+
+func (t blockingTool) Prepare(_ *validation.Validator, params map[string]interface{}) (tools.Invocation, error) {
+	if _, ok := params["target"].(string); !ok {
+		return nil, &validation.ValidationError{Field: "target", Message: "target is required"}
+	}
+	return t, nil
+}
+
+func (blockingTool) Argv() []string { return []string{"block"} }
+
+func (t blockingTool) Run(ctx context.Context, _ chan<- string) (interface{}, error) {
+	select {
+	case <-t.release:
+	case <-ctx.Done():
+	}
+	return nil, nil
+}
+
+func newTestScheduler(t *testing.T, tool blockingTool) *Scheduler {
+	t.Helper()
+	s, err := store.Open(filepath.Join(t.TempDir(), "nettools.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	v, err := validation.NewValidator(validation.DefaultPolicy())
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := jobs.NewManager(jobs.Settings{
+		Registry:  tools.NewRegistry(tool),
+		Validator: v,
+		Limits:    jobs.Limits{MaxConcurrent: 2, Timeout: time.Minute},
+	})
+	return New(s, m, Limits{MinInterval: time.Minute, MaxSchedules: 2})
+}
+
+func TestSchedulerValidation(t *testing.T) {
+	sch := newTestScheduler(t, blockingTool{})
+
+	tests := []struct {
+		name    string
+		sched   store.Schedule
+		wantErr string
+	}{
+		{"No timing", store.Schedule{Tool: "block", Parameters: map[string]interface{}{"target": "a"}}, "either cron or interval is required"},
+		{"Both timings", store.Schedule{Tool: "block", Cron: "@hourly", Interval: "1h"}, "only one of cron and interval"},
+		{"Interval too short", store.Schedule{Tool: "block", Interval: "30s"}, "interval must be at least 1m0s"},
+		{"Bad interval", store.Schedule{Tool: "block", Interval: "often"}, "interval must be a duration"},
+		{"Bad cron", store.Schedule{Tool: "block", Cron: "* * *"}, "must have 5 fields"},
+		{"Unknown timezone", store.Schedule{Tool: "block", Cron: "@daily", Timezone: "Mars/Olympus"}, "unknown timezone"},
+		{"Cron never matches", store.Schedule{Tool: "block", Cron: "0 0 31 2 *"}, "never matches"},
+		{"Unknown tool", store.Schedule{Tool: "nope", Interval: "5m"}, "unknown command type: nope"},
+		{"Invalid parameters", store.Schedule{Tool: "block", Interval: "5m"}, "target is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := sch.Create(&tt.sched)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Create() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSchedulerRuns(t *testing.T) {
+	tool := blockingTool{release: make(chan struct{})}
+	sch := newTestScheduler(t, tool)
+
+	owner := jobs.Client{Identity: "jwt:alice", Roles: []string{"admin"}, Transport: "rest"}
+	sched := &store.Schedule{Tool: "block", Parameters: map[string]interface{}{"target": "a"}, Interval: "5m", Owner: owner}
+	if err := sch.Create(sched); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if sched.Owner.Identity != owner.Identity || len(sched.Owner.Roles) != 0 {
+		t.Errorf("stored owner = %+v, want the identity without claimed roles", sched.Owner)
+	}
+	if sched.NextRunAt == nil || time.Until(*sched.NextRunAt) < 4*time.Minute {
+		t.Fatalf("NextRunAt = %v, want about 5m from now", sched.NextRunAt)
+	}
+
+	// Due runs start a job on the owner's behalf, then skip while it runs
+	now := sched.NextRunAt.Add(time.Second)
+	sch.fire(sched.ID, now)
+	first, err := sch.Get(sched.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.LastJobID == "" || first.LastError != "" {
+		t.Fatalf("after first run: lastJobId %q, lastError %q", first.LastJobID, first.LastError)
+	}
+	job, err := sch.manager.Get(first.LastJobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Client.Schedule != sched.ID || job.Client.Transport != "schedule" {
+		t.Errorf("job client = %+v, want the schedule", job.Client)
+	}
+	if job.Client.Identity != owner.Identity || len(job.Client.Roles) != 0 {
+		t.Errorf("job client = %+v, want the owner without claimed roles", job.Client)
+	}
+	if want := now.Add(5 * time.Minute); !first.NextRunAt.Equal(want) {
+		t.Errorf("NextRunAt = %s, want %s", first.NextRunAt, want)
+	}
+
+	sch.fire(sched.ID, now.Add(5*time.Minute))
+	skipped, _ := sch.Get(sched.ID)
+	if skipped.LastJobID != first.LastJobID || !strings.Contains(skipped.LastError, "still running") {
+		t.Errorf("overlapping run: lastJobId %q, lastError %q", skipped.LastJobID, skipped.LastError)
+	}
+	close(tool.release)
+	<-job.Done()
+
+	// Paused schedules are not due
+	paused, err := sch.SetPaused(sched.ID, true)
+	if err != nil || !paused.Paused || paused.NextRunAt != nil {
+		t.Fatalf("SetPaused(true) = %+v, %v", paused, err)
+	}
+	if due := sch.due(now.Add(time.Hour)); len(due) != 0 {
+		t.Errorf("due while paused = %v", due)
+	}
+	resumed, err := sch.SetPaused(sched.ID, false)
+	if err != nil || resumed.NextRunAt == nil {
+		t.Fatalf("SetPaused(false) = %+v, %v", resumed, err)
+	}
+
+	// The limit counts stored schedules
+	if err := sch.Create(&store.Schedule{Tool: "block", Parameters: map[string]interface{}{"target": "b"}, Cron: "@hourly"}); err != nil {
+		t.Fatalf("second Create: %v", err)
+	}
+	if err := sch.Create(&store.Schedule{Tool: "block", Parameters: map[string]interface{}{"target": "c"}, Cron: "@hourly"}); !errors.Is(err, ErrLimitReached) {
+		t.Errorf("third Create error = %v, want ErrLimitReached", err)
+	}
+
+	if err := sch.Delete(sched.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := sch.Get(sched.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete error = %v, want ErrNotFound", err)
+	}
+}
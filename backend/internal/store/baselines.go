@@ -0,0 +1,99 @@
+// File: backend/internal/store/baselines.go
+package store
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"backend/internal/tools"
+)
+
+// baselinesBucket maps baseline names to JSON encoded baselines
+var baselinesBucket = []byte("baselines")
+
+// Baseline is a named snapshot of DNS answers, taken from a finished job,
+// that later answers are diffed against
+type Baseline struct {
+	tools.DNSBaseline
+	// JobID is the job the answers were taken from
+	JobID string `json:"jobId"`
+	// CreatedBy is the identity that created the baseline, empty if
+	// anonymous
+	CreatedBy string    `json:"createdBy,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// CreateBaseline stores a new baseline, failing with ErrExists if the name
+// is taken
+func (s *Store) CreateBaseline(b *Baseline) error {
+	b.CreatedAt = time.Now()
+	data, err := json.Marshal(b)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(baselinesBucket)
+		if bucket.Get([]byte(b.Name)) != nil {
+			return ErrExists
+		}
+		return bucket.Put([]byte(b.Name), data)
+	})
+}
+
+// Baseline returns the baseline with the given name
+func (s *Store) Baseline(name string) (*Baseline, error) {
+	var b Baseline
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(baselinesBucket).Get([]byte(name))
+		if data == nil {
+			return ErrNotFound
+		}
+		return json.Unmarshal(data, &b)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// Baselines returns every baseline, oldest first
+func (s *Store) Baselines() ([]Baseline, error) {
+	baselines := []Baseline{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(baselinesBucket).ForEach(func(_, data []byte) error {
+			var b Baseline
+			if err := json.Unmarshal(data, &b); err != nil {
+				return err
+			}
+			baselines = append(baselines, b)
+			return nil
+		})
+	})
+	sort.Slice(baselines, func(i, j int) bool {
+		return baselines[i].CreatedAt.Before(baselines[j].CreatedAt)
+	})
+	return baselines, err
+}
+
+// DeleteBaseline removes a baseline
+func (s *Store) DeleteBaseline(name string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(baselinesBucket)
+		if b.Get([]byte(name)) == nil {
+			return ErrNotFound
+		}
+		return b.Delete([]byte(name))
+	})
+}
+
+// DNSBaseline looks up a baseline's answers for dns-diff jobs
+func (s *Store) DNSBaseline(name string) (*tools.DNSBaseline, error) {
+	b, err := s.Baseline(name)
+	if err != nil {
+		return nil, err
+	}
+	return &b.DNSBaseline, nil
+}
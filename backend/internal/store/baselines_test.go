@@ -0,0 +1,42 @@
+package store
+
+import (
+	"errors"
+	"testing"
+
+	"backend/internal/tools"
+)
+
+func TestBaselines(t *testing.T) {
+	s := openTestStore(t)
+	answers := []tools.DNSAnswer{{DNSQuery: tools.DNSQuery{Domain: "example.com", RecordType: "A"}, Status: "NOERROR"}}
+
+	b := &Baseline{DNSBaseline: tools.DNSBaseline{Name: "before", Answers: answers}, JobID: "job"}
+	if err := s.CreateBaseline(b); err != nil {
+		t.Fatalf("CreateBaseline: %v", err)
+	}
+	if err := s.CreateBaseline(&Baseline{DNSBaseline: tools.DNSBaseline{Name: "before"}}); !errors.Is(err, ErrExists) {
+		t.Errorf("duplicate CreateBaseline err = %v, want ErrExists", err)
+	}
+
+	got, err := s.DNSBaseline("before")
+	if err != nil {
+		t.Fatalf("DNSBaseline: %v", err)
+	}
+	if len(got.Answers) != 1 || got.Answers[0].Domain != "example.com" {
+		t.Errorf("DNSBaseline answers = %+v", got.Answers)
+	}
+	if all, err := s.Baselines(); err != nil || len(all) != 1 || all[0].JobID != "job" {
+		t.Errorf("Baselines() = %+v, %v", all, err)
+	}
+
+	if err := s.DeleteBaseline("before"); err != nil {
+		t.Fatalf("DeleteBaseline: %v", err)
+	}
+	if _, err := s.Baseline("before"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Baseline after delete err = %v, want ErrNotFound", err)
+	}
+	if err := s.DeleteBaseline("before"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second DeleteBaseline err = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,233 @@
+// File: backend/internal/store/jobs.go
+package store
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"backend/internal/jobs"
+)
+
+var (
+	// jobsBucket maps job IDs to JSON encoded records
+	jobsBucket = []byte("jobs")
+	// jobsByTimeBucket indexes job IDs by creation time, keyed by the
+	// big endian creation time in nanoseconds followed by the ID
+	jobsByTimeBucket = []byte("jobsByTime")
+)
+
+// Search limits
+const (
+	DefaultSearchLimit = 50
+	MaxSearchLimit     = 500
+)
+
+// JobRecord is a finished job as kept in the history
+type JobRecord struct {
+	jobs.Snapshot
+	// Target is the host or domain the job was run against
+	Target string `json:"target,omitempty"`
+	// Output is the complete output, omitted from search results
+	Output string `json:"output,omitempty"`
+}
+
+// NewJobRecord captures a job's current state and output
+func NewJobRecord(job *jobs.Job) JobRecord {
+	snap := job.Snapshot()
+	rec := JobRecord{Snapshot: snap, Target: jobs.Target(snap.Parameters), Output: job.Output()}
+	// Batch jobs list their targets
+	if rec.Target == "" {
+		switch targets := snap.Parameters["targets"].(type) {
+		case string:
+			rec.Target = targets
+		case []interface{}:
+			names := make([]string, 0, len(targets))
+			for _, t := range targets {
+				if name, ok := t.(string); ok {
+					names = append(names, name)
+				}
+			}
+			rec.Target = strings.Join(names, ",")
+		}
+	}
+	return rec
+}
+
+// JobQuery filters a job search. Empty fields match every job.
+type JobQuery struct {
+	Tool string
+	// Target matches a case insensitive substring of the job's target
+	Target string
+	// User matches the full identity, e.g. basic:alice, or its subject
+	User  string
+	State jobs.State
+	// Schedule matches jobs started by the schedule with this ID
+	Schedule string
+	// VisibleTo, when set, limits results to the jobs this identity may
+	// read: its own and those of anonymous clients
+	VisibleTo *string
+	Since     time.Time
+	Until     time.Time
+	// Limit caps the number of results, newest first
+	Limit int
+}
+
+func (q JobQuery) matches(rec *JobRecord) bool {
+	if q.Tool != "" && rec.Tool != q.Tool {
+		return false
+	}
+	if q.Target != "" && !strings.Contains(strings.ToLower(rec.Target), strings.ToLower(q.Target)) {
+		return false
+	}
+	if q.User != "" {
+		identity := rec.Client.Identity
+		_, subject, _ := strings.Cut(identity, ":")
+		if identity != q.User && subject != q.User {
+			return false
+		}
+	}
+	if q.VisibleTo != nil && rec.Client.Identity != "" && rec.Client.Identity != *q.VisibleTo {
+		return false
+	}
+	if q.State != "" && rec.State != q.State {
+		return false
+	}
+	if q.Schedule != "" && rec.Client.Schedule != q.Schedule {
+		return false
+	}
+	return true
+}
+
+func timeKey(t time.Time, id string) []byte {
+	key := make([]byte, 8, 8+len(id))
+	binary.BigEndian.PutUint64(key, uint64(t.UnixNano()))
+	return append(key, id...)
+}
+
+// SaveJob stores or replaces a job record
+func (s *Store) SaveJob(rec JobRecord) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(jobsBucket).Put([]byte(rec.ID), data); err != nil {
+			return err
+		}
+		return tx.Bucket(jobsByTimeBucket).Put(timeKey(rec.CreatedAt, rec.ID), []byte(rec.ID))
+	})
+}
+
+// Job returns the stored record of the job with the given ID
+func (s *Store) Job(id string) (*JobRecord, error) {
+	var rec JobRecord
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(jobsBucket).Get([]byte(id))
+		if data == nil {
+			return ErrNotFound
+		}
+		return json.Unmarshal(data, &rec)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+// SearchJobs returns the jobs matching q, newest first, without their output
+func (s *Store) SearchJobs(q JobQuery) ([]JobRecord, error) {
+	if q.Limit <= 0 {
+		q.Limit = DefaultSearchLimit
+	}
+	q.Limit = min(q.Limit, MaxSearchLimit)
+
+	results := []JobRecord{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		records := tx.Bucket(jobsBucket)
+		c := tx.Bucket(jobsByTimeBucket).Cursor()
+
+		// Walk the time index backwards from the end of the range
+		var k, v []byte
+		if q.Until.IsZero() {
+			k, v = c.Last()
+		} else {
+			end := timeKey(q.Until, "")
+			k, v = c.Seek(end)
+			if k == nil {
+				k, v = c.Last()
+			}
+			for k != nil && bytes.Compare(k, end) >= 0 {
+				k, v = c.Prev()
+			}
+		}
+		start := timeKey(q.Since, "")
+		for ; k != nil && len(results) < q.Limit; k, v = c.Prev() {
+			if !q.Since.IsZero() && bytes.Compare(k, start) < 0 {
+				break
+			}
+			data := records.Get(v)
+			if data == nil {
+				continue
+			}
+			var rec JobRecord
+			if err := json.Unmarshal(data, &rec); err != nil {
+				return err
+			}
+			if q.matches(&rec) {
+				rec.Output = ""
+				results = append(results, rec)
+			}
+		}
+		return nil
+	})
+	return results, err
+}
+
+// PruneJobs removes jobs created before cutoff and returns how many were
+// removed
+func (s *Store) PruneJobs(cutoff time.Time) (int, error) {
+	removed := 0
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		records := tx.Bucket(jobsBucket)
+		index := tx.Bucket(jobsByTimeBucket)
+		end := timeKey(cutoff, "")
+		c := index.Cursor()
+		for k, v := c.First(); k != nil && bytes.Compare(k, end) < 0; k, v = c.First() {
+			if err := records.Delete(v); err != nil {
+				return err
+			}
+			if err := c.Delete(); err != nil {
+				return err
+			}
+			removed++
+		}
+		return nil
+	})
+	return removed, err
+}
+
+// jobRecorder saves every finished job to the store
+type jobRecorder struct {
+	store *Store
+}
+
+// NewJobRecorder returns an Observer keeping the history of finished jobs
+func NewJobRecorder(s *Store) jobs.Observer {
+	return &jobRecorder{store: s}
+}
+
+func (r *jobRecorder) JobRejected(string, map[string]interface{}, jobs.Client, error) {}
+
+func (r *jobRecorder) JobStarted(*jobs.Job) {}
+
+func (r *jobRecorder) JobFinished(job *jobs.Job) {
+	if err := r.store.SaveJob(NewJobRecord(job)); err != nil {
+		slog.Error("saving job history failed", "jobId", job.ID, "error", err)
+	}
+}
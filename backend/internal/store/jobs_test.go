@@ -0,0 +1,95 @@
+package store
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"backend/internal/jobs"
+)
+
+func openTestStore(t *testing.T) *Store {
+	t.Helper()
+	s, err := Open(filepath.Join(t.TempDir(), "nettools.db"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestJobHistory(t *testing.T) {
+	s := openTestStore(t)
+	now := time.Now()
+	records := []JobRecord{
+		{Snapshot: jobs.Snapshot{ID: "a", Tool: "ping", State: jobs.StateSucceeded, CreatedAt: now.Add(-72 * time.Hour),
+			Client: jobs.Client{Identity: "basic:alice"}}, Target: "gw.example.com", Output: "64 bytes"},
+		{Snapshot: jobs.Snapshot{ID: "b", Tool: "dig", State: jobs.StateSucceeded, CreatedAt: now.Add(-2 * time.Hour),
+			Client: jobs.Client{Identity: "jwt:bob"}}, Target: "example.com"},
+		{Snapshot: jobs.Snapshot{ID: "c", Tool: "ping", State: jobs.StateFailed, CreatedAt: now.Add(-time.Hour),
+			Client: jobs.Client{Identity: "jwt:bob"}}, Target: "10.0.0.1"},
+		{Snapshot: jobs.Snapshot{ID: "d", Tool: "ping", State: jobs.StateSucceeded, CreatedAt: now.Add(-time.Minute)},
+			Target: "gw.example.com"},
+	}
+	for _, rec := range records {
+		if err := s.SaveJob(rec); err != nil {
+			t.Fatalf("SaveJob: %v", err)
+		}
+	}
+
+	rec, err := s.Job("a")
+	if err != nil || rec.Output != "64 bytes" || rec.Client.Identity != "basic:alice" {
+		t.Fatalf("Job(a) = %+v, %v", rec, err)
+	}
+	if _, err := s.Job("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Job(missing) err = %v, want ErrNotFound", err)
+	}
+
+	tests := []struct {
+		name  string
+		query JobQuery
+		want  []string
+	}{
+		{"All newest first", JobQuery{}, []string{"d", "c", "b", "a"}},
+		{"Tool", JobQuery{Tool: "ping"}, []string{"d", "c", "a"}},
+		{"Target substring", JobQuery{Target: "GW.example"}, []string{"d", "a"}},
+		{"User subject", JobQuery{User: "bob"}, []string{"c", "b"}},
+		{"User identity", JobQuery{User: "basic:alice"}, []string{"a"}},
+		{"State", JobQuery{State: jobs.StateFailed}, []string{"c"}},
+		{"Since", JobQuery{Since: now.Add(-3 * time.Hour)}, []string{"d", "c", "b"}},
+		{"Until", JobQuery{Until: now.Add(-time.Hour)}, []string{"b", "a"}},
+		{"Limit", JobQuery{Limit: 2}, []string{"d", "c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.SearchJobs(tt.query)
+			if err != nil {
+				t.Fatalf("SearchJobs: %v", err)
+			}
+			var ids []string
+			for _, rec := range got {
+				ids = append(ids, rec.ID)
+				if rec.Output != "" {
+					t.Errorf("search result %s includes output", rec.ID)
+				}
+			}
+			if len(ids) != len(tt.want) {
+				t.Fatalf("ids = %v, want %v", ids, tt.want)
+			}
+			for i := range ids {
+				if ids[i] != tt.want[i] {
+					t.Fatalf("ids = %v, want %v", ids, tt.want)
+				}
+			}
+		})
+	}
+
+	removed, err := s.PruneJobs(now.Add(-24 * time.Hour))
+	if err != nil || removed != 1 {
+		t.Fatalf("PruneJobs = %d, %v, want 1", removed, err)
+	}
+	if _, err := s.Job("a"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("pruned job still stored: %v", err)
+	}
+}
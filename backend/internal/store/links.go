@@ -0,0 +1,139 @@
+// File: backend/internal/store/links.go
+package store
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// linksBucket maps permalink tokens to JSON encoded links
+var linksBucket = []byte("links")
+
+// Link is a shareable, read-only permalink to a finished job
+type Link struct {
+	// Token is the unguessable identifier in the link's URL
+	Token string `json:"token"`
+	JobID string `json:"jobId"`
+	// CreatedBy is the identity that created the link, empty if anonymous
+	CreatedBy string    `json:"createdBy,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	// ExpiresAt is when the link stops working, nil if it never expires
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// Expired reports whether the link is no longer valid at now
+func (l *Link) Expired(now time.Time) bool {
+	return l.ExpiresAt != nil && !now.Before(*l.ExpiresAt)
+}
+
+// CreateLink stores a new permalink to the job. An expiry of 0 creates a
+// link that never expires.
+func (s *Store) CreateLink(jobID, createdBy string, expiry time.Duration) (*Link, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	link := &Link{
+		Token:     base64.RawURLEncoding.EncodeToString(b),
+		JobID:     jobID,
+		CreatedBy: createdBy,
+		CreatedAt: time.Now(),
+	}
+	if expiry > 0 {
+		expiresAt := link.CreatedAt.Add(expiry)
+		link.ExpiresAt = &expiresAt
+	}
+	data, err := json.Marshal(link)
+	if err != nil {
+		return nil, err
+	}
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(linksBucket).Put([]byte(link.Token), data)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+// Link returns the permalink with the given token. Expired links are
+// reported as not found.
+func (s *Store) Link(token string) (*Link, error) {
+	var link Link
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(linksBucket).Get([]byte(token))
+		if data == nil {
+			return ErrNotFound
+		}
+		return json.Unmarshal(data, &link)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if link.Expired(time.Now()) {
+		return nil, ErrNotFound
+	}
+	return &link, nil
+}
+
+// JobLinks returns the unexpired permalinks to a job
+func (s *Store) JobLinks(jobID string) ([]Link, error) {
+	links := []Link{}
+	now := time.Now()
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(linksBucket).ForEach(func(_, data []byte) error {
+			var link Link
+			if err := json.Unmarshal(data, &link); err != nil {
+				return err
+			}
+			if link.JobID == jobID && !link.Expired(now) {
+				links = append(links, link)
+			}
+			return nil
+		})
+	})
+	return links, err
+}
+
+// RevokeLink deletes a permalink
+func (s *Store) RevokeLink(token string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(linksBucket)
+		if b.Get([]byte(token)) == nil {
+			return ErrNotFound
+		}
+		return b.Delete([]byte(token))
+	})
+}
+
+// PruneLinks removes expired links and links to jobs no longer in the
+// history, returning how many were removed
+func (s *Store) PruneLinks(now time.Time) (int, error) {
+	removed := 0
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		records := tx.Bucket(jobsBucket)
+		c := tx.Bucket(linksBucket).Cursor()
+		for k, data := c.First(); k != nil; {
+			var link Link
+			if err := json.Unmarshal(data, &link); err != nil {
+				return err
+			}
+			if !link.Expired(now) && records.Get([]byte(link.JobID)) != nil {
+				k, data = c.Next()
+				continue
+			}
+			deleted := append([]byte(nil), k...)
+			if err := c.Delete(); err != nil {
+				return err
+			}
+			removed++
+			k, data = c.Seek(deleted)
+		}
+		return nil
+	})
+	return removed, err
+}
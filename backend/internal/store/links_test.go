@@ -0,0 +1,64 @@
+package store
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"backend/internal/jobs"
+)
+
+func TestLinks(t *testing.T) {
+	s := openTestStore(t)
+	if err := s.SaveJob(JobRecord{Snapshot: jobs.Snapshot{ID: "job", CreatedAt: time.Now()}}); err != nil {
+		t.Fatal(err)
+	}
+
+	permanent, err := s.CreateLink("job", "jwt:alice", 0)
+	if err != nil {
+		t.Fatalf("CreateLink: %v", err)
+	}
+	expiring, err := s.CreateLink("job", "", time.Millisecond)
+	if err != nil {
+		t.Fatalf("CreateLink: %v", err)
+	}
+	revoked, err := s.CreateLink("job", "", 0)
+	if err != nil {
+		t.Fatalf("CreateLink: %v", err)
+	}
+	dangling, err := s.CreateLink("gone", "", 0)
+	if err != nil {
+		t.Fatalf("CreateLink: %v", err)
+	}
+	if len(permanent.Token) < 32 || permanent.Token == expiring.Token {
+		t.Errorf("tokens %q and %q are not unique and unguessable", permanent.Token, expiring.Token)
+	}
+	if err := s.RevokeLink(revoked.Token); err != nil {
+		t.Fatalf("RevokeLink: %v", err)
+	}
+	if err := s.RevokeLink(revoked.Token); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second RevokeLink err = %v, want ErrNotFound", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	if link, err := s.Link(permanent.Token); err != nil || link.JobID != "job" || link.CreatedBy != "jwt:alice" {
+		t.Errorf("Link(permanent) = %+v, %v", link, err)
+	}
+	for name, token := range map[string]string{"expired": expiring.Token, "revoked": revoked.Token, "unknown": "nope"} {
+		if _, err := s.Link(token); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Link(%s) err = %v, want ErrNotFound", name, err)
+		}
+	}
+	links, err := s.JobLinks("job")
+	if err != nil || len(links) != 1 || links[0].Token != permanent.Token {
+		t.Errorf("JobLinks = %+v, %v, want only the permanent link", links, err)
+	}
+
+	removed, err := s.PruneLinks(time.Now())
+	if err != nil || removed != 2 {
+		t.Errorf("PruneLinks = %d, %v, want the expired and dangling links", removed, err)
+	}
+	if _, err := s.Link(dangling.Token); !errors.Is(err, ErrNotFound) {
+		t.Errorf("dangling link survived pruning: %v", err)
+	}
+}
@@ -0,0 +1,138 @@
+// File: backend/internal/store/schedules.go
+package store
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"backend/internal/jobs"
+)
+
+// schedulesBucket maps schedule IDs to JSON encoded schedules
+var schedulesBucket = []byte("schedules")
+
+// Schedule runs a tool with fixed parameters on a cron or interval schedule
+type Schedule struct {
+	ID         string                 `json:"id"`
+	Name       string                 `json:"name,omitempty"`
+	Tool       string                 `json:"tool"`
+	Parameters map[string]interface{} `json:"parameters"`
+	// Cron is a five field cron expression and Interval a duration such as
+	// 5m. Exactly one of them is set.
+	Cron     string `json:"cron,omitempty"`
+	Interval string `json:"interval,omitempty"`
+	// Timezone is the IANA zone cron expressions are evaluated in, UTC when
+	// empty
+	Timezone string `json:"timezone,omitempty"`
+	Paused   bool   `json:"paused"`
+	// Owner is the client that created the schedule; its jobs run on the
+	// owner's behalf with the roles assigned to its identity
+	Owner     jobs.Client `json:"owner"`
+	CreatedAt time.Time   `json:"createdAt"`
+	UpdatedAt time.Time   `json:"updatedAt"`
+	// NextRunAt is when the schedule next runs, nil while paused
+	NextRunAt *time.Time `json:"nextRunAt,omitempty"`
+	LastRunAt *time.Time `json:"lastRunAt,omitempty"`
+	LastJobID string     `json:"lastJobId,omitempty"`
+	// LastError is why the last run could not start, empty if it did
+	LastError string `json:"lastError,omitempty"`
+}
+
+// CreateSchedule assigns the schedule an ID and stores it
+func (s *Store) CreateSchedule(sched *Schedule) error {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return err
+	}
+	sched.ID = hex.EncodeToString(b)
+	sched.CreatedAt = time.Now()
+	sched.UpdatedAt = sched.CreatedAt
+	data, err := json.Marshal(sched)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(schedulesBucket).Put([]byte(sched.ID), data)
+	})
+}
+
+// Schedule returns the schedule with the given ID
+func (s *Store) Schedule(id string) (*Schedule, error) {
+	var sched Schedule
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(schedulesBucket).Get([]byte(id))
+		if data == nil {
+			return ErrNotFound
+		}
+		return json.Unmarshal(data, &sched)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &sched, nil
+}
+
+// Schedules returns every schedule, oldest first
+func (s *Store) Schedules() ([]Schedule, error) {
+	schedules := []Schedule{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(schedulesBucket).ForEach(func(_, data []byte) error {
+			var sched Schedule
+			if err := json.Unmarshal(data, &sched); err != nil {
+				return err
+			}
+			schedules = append(schedules, sched)
+			return nil
+		})
+	})
+	sort.Slice(schedules, func(i, j int) bool {
+		return schedules[i].CreatedAt.Before(schedules[j].CreatedAt)
+	})
+	return schedules, err
+}
+
+// UpdateSchedule applies update to the stored schedule in a single
+// transaction and returns the result. An error from update aborts the
+// change.
+func (s *Store) UpdateSchedule(id string, update func(*Schedule) error) (*Schedule, error) {
+	var sched Schedule
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(schedulesBucket)
+		data := b.Get([]byte(id))
+		if data == nil {
+			return ErrNotFound
+		}
+		if err := json.Unmarshal(data, &sched); err != nil {
+			return err
+		}
+		if err := update(&sched); err != nil {
+			return err
+		}
+		sched.ID = id
+		data, err := json.Marshal(&sched)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(id), data)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &sched, nil
+}
+
+// DeleteSchedule removes a schedule. Jobs it already ran stay in the history.
+func (s *Store) DeleteSchedule(id string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(schedulesBucket)
+		if b.Get([]byte(id)) == nil {
+			return ErrNotFound
+		}
+		return b.Delete([]byte(id))
+	})
+}
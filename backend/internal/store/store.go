@@ -0,0 +1,51 @@
+// File: backend/internal/store/store.go
+package store
+
+import (
+	"errors"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	// ErrNotFound is returned when a record does not exist
+	ErrNotFound = errors.New("not found")
+	// ErrExists is returned when creating a record whose name is taken
+	ErrExists = errors.New("already exists")
+)
+
+// Store is the server's embedded database, a single bbolt file holding job
+// history, the data that refers to it, job schedules and DNS baselines
+type Store struct {
+	db *bolt.DB
+}
+
+// Open opens or creates the database at path
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range buckets {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes the database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// buckets lists every top level bucket, created when the store is opened
+var buckets = [][]byte{jobsBucket, jobsByTimeBucket, linksBucket, schedulesBucket, baselinesBucket}
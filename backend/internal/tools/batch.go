@@ -0,0 +1,371 @@
+// File: backend/internal/tools/batch.go
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"backend/internal/validation"
+)
+
+// batchTargetParams maps each batchable tool to the parameter its targets
+// are passed in
+var batchTargetParams = map[string]string{
+	"ping": "target",
+	"dig":  "domain",
+}
+
+// BatchTarget is the outcome of running the batch's tool against one target
+type BatchTarget struct {
+	Target string `json:"target"`
+	// Status is ok when the tool succeeded, otherwise failed
+	Status   string      `json:"status"`
+	Error    string      `json:"error,omitempty"`
+	ExitCode int         `json:"exitCode"`
+	Result   interface{} `json:"result,omitempty"`
+	// Reachable reports whether a pinged target answered
+	Reachable *bool `json:"reachable,omitempty"`
+	// AvgMs is a pinged target's average round trip time
+	AvgMs float64 `json:"avgMs,omitempty"`
+	// DNSStatus and Answer summarise a dig response
+	DNSStatus string   `json:"dnsStatus,omitempty"`
+	Answer    []string `json:"answer,omitempty"`
+}
+
+// Summary describes the target's outcome in a few words
+func (t BatchTarget) Summary() string {
+	switch {
+	case t.Reachable != nil && *t.Reachable:
+		return fmt.Sprintf("reachable, avg %.3f ms", t.AvgMs)
+	case t.Reachable != nil:
+		return "unreachable"
+	case t.DNSStatus != "" && len(t.Answer) > 0:
+		return t.DNSStatus + ": " + strings.Join(t.Answer, ", ")
+	case t.DNSStatus != "":
+		return t.DNSStatus + ": no answer"
+	case len(t.Answer) > 0:
+		return strings.Join(t.Answer, ", ")
+	case t.Error != "":
+		return t.Error
+	default:
+		return t.Status
+	}
+}
+
+// BatchResult is the aggregate outcome of a batch job
+type BatchResult struct {
+	Tool      string        `json:"tool"`
+	Targets   []BatchTarget `json:"targets"`
+	Succeeded int           `json:"succeeded"`
+	Failed    int           `json:"failed"`
+	// Reachable and Unreachable count pinged targets
+	Reachable   int `json:"reachable,omitempty"`
+	Unreachable int `json:"unreachable,omitempty"`
+	// AvgMs is the mean average round trip time of reachable targets
+	AvgMs float64 `json:"avgMs,omitempty"`
+}
+
+// Batch runs ping or dig against a list of targets, sharing the remaining
+// parameters. Every target is validated by the underlying tool, so the same
+// policy and role checks apply as for a single job.
+type Batch struct {
+	tools       map[string]Tool
+	maxTargets  int
+	concurrency int
+}
+
+// NewBatch creates a batch tool over the given tools, accepting up to
+// maxTargets targets of which concurrency run at once
+func NewBatch(maxTargets, concurrency int, tools ...Tool) Batch {
+	b := Batch{tools: make(map[string]Tool), maxTargets: maxTargets, concurrency: concurrency}
+	for _, t := range tools {
+		if _, ok := batchTargetParams[t.Name()]; ok {
+			b.tools[t.Name()] = t
+		}
+	}
+	return b
+}
+
+// Name implements Tool
+func (Batch) Name() string { return "batch" }
+
+// Prepare implements Tool. params holds the tool name, the targets as a list
+// or a comma or newline separated string, and the tool's other parameters.
+func (b Batch) Prepare(v *validation.Validator, params map[string]interface{}) (Invocation, error) {
+	name, _ := params["tool"].(string)
+	tool, ok := b.tools[name]
+	if !ok {
+		return nil, &validation.ValidationError{Field: "tool", Message: fmt.Sprintf("batch tool must be one of %s", strings.Join(b.toolNames(), ", "))}
+	}
+	if err := v.CheckTool(name); err != nil {
+		return nil, err
+	}
+
+	targets, err := batchTargets(params["targets"])
+	if err != nil {
+		return nil, err
+	}
+	if len(targets) > b.maxTargets {
+		return nil, &validation.ValidationError{Field: "targets", Message: fmt.Sprintf("at most %d targets are allowed", b.maxTargets)}
+	}
+
+	shared, _ := params["parameters"].(map[string]interface{})
+	inv := batchInvocation{tool: name, concurrency: b.concurrency}
+	for _, target := range targets {
+		p := make(map[string]interface{}, len(shared)+1)
+		for k, val := range shared {
+			p[k] = val
+		}
+		p[batchTargetParams[name]] = target
+		sub, err := tool.Prepare(v, p)
+		if err != nil {
+			var valErr *validation.ValidationError
+			if errors.As(err, &valErr) {
+				return nil, &validation.ValidationError{Field: "targets", Message: fmt.Sprintf("%s: %s", target, valErr.Message)}
+			}
+			return nil, err
+		}
+		inv.targets = append(inv.targets, target)
+		inv.subs = append(inv.subs, sub)
+	}
+	return inv, nil
+}
+
+func (b Batch) toolNames() []string {
+	names := make([]string, 0, len(b.tools))
+	for name := range b.tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// batchTargets reads the targets parameter, dropping blanks and duplicates
+func batchTargets(raw interface{}) ([]string, error) {
+	return listParam("targets", raw)
+}
+
+// listParam reads a list parameter given as a list or a comma or newline
+// separated string, dropping blanks and case insensitive duplicates
+func listParam(field string, raw interface{}) ([]string, error) {
+	var items []string
+	switch v := raw.(type) {
+	case []interface{}:
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, &validation.ValidationError{Field: field, Message: field + " must be strings"}
+			}
+			items = append(items, s)
+		}
+	case []string:
+		items = v
+	case string:
+		items = strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == '\n' || r == '\r' })
+	case nil:
+		return nil, &validation.ValidationError{Field: field, Message: field + " are required"}
+	default:
+		return nil, &validation.ValidationError{Field: field, Message: "invalid " + field + " format"}
+	}
+
+	seen := make(map[string]bool)
+	var targets []string
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		if item == "" || seen[strings.ToLower(item)] {
+			continue
+		}
+		seen[strings.ToLower(item)] = true
+		targets = append(targets, item)
+	}
+	if len(targets) == 0 {
+		return nil, &validation.ValidationError{Field: field, Message: field + " are required"}
+	}
+	return targets, nil
+}
+
+type batchInvocation struct {
+	tool        string
+	targets     []string
+	subs        []Invocation
+	concurrency int
+}
+
+// Argv lists every command the batch runs
+func (i batchInvocation) Argv() []string {
+	argv := []string{"batch"}
+	for _, sub := range i.subs {
+		argv = append(argv, strings.Join(sub.Argv(), " "))
+	}
+	return argv
+}
+
+// Run executes the targets concurrently, borrowing free job slots for all but
+// the first. Output lines are prefixed with their target, each target
+// reports a result line as it finishes, and a summary table follows once all
+// are done. Failing targets do not fail the batch; only cancellation does.
+func (i batchInvocation) Run(ctx context.Context, output chan<- string) (interface{}, error) {
+	result := &BatchResult{Tool: i.tool, Targets: make([]BatchTarget, len(i.targets))}
+	for n, target := range i.targets {
+		result.Targets[n] = BatchTarget{Target: target, Status: "cancelled", ExitCode: -1}
+	}
+
+	fanOut(ctx, len(i.subs), i.concurrency, func(n int) {
+		target := i.run(ctx, i.targets[n], i.subs[n], output)
+		result.Targets[n] = target
+		send(ctx, output, fmt.Sprintf("[%s] %s: %s", target.Target, target.Status, target.Summary()))
+	})
+	if err := ctx.Err(); err != nil {
+		return result, err
+	}
+
+	summarise(result)
+	for _, line := range batchTable(result) {
+		send(ctx, output, line)
+	}
+	return result, nil
+}
+
+// run executes one target, forwarding its output tagged with the target
+func (i batchInvocation) run(ctx context.Context, target string, sub Invocation, output chan<- string) BatchTarget {
+	lines := make(chan string)
+	forwarded := make(chan struct{})
+	go func() {
+		defer close(forwarded)
+		for line := range lines {
+			send(ctx, output, "["+target+"] "+line)
+		}
+	}()
+	res, err := sub.Run(ctx, lines)
+	close(lines)
+	<-forwarded
+
+	t := BatchTarget{Target: target, Status: "ok", ExitCode: ExitCode(err), Result: res}
+	if err != nil {
+		t.Status = "failed"
+		t.Error = err.Error()
+	}
+	switch r := res.(type) {
+	case *PingResult:
+		reachable := r.Received > 0
+		t.Reachable = &reachable
+		t.AvgMs = r.AvgMs
+	case *DigResult:
+		t.DNSStatus = r.Status
+		for _, rec := range r.Answer {
+			t.Answer = append(t.Answer, rec.Data)
+		}
+		t.Answer = append(t.Answer, r.Short...)
+	}
+	return t
+}
+
+// summarise fills in the batch's aggregate counts
+func summarise(result *BatchResult) {
+	var rttSum float64
+	for _, t := range result.Targets {
+		if t.Status == "ok" {
+			result.Succeeded++
+		} else {
+			result.Failed++
+		}
+		if t.Reachable == nil {
+			continue
+		}
+		if *t.Reachable {
+			result.Reachable++
+			rttSum += t.AvgMs
+		} else {
+			result.Unreachable++
+		}
+	}
+	if result.Reachable > 0 {
+		result.AvgMs = rttSum / float64(result.Reachable)
+	}
+}
+
+// batchTable renders the summary printed at the end of a batch
+func batchTable(result *BatchResult) []string {
+	var b strings.Builder
+	tw := tabwriter.NewWriter(&b, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "TARGET\tSTATUS\tRESULT")
+	for _, t := range result.Targets {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", t.Target, t.Status, t.Summary())
+	}
+	tw.Flush()
+
+	lines := []string{"", fmt.Sprintf("--- batch %s summary: %d targets ---", result.Tool, len(result.Targets))}
+	lines = append(lines, strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")...)
+	total := fmt.Sprintf("%d succeeded, %d failed", result.Succeeded, result.Failed)
+	if result.Reachable+result.Unreachable > 0 {
+		total = fmt.Sprintf("%d reachable, %d unreachable", result.Reachable, result.Unreachable)
+		if result.Reachable > 0 {
+			total += fmt.Sprintf(", avg rtt %.3f ms", result.AvgMs)
+		}
+	}
+	return append(lines, total)
+}
+
+// send delivers a line unless ctx is done
+// fanOut calls fn for 0 to n-1, up to limit calls at once, and waits for them
+// to return. The first call runs on the job's own slot and every further
+// concurrent call on a slot borrowed from ctx, so parallel sub-invocations
+// count against the job limit. It stops starting calls once ctx is done.
+func fanOut(ctx context.Context, n, limit int, fn func(n int)) {
+	slots := slotsFrom(ctx)
+	// finished receives whether a call that returned ran on a borrowed slot
+	finished := make(chan bool, n)
+	ownFree, borrowed := true, 0
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for next := 0; next < n; {
+		extra := false
+		switch {
+		case ownFree:
+			ownFree = false
+		case borrowed < limit-1 && slots.TryAcquire():
+			borrowed++
+			extra = true
+		default:
+			select {
+			case b := <-finished:
+				if b {
+					borrowed--
+				} else {
+					ownFree = true
+				}
+			case <-ctx.Done():
+				return
+			}
+			continue
+		}
+		if ctx.Err() != nil {
+			if extra {
+				slots.Release()
+			}
+			return
+		}
+		wg.Add(1)
+		go func(n int, extra bool) {
+			defer wg.Done()
+			fn(n)
+			if extra {
+				slots.Release()
+			}
+			finished <- extra
+		}(next, extra)
+		next++
+	}
+}
+
+func send(ctx context.Context, output chan<- string, line string) {
+	select {
+	case output <- line:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,220 @@
+// File: backend/internal/tools/batch_test.go
+package tools
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"backend/internal/validation"
+)
+
+func TestBatchPrepare(t *testing.T) {
+	p := validation.DefaultPolicy()
+	p.DenyTargets = []string{"10.0.0.0/8"}
+	v, err := validation.NewValidator(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	batch := NewBatch(3, 2, Ping{}, Dig{})
+
+	tests := []struct {
+		name    string
+		params  map[string]interface{}
+		argv    []string
+		wantErr string
+	}{
+		{
+			name: "List of targets",
+			params: map[string]interface{}{
+				"tool":       "ping",
+				"targets":    []interface{}{"192.0.2.1", "192.0.2.2"},
+				"parameters": map[string]interface{}{"count": float64(1)},
+			},
+			argv: []string{"batch", "ping -c 1 192.0.2.1", "ping -c 1 192.0.2.2"},
+		},
+		{
+			name: "Separated string with duplicates",
+			params: map[string]interface{}{
+				"tool":       "ping",
+				"targets":    "192.0.2.1, 192.0.2.2\n192.0.2.1,",
+				"parameters": map[string]interface{}{"count": float64(2)},
+			},
+			argv: []string{"batch", "ping -c 2 192.0.2.1", "ping -c 2 192.0.2.2"},
+		},
+		{
+			name:    "Unknown tool",
+			params:  map[string]interface{}{"tool": "traceroute", "targets": "192.0.2.1"},
+			wantErr: "batch tool must be one of dig, ping",
+		},
+		{
+			name:    "No targets",
+			params:  map[string]interface{}{"tool": "ping", "targets": " , "},
+			wantErr: "targets are required",
+		},
+		{
+			name:    "Too many targets",
+			params:  map[string]interface{}{"tool": "ping", "targets": "192.0.2.1,192.0.2.2,192.0.2.3,192.0.2.4"},
+			wantErr: "at most 3 targets are allowed",
+		},
+		{
+			name: "Denied target",
+			params: map[string]interface{}{
+				"tool":       "ping",
+				"targets":    []interface{}{"192.0.2.1", "10.1.2.3"},
+				"parameters": map[string]interface{}{"count": float64(1)},
+			},
+			wantErr: "10.1.2.3: ",
+		},
+		{
+			name: "Invalid domain",
+			params: map[string]interface{}{
+				"tool":       "dig",
+				"targets":    []interface{}{"example.com", "bad_domain!"},
+				"parameters": map[string]interface{}{"recordType": "A"},
+			},
+			wantErr: "bad_domain!: ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inv, err := batch.Prepare(v, tt.params)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Prepare() error = %v, want %q", err, tt.wantErr)
+				}
+				var valErr *validation.ValidationError
+				if !errors.As(err, &valErr) {
+					t.Errorf("Prepare() error = %T, want *validation.ValidationError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Prepare() error = %v", err)
+			}
+			if got := inv.Argv(); !reflect.DeepEqual(got, tt.argv) {
+				t.Errorf("Argv() = %q, want %q", got, tt.argv)
+			}
+		})
+	}
+}
+
+// stubInvocation returns a fixed result after writing one line of output
+type stubInvocation struct {
+	result interface{}
+	err    error
+}
+
+func (s stubInvocation) Argv() []string { return []string{"stub"} }
+
+func (s stubInvocation) Run(ctx context.Context, output chan<- string) (interface{}, error) {
+	output <- "running"
+	return s.result, s.err
+}
+
+func TestBatchRun(t *testing.T) {
+	inv := batchInvocation{
+		tool:    "ping",
+		targets: []string{"a", "b", "c"},
+		subs: []Invocation{
+			stubInvocation{result: &PingResult{Transmitted: 1, Received: 1, AvgMs: 10}},
+			stubInvocation{result: &PingResult{Transmitted: 1, Received: 1, AvgMs: 20}},
+			stubInvocation{result: &PingResult{Transmitted: 1}, err: errors.New("exit status 1")},
+		},
+		concurrency: 2,
+	}
+
+	output := make(chan string)
+	var lines []string
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for line := range output {
+			lines = append(lines, line)
+		}
+	}()
+	res, err := inv.Run(context.Background(), output)
+	close(output)
+	<-done
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	got := res.(*BatchResult)
+	if got.Succeeded != 2 || got.Failed != 1 || got.Reachable != 2 || got.Unreachable != 1 || got.AvgMs != 15 {
+		t.Errorf("Run() summary = %+v", got)
+	}
+	joined := strings.Join(lines, "\n")
+	for _, want := range []string{"[a] running", "[b] ok: reachable, avg 20.000 ms", "[c] failed: unreachable", "2 reachable, 1 unreachable, avg rtt 15.000 ms"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("output missing %q:\n%s", want, joined)
+		}
+	}
+}
+
+// countingSlots lends up to free slots and records how many are out
+type countingSlots struct {
+	mu        sync.Mutex
+	free, out int
+}
+
+func (s *countingSlots) TryAcquire() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.out == s.free {
+		return false
+	}
+	s.out++
+	return true
+}
+
+func (s *countingSlots) Release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.out--
+}
+
+func TestFanOut(t *testing.T) {
+	tests := []struct {
+		name        string
+		limit, free int
+		wantMax     int
+	}{
+		{"No free slots", 5, 0, 1},
+		{"Fewer free slots than the limit", 5, 2, 3},
+		{"Limit below free slots", 3, 10, 3},
+		{"Limit of one", 1, 10, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slots := &countingSlots{free: tt.free}
+			var mu sync.Mutex
+			running, peak, calls := 0, 0, 0
+			fanOut(WithSlots(context.Background(), slots), 20, tt.limit, func(int) {
+				mu.Lock()
+				running++
+				calls++
+				peak = max(peak, running)
+				mu.Unlock()
+				time.Sleep(2 * time.Millisecond)
+				mu.Lock()
+				running--
+				mu.Unlock()
+			})
+			if calls != 20 {
+				t.Errorf("made %d calls, want 20", calls)
+			}
+			if peak > tt.wantMax {
+				t.Errorf("ran %d at once, want at most %d", peak, tt.wantMax)
+			}
+			if slots.out != 0 {
+				t.Errorf("%d borrowed slots not returned", slots.out)
+			}
+		})
+	}
+}
@@ -2,11 +2,266 @@
 
 package tools
 
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"backend/internal/validation"
+)
+
 type DigParams struct {
-	Domain     string `json:"domain" validate:"required"`
-	RecordType string `json:"recordType" validate:"required,oneof=A AAAA MX NS TXT CNAME SOA PTR"`
-	Nameserver string `json:"nameserver"`
-	Parameters struct {
-		Short bool `json:"short"`
-	} `json:"parameters"`
+	Domain     string                 `json:"domain" validate:"required"`
+	RecordType string                 `json:"recordType" validate:"required,oneof=A AAAA MX NS TXT CNAME SOA PTR"`
+	Nameserver string                 `json:"nameserver"`
+	Parameters map[string]interface{} `json:"parameters"`
+	// NameserverAddress is the policy checked address queried in place of
+	// Nameserver, empty to let dig resolve Nameserver itself
+	NameserverAddress string `json:"-"`
+}
+
+// DigRecord is a single resource record from a dig response section
+type DigRecord struct {
+	Name  string `json:"name"`
+	TTL   int    `json:"ttl"`
+	Class string `json:"class"`
+	Type  string `json:"type"`
+	Data  string `json:"data"`
+}
+
+// DigResult is the structured form of dig output
+type DigResult struct {
+	Domain      string      `json:"domain"`
+	RecordType  string      `json:"recordType"`
+	Status      string      `json:"status,omitempty"`
+	Flags       []string    `json:"flags,omitempty"`
+	Answer      []DigRecord `json:"answer"`
+	Authority   []DigRecord `json:"authority,omitempty"`
+	Additional  []DigRecord `json:"additional,omitempty"`
+	Short       []string    `json:"short,omitempty"`
+	QueryTimeMs int         `json:"queryTimeMs,omitempty"`
+	Server      string      `json:"server,omitempty"`
+}
+
+var (
+	digStatusRegex    = regexp.MustCompile(`status: ([A-Z]+)`)
+	digFlagsRegex     = regexp.MustCompile(`^;; flags: ([^;]*);`)
+	digQueryTimeRegex = regexp.MustCompile(`^;; Query time: (\d+) msec`)
+	digServerRegex    = regexp.MustCompile(`^;; SERVER: ([^#(\s]+)`)
+)
+
+// Dig is the dig tool
+type Dig struct{}
+
+// Name implements Tool
+func (Dig) Name() string { return "dig" }
+
+// Check implements Checker
+func (Dig) Check(ctx context.Context) Status {
+	return checkBinary(ctx, "dig", "dig", "-v")
+}
+
+// Prepare implements Tool
+func (Dig) Prepare(v *validation.Validator, params map[string]interface{}) (Invocation, error) {
+	d, err := ParseDigParams(v, params)
+	if err != nil {
+		return nil, err
+	}
+	return digInvocation{params: *d}, nil
+}
+
+type digInvocation struct {
+	params DigParams
+}
+
+func (i digInvocation) Argv() []string {
+	return append([]string{"dig"}, DigArgs(i.params)...)
+}
+
+func (i digInvocation) Run(ctx context.Context, output chan<- string) (interface{}, error) {
+	result, err := ExecuteDig(ctx, i.params, output)
+	if result == nil {
+		return nil, err
+	}
+	return result, err
+}
+
+// ParseDigParams validates dig command parameters
+func ParseDigParams(v *validation.Validator, params map[string]interface{}) (*DigParams, error) {
+	var d DigParams
+
+	// Extract and validate domain
+	domainRaw, ok := params["domain"]
+	if !ok {
+		return nil, &validation.ValidationError{Field: "domain", Message: "domain is required"}
+	}
+	if domain, ok := domainRaw.(string); ok {
+		if err := validation.ValidateDomain(domain); err != nil {
+			return nil, err
+		}
+		d.Domain = domain
+	} else {
+		return nil, &validation.ValidationError{Field: "domain", Message: "invalid domain format"}
+	}
+
+	// Extract and validate record type
+	recordTypeRaw, ok := params["recordType"]
+	if !ok {
+		return nil, &validation.ValidationError{Field: "recordType", Message: "record type is required"}
+	}
+	if recordType, ok := recordTypeRaw.(string); ok {
+		if err := v.ValidateRecordType(recordType); err != nil {
+			return nil, err
+		}
+		d.RecordType = recordType
+	} else {
+		return nil, &validation.ValidationError{Field: "recordType", Message: "invalid record type format"}
+	}
+
+	// Extract and validate nameserver (optional)
+	if nameserverRaw, ok := params["nameserver"]; ok {
+		if nameserver, ok := nameserverRaw.(string); ok && nameserver != "" {
+			if err := validation.ValidateTarget(nameserver); err != nil {
+				return nil, &validation.ValidationError{Field: "nameserver", Message: "invalid nameserver format"}
+			}
+			address, err := v.ResolveTarget("nameserver", nameserver)
+			if err != nil {
+				return nil, err
+			}
+			d.Nameserver = nameserver
+			if address != nameserver {
+				d.NameserverAddress = address
+			}
+		}
+	}
+
+	// Extract and validate additional parameters
+	if paramsRaw, ok := params["parameters"]; ok {
+		if parameters, ok := paramsRaw.(map[string]interface{}); ok {
+			if err := v.ValidateDigParameters(parameters); err != nil {
+				return nil, err
+			}
+			d.Parameters = parameters
+		}
+	}
+
+	return &d, nil
+}
+
+// DigArgs builds the dig command line arguments
+func DigArgs(params DigParams) []string {
+	// Start with base arguments
+	args := []string{}
+
+	// Add nameserver if provided
+	if params.NameserverAddress != "" {
+		args = append(args, fmt.Sprintf("@%s", params.NameserverAddress))
+	} else if params.Nameserver != "" {
+		args = append(args, fmt.Sprintf("@%s", params.Nameserver))
+	}
+
+	// Add domain and record type
+	args = append(args, params.Domain, params.RecordType)
+
+	// Add enabled parameters in a stable order
+	keys := make([]string, 0, len(params.Parameters))
+	for key := range params.Parameters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if boolVal, ok := params.Parameters[key].(bool); ok && boolVal {
+			args = append(args, fmt.Sprintf("+%s", key))
+		}
+	}
+
+	return args
+}
+
+// ExecuteDig runs dig, streaming its output, and returns the parsed result
+func ExecuteDig(ctx context.Context, params DigParams, output chan<- string) (*DigResult, error) {
+	out, err := runCommand(ctx, "dig", DigArgs(params), output)
+	if out == "" && err != nil {
+		return nil, err
+	}
+	result := ParseDigOutput(out)
+	result.Domain = params.Domain
+	result.RecordType = strings.ToUpper(params.RecordType)
+	return result, err
+}
+
+// ParseDigOutput extracts the header and record sections from dig output.
+// Output produced with +short has no sections; its lines are returned in Short.
+func ParseDigOutput(out string) *DigResult {
+	result := &DigResult{Answer: []DigRecord{}}
+
+	var section *[]DigRecord
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			section = nil
+			continue
+		}
+
+		if strings.HasPrefix(line, ";") {
+			switch {
+			case strings.HasPrefix(line, ";; ANSWER SECTION:"):
+				section = &result.Answer
+			case strings.HasPrefix(line, ";; AUTHORITY SECTION:"):
+				section = &result.Authority
+			case strings.HasPrefix(line, ";; ADDITIONAL SECTION:"):
+				section = &result.Additional
+			case strings.HasPrefix(line, ";; ->>HEADER<<-"):
+				if m := digStatusRegex.FindStringSubmatch(line); m != nil {
+					result.Status = m[1]
+				}
+			default:
+				if m := digFlagsRegex.FindStringSubmatch(line); m != nil {
+					result.Flags = strings.Fields(m[1])
+				} else if m := digQueryTimeRegex.FindStringSubmatch(line); m != nil {
+					result.QueryTimeMs, _ = strconv.Atoi(m[1])
+				} else if m := digServerRegex.FindStringSubmatch(line); m != nil {
+					result.Server = m[1]
+				}
+			}
+			continue
+		}
+
+		if section != nil {
+			if rec, ok := parseDigRecord(line); ok {
+				*section = append(*section, rec)
+			}
+			continue
+		}
+
+		// Lines outside any section only appear in +short output
+		result.Short = append(result.Short, line)
+	}
+
+	return result
+}
+
+// parseDigRecord parses a presentation format resource record line
+func parseDigRecord(line string) (DigRecord, bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 4 {
+		return DigRecord{}, false
+	}
+	ttl, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return DigRecord{}, false
+	}
+	rec := DigRecord{
+		Name:  fields[0],
+		TTL:   ttl,
+		Class: fields[2],
+		Type:  fields[3],
+	}
+	if len(fields) > 4 {
+		rec.Data = strings.Join(fields[4:], " ")
+	}
+	return rec, true
 }
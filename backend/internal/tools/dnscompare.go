@@ -0,0 +1,332 @@
+// File: backend/internal/tools/dnscompare.go
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"backend/internal/validation"
+)
+
+// NameserverPreset is a configured nameserver clients can compare by name
+type NameserverPreset struct {
+	Name    string
+	Address string
+}
+
+// DNSCompareServer is one nameserver's answer in a comparison
+type DNSCompareServer struct {
+	Server string `json:"server"`
+	// Preset is the preset's name, empty for servers the client supplied
+	Preset string `json:"preset,omitempty"`
+	Status string `json:"status,omitempty"`
+	// Answer is the sorted data of the answer records
+	Answer      []string `json:"answer"`
+	QueryTimeMs int      `json:"queryTimeMs,omitempty"`
+	// Error is set when the server gave no response
+	Error string `json:"error,omitempty"`
+	// Mismatch is set when the answer differs from the most common one
+	Mismatch bool `json:"mismatch"`
+}
+
+// label names the server in output, with its preset when it has one
+func (s DNSCompareServer) label() string {
+	if s.Preset != "" {
+		return s.Preset + " (" + s.Server + ")"
+	}
+	return s.Server
+}
+
+// answerKey identifies the answer regardless of record order and TTLs
+func (s DNSCompareServer) answerKey() string {
+	if s.Error != "" || s.Status == "" {
+		return failedStatus
+	}
+	return s.Status + " " + strings.Join(s.Answer, ", ")
+}
+
+// summary describes the answer in a few words
+func (s DNSCompareServer) summary() string {
+	switch {
+	case s.Error != "":
+		return s.Error
+	case len(s.Answer) == 0:
+		return "no records"
+	default:
+		return strings.Join(s.Answer, ", ")
+	}
+}
+
+// DNSCompareResult is the outcome of a dns-compare job
+type DNSCompareResult struct {
+	Domain     string             `json:"domain"`
+	RecordType string             `json:"recordType"`
+	Servers    []DNSCompareServer `json:"servers"`
+	// DistinctAnswers counts the different answers given; 1 means every
+	// server agreed
+	DistinctAnswers int  `json:"distinctAnswers"`
+	Consistent      bool `json:"consistent"`
+}
+
+// DNSCompare queries one name and record type against several nameservers
+// at once, e.g. to follow the propagation of a change. Servers are
+// configured presets and nameservers the client supplies; each is validated
+// by the dig tool like a dig job's nameserver.
+type DNSCompare struct {
+	presets    []NameserverPreset
+	maxServers int
+}
+
+// NewDNSCompare creates a dns-compare tool offering presets and comparing
+// at most maxServers servers per job
+func NewDNSCompare(presets []NameserverPreset, maxServers int) DNSCompare {
+	return DNSCompare{presets: presets, maxServers: maxServers}
+}
+
+// Name implements Tool
+func (DNSCompare) Name() string { return "dns-compare" }
+
+// Check implements Checker. Comparisons run dig, so they need what dig
+// needs.
+func (DNSCompare) Check(ctx context.Context) Status {
+	s := Dig{}.Check(ctx)
+	s.Name = "dns-compare"
+	return s
+}
+
+// Prepare implements Tool. params holds the domain and record type, the
+// names of the presets to query, all of them when omitted, and further
+// nameservers as a list or a comma or newline separated string.
+func (c DNSCompare) Prepare(v *validation.Validator, params map[string]interface{}) (Invocation, error) {
+	if err := v.CheckTool("dig"); err != nil {
+		return nil, err
+	}
+	query := make(map[string]interface{}, 2)
+	for _, key := range []string{"domain", "recordType"} {
+		if val, ok := params[key]; ok {
+			query[key] = val
+		}
+	}
+	d, err := ParseDigParams(v, query)
+	if err != nil {
+		return nil, err
+	}
+	presets, err := c.selectPresets(params["presets"])
+	if err != nil {
+		return nil, err
+	}
+	servers := make([]DNSCompareServer, 0, len(presets))
+	seen := make(map[string]bool)
+	for _, p := range presets {
+		if !seen[strings.ToLower(p.Address)] {
+			seen[strings.ToLower(p.Address)] = true
+			servers = append(servers, DNSCompareServer{Server: p.Address, Preset: p.Name})
+		}
+	}
+	if raw, ok := params["nameservers"]; ok {
+		nameservers, err := listParam("nameservers", raw)
+		if err != nil {
+			return nil, err
+		}
+		for _, ns := range nameservers {
+			if !seen[strings.ToLower(ns)] {
+				seen[strings.ToLower(ns)] = true
+				servers = append(servers, DNSCompareServer{Server: ns})
+			}
+		}
+	}
+	if len(servers) == 0 {
+		return nil, &validation.ValidationError{Field: "nameservers", Message: "at least one preset or nameserver is required"}
+	}
+	if len(servers) > c.maxServers {
+		return nil, &validation.ValidationError{Field: "nameservers", Message: fmt.Sprintf("at most %d nameservers are allowed", c.maxServers)}
+	}
+
+	inv := dnsCompareInvocation{domain: d.Domain, recordType: strings.ToUpper(d.RecordType), servers: servers}
+	for _, s := range servers {
+		p := map[string]interface{}{"domain": d.Domain, "recordType": d.RecordType, "nameserver": s.Server}
+		sub, err := Dig{}.Prepare(v, p)
+		if err != nil {
+			var valErr *validation.ValidationError
+			if errors.As(err, &valErr) && valErr.Field == "nameserver" {
+				field := "nameservers"
+				if s.Preset != "" {
+					field = "presets"
+				}
+				return nil, &validation.ValidationError{Field: field, Message: fmt.Sprintf("%s: %s", s.label(), valErr.Message)}
+			}
+			return nil, err
+		}
+		inv.subs = append(inv.subs, sub)
+	}
+	return inv, nil
+}
+
+// selectPresets returns the presets named in raw, or every preset when raw
+// is nil
+func (c DNSCompare) selectPresets(raw interface{}) ([]NameserverPreset, error) {
+	if raw == nil {
+		return c.presets, nil
+	}
+	var names []string
+	switch v := raw.(type) {
+	case []interface{}:
+		for _, item := range v {
+			name, ok := item.(string)
+			if !ok {
+				return nil, &validation.ValidationError{Field: "presets", Message: "presets must be strings"}
+			}
+			names = append(names, name)
+		}
+	case string:
+		names = strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == '\n' || r == '\r' })
+	default:
+		return nil, &validation.ValidationError{Field: "presets", Message: "invalid presets format"}
+	}
+
+	var selected []NameserverPreset
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		p, ok := c.preset(name)
+		if !ok {
+			return nil, &validation.ValidationError{Field: "presets", Message: fmt.Sprintf("unknown preset %q, expected one of %s", name, strings.Join(c.presetNames(), ", "))}
+		}
+		selected = append(selected, p)
+	}
+	return selected, nil
+}
+
+func (c DNSCompare) preset(name string) (NameserverPreset, bool) {
+	for _, p := range c.presets {
+		if strings.EqualFold(p.Name, name) {
+			return p, true
+		}
+	}
+	return NameserverPreset{}, false
+}
+
+func (c DNSCompare) presetNames() []string {
+	names := make([]string, 0, len(c.presets))
+	for _, p := range c.presets {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+type dnsCompareInvocation struct {
+	domain     string
+	recordType string
+	servers    []DNSCompareServer
+	subs       []Invocation
+}
+
+// Argv lists every dig command the comparison runs
+func (i dnsCompareInvocation) Argv() []string {
+	argv := []string{"dns-compare"}
+	for _, sub := range i.subs {
+		argv = append(argv, strings.Join(sub.Argv(), " "))
+	}
+	return argv
+}
+
+// Run queries the servers concurrently on free job slots, reporting a line per
+// server as it answers, then a grid of all answers with mismatches marked.
+// Servers that fail do not fail the job; only cancellation does.
+func (i dnsCompareInvocation) Run(ctx context.Context, output chan<- string) (interface{}, error) {
+	result := &DNSCompareResult{Domain: i.domain, RecordType: i.recordType, Servers: make([]DNSCompareServer, len(i.servers))}
+	fanOut(ctx, len(i.subs), len(i.subs), func(n int) {
+		s := i.servers[n]
+		r, err := runDig(ctx, i.subs[n])
+		s.Answer = []string{}
+		if r != nil {
+			s.Status, s.QueryTimeMs = r.Status, r.QueryTimeMs
+			for _, rec := range r.Answer {
+				s.Answer = append(s.Answer, rec.Data)
+			}
+			sort.Strings(s.Answer)
+		}
+		switch {
+		case s.Status != "":
+		case err != nil:
+			s.Error = err.Error()
+		default:
+			s.Error = "no response"
+		}
+		result.Servers[n] = s
+		send(ctx, output, fmt.Sprintf("[%s] %s: %s", s.label(), compareStatus(s), s.summary()))
+	})
+	if err := ctx.Err(); err != nil {
+		return result, err
+	}
+
+	markMismatches(result)
+	for _, line := range compareTable(result) {
+		send(ctx, output, line)
+	}
+	return result, nil
+}
+
+// markMismatches flags the servers whose answer differs from the most
+// common one. Ties go to the answer of the earliest server.
+func markMismatches(result *DNSCompareResult) {
+	counts := make(map[string]int)
+	var order []string
+	for _, s := range result.Servers {
+		key := s.answerKey()
+		if counts[key] == 0 {
+			order = append(order, key)
+		}
+		counts[key]++
+	}
+	majority := ""
+	for _, key := range order {
+		if counts[key] > counts[majority] {
+			majority = key
+		}
+	}
+	for n := range result.Servers {
+		result.Servers[n].Mismatch = result.Servers[n].answerKey() != majority
+	}
+	result.DistinctAnswers = len(order)
+	result.Consistent = len(order) == 1
+}
+
+func compareStatus(s DNSCompareServer) string {
+	if s.Error != "" || s.Status == "" {
+		return failedStatus
+	}
+	return s.Status
+}
+
+// compareTable renders the grid printed at the end of a comparison
+func compareTable(result *DNSCompareResult) []string {
+	var b strings.Builder
+	tw := tabwriter.NewWriter(&b, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, " \tSERVER\tSTATUS\tTIME\tANSWER")
+	for _, s := range result.Servers {
+		marker := " "
+		if s.Mismatch {
+			marker = "*"
+		}
+		latency := "-"
+		if compareStatus(s) != failedStatus {
+			latency = fmt.Sprintf("%d ms", s.QueryTimeMs)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", marker, s.label(), compareStatus(s), latency, s.summary())
+	}
+	tw.Flush()
+
+	lines := []string{"", fmt.Sprintf("--- dns-compare %s %s: %d servers ---", result.Domain, result.RecordType, len(result.Servers))}
+	lines = append(lines, strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")...)
+	if result.Consistent {
+		return append(lines, "all servers agree")
+	}
+	return append(lines, fmt.Sprintf("%d different answers, * marks servers that differ from the most common one", result.DistinctAnswers))
+}
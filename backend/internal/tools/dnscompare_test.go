@@ -0,0 +1,87 @@
+// File: backend/internal/tools/dnscompare_test.go
+package tools
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"backend/internal/validation"
+)
+
+func TestDNSComparePrepare(t *testing.T) {
+	p := validation.DefaultPolicy()
+	p.DenyTargets = []string{"10.0.0.0/8"}
+	v, err := validation.NewValidator(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compare := NewDNSCompare([]NameserverPreset{
+		{Name: "google", Address: "8.8.8.8"},
+		{Name: "cloudflare", Address: "1.1.1.1"},
+	}, 3)
+
+	tests := []struct {
+		name    string
+		params  map[string]interface{}
+		argv    []string
+		wantErr string
+	}{
+		{"All presets", map[string]interface{}{},
+			[]string{"dns-compare", "dig @8.8.8.8 example.com A", "dig @1.1.1.1 example.com A"}, ""},
+		{"Selected preset and own servers", map[string]interface{}{"presets": []interface{}{"Cloudflare"}, "nameservers": "192.0.2.54, 1.1.1.1, 192.0.2.53"},
+			[]string{"dns-compare", "dig @1.1.1.1 example.com A", "dig @192.0.2.54 example.com A", "dig @192.0.2.53 example.com A"}, ""},
+		{"Own servers only", map[string]interface{}{"presets": []interface{}{}, "nameservers": []interface{}{"192.0.2.53"}},
+			[]string{"dns-compare", "dig @192.0.2.53 example.com A"}, ""},
+		{"No servers", map[string]interface{}{"presets": ""}, nil, "at least one preset or nameserver"},
+		{"Unknown preset", map[string]interface{}{"presets": "opendns"}, nil, `unknown preset "opendns"`},
+		{"Too many", map[string]interface{}{"nameservers": "192.0.2.1, 192.0.2.2"}, nil, "at most 3 nameservers"},
+		{"Invalid nameserver", map[string]interface{}{"nameservers": "-x"}, nil, "-x: invalid nameserver format"},
+		{"Denied nameserver", map[string]interface{}{"nameservers": "10.0.0.53"}, nil, "not permitted"},
+		{"Unresolvable nameserver", map[string]interface{}{"nameservers": "ns.example.invalid"}, nil, "could not be resolved"},
+		{"Bad record type", map[string]interface{}{"recordType": "ANY"}, nil, "invalid DNS record type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := map[string]interface{}{"domain": "example.com", "recordType": "A"}
+			for k, val := range tt.params {
+				params[k] = val
+			}
+			inv, err := compare.Prepare(v, params)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Prepare() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Prepare() error = %v", err)
+			}
+			if got := inv.Argv(); !reflect.DeepEqual(got, tt.argv) {
+				t.Errorf("Argv() = %q, want %q", got, tt.argv)
+			}
+		})
+	}
+}
+
+func TestMarkMismatches(t *testing.T) {
+	result := &DNSCompareResult{Servers: []DNSCompareServer{
+		{Server: "a", Status: "NOERROR", Answer: []string{"192.0.2.1"}},
+		{Server: "b", Status: "NOERROR", Answer: []string{"192.0.2.2"}},
+		{Server: "c", Status: "NOERROR", Answer: []string{"192.0.2.2"}},
+		{Server: "d", Error: "connection timed out"},
+	}}
+	markMismatches(result)
+
+	var got []bool
+	for _, s := range result.Servers {
+		got = append(got, s.Mismatch)
+	}
+	if want := []bool{true, false, false, true}; !reflect.DeepEqual(got, want) {
+		t.Errorf("mismatches = %v, want %v", got, want)
+	}
+	if result.DistinctAnswers != 3 || result.Consistent {
+		t.Errorf("distinct answers = %d, consistent = %v, want 3, false", result.DistinctAnswers, result.Consistent)
+	}
+}
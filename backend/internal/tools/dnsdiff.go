@@ -0,0 +1,525 @@
+// File: backend/internal/tools/dnsdiff.go
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"backend/internal/validation"
+)
+
+// Kinds of DNS changes
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeTTL     = "ttl"
+	ChangeSerial  = "serial"
+	ChangeStatus  = "status"
+)
+
+// failedStatus stands in for the response status of a query that got no
+// response
+const failedStatus = "FAILED"
+
+// DNSQuery is a name and record type to look up
+type DNSQuery struct {
+	Domain     string `json:"domain"`
+	RecordType string `json:"recordType"`
+}
+
+func (q DNSQuery) String() string {
+	return q.Domain + " " + q.RecordType
+}
+
+// key identifies the query regardless of case and a trailing dot
+func (q DNSQuery) key() string {
+	return strings.ToLower(strings.TrimSuffix(q.Domain, ".")) + " " + strings.ToUpper(q.RecordType)
+}
+
+// DNSAnswer is the response to one query
+type DNSAnswer struct {
+	DNSQuery
+	Status  string      `json:"status,omitempty"`
+	Records []DigRecord `json:"records"`
+	// Error is set when the query got no response
+	Error string `json:"error,omitempty"`
+}
+
+// status returns the answer's response status, or FAILED without a response
+func (a DNSAnswer) status() string {
+	if a.Error != "" || a.Status == "" {
+		return failedStatus
+	}
+	return a.Status
+}
+
+// DNSChange is one difference between a baseline answer and a current one
+type DNSChange struct {
+	Kind string `json:"kind"`
+	DNSQuery
+	// Name, Type and Data identify the changed record, empty for status
+	// changes
+	Name string `json:"name,omitempty"`
+	Type string `json:"type,omitempty"`
+	Data string `json:"data,omitempty"`
+	// Old and New are the changed TTL, serial or status
+	Old string `json:"old,omitempty"`
+	New string `json:"new,omitempty"`
+}
+
+func (c DNSChange) String() string {
+	switch c.Kind {
+	case ChangeAdded:
+		return fmt.Sprintf("+ %s %s %s", c.Name, c.Type, c.Data)
+	case ChangeRemoved:
+		return fmt.Sprintf("- %s %s %s", c.Name, c.Type, c.Data)
+	case ChangeTTL:
+		return fmt.Sprintf("~ %s %s %s: ttl %s -> %s", c.Name, c.Type, c.Data, c.Old, c.New)
+	case ChangeSerial:
+		return fmt.Sprintf("~ %s SOA: serial %s -> %s", c.Name, c.Old, c.New)
+	default:
+		return fmt.Sprintf("~ %s: status %s -> %s", c.DNSQuery, c.Old, c.New)
+	}
+}
+
+// DNSDiff is the difference between two sets of answers
+type DNSDiff struct {
+	Changes []DNSChange `json:"changes"`
+	Added   int         `json:"added"`
+	Removed int         `json:"removed"`
+	// Changed counts TTL, serial and status changes
+	Changed int `json:"changed"`
+}
+
+// Summary describes the diff in one line
+func (d *DNSDiff) Summary() string {
+	if len(d.Changes) == 0 {
+		return "no changes"
+	}
+	return fmt.Sprintf("%d added, %d removed, %d changed", d.Added, d.Removed, d.Changed)
+}
+
+// DiffDNS compares current answers against base. Records are matched by
+// name, type and data, so a changed address shows as one record removed and
+// one added. SOA records are matched by name and report serial changes.
+// Queries that failed to get a response only report their status, not every
+// record as removed. Baseline queries missing from current are ignored, so a
+// single dig job can be diffed against a larger baseline.
+func DiffDNS(base, current []DNSAnswer) *DNSDiff {
+	d := &DNSDiff{Changes: []DNSChange{}}
+	baseByKey := make(map[string]DNSAnswer, len(base))
+	for _, a := range base {
+		baseByKey[a.key()] = a
+	}
+	for _, cur := range current {
+		old, ok := baseByKey[cur.key()]
+		if !ok {
+			old = DNSAnswer{DNSQuery: cur.DNSQuery, Status: cur.Status}
+		}
+		d.compare(old, cur)
+	}
+	for _, c := range d.Changes {
+		switch c.Kind {
+		case ChangeAdded:
+			d.Added++
+		case ChangeRemoved:
+			d.Removed++
+		default:
+			d.Changed++
+		}
+	}
+	return d
+}
+
+// compare appends the differences between two answers to one query
+func (d *DNSDiff) compare(old, cur DNSAnswer) {
+	if old.status() != cur.status() {
+		d.Changes = append(d.Changes, DNSChange{Kind: ChangeStatus, DNSQuery: cur.DNSQuery, Old: old.status(), New: cur.status()})
+	}
+	if old.status() == failedStatus || cur.status() == failedStatus {
+		return
+	}
+
+	oldRecords := recordsByKey(old.Records)
+	curRecords := recordsByKey(cur.Records)
+	for _, rec := range cur.Records {
+		prev, ok := oldRecords[recordKey(rec)]
+		change := DNSChange{DNSQuery: cur.DNSQuery, Name: rec.Name, Type: rec.Type, Data: rec.Data}
+		switch {
+		case !ok:
+			change.Kind = ChangeAdded
+		case rec.Type == "SOA" && soaSerial(prev.Data) != soaSerial(rec.Data):
+			change.Kind, change.Data = ChangeSerial, ""
+			change.Old, change.New = soaSerial(prev.Data), soaSerial(rec.Data)
+		case prev.TTL != rec.TTL:
+			change.Kind = ChangeTTL
+			change.Old, change.New = fmt.Sprint(prev.TTL), fmt.Sprint(rec.TTL)
+		default:
+			continue
+		}
+		d.Changes = append(d.Changes, change)
+	}
+	for _, rec := range old.Records {
+		if _, ok := curRecords[recordKey(rec)]; !ok {
+			d.Changes = append(d.Changes, DNSChange{Kind: ChangeRemoved, DNSQuery: cur.DNSQuery, Name: rec.Name, Type: rec.Type, Data: rec.Data})
+		}
+	}
+}
+
+func recordsByKey(records []DigRecord) map[string]DigRecord {
+	m := make(map[string]DigRecord, len(records))
+	for _, rec := range records {
+		m[recordKey(rec)] = rec
+	}
+	return m
+}
+
+// recordKey identifies a record regardless of its TTL. SOA records are
+// identified without their serial so that a new serial is a change rather
+// than a replacement.
+func recordKey(rec DigRecord) string {
+	name := strings.ToLower(rec.Name)
+	data := rec.Data
+	if rec.Type == "SOA" {
+		if fields := strings.Fields(data); len(fields) == 7 {
+			fields[2] = ""
+			data = strings.Join(fields, " ")
+		}
+	} else if rec.Type == "CNAME" || rec.Type == "NS" || rec.Type == "MX" || rec.Type == "PTR" {
+		data = strings.ToLower(data)
+	}
+	return name + " " + rec.Type + " " + data
+}
+
+// soaSerial returns the serial field of SOA record data
+func soaSerial(data string) string {
+	fields := strings.Fields(data)
+	if len(fields) != 7 {
+		return ""
+	}
+	return fields[2]
+}
+
+// DNSAnswers extracts the answers from the result of a dig, dig batch or
+// dns-diff job. The result may be the tool's own type or its JSON decoded
+// form, as read from the job history.
+func DNSAnswers(tool string, result interface{}) ([]DNSAnswer, error) {
+	if result == nil {
+		return nil, errors.New("job has no result")
+	}
+	switch tool {
+	case "dig":
+		var r DigResult
+		if err := convertResult(result, &r); err != nil {
+			return nil, err
+		}
+		return []DNSAnswer{digAnswer(DNSQuery{Domain: r.Domain, RecordType: r.RecordType}, &r, nil)}, nil
+	case "batch":
+		var r BatchResult
+		if err := convertResult(result, &r); err != nil {
+			return nil, err
+		}
+		if r.Tool != "dig" {
+			return nil, fmt.Errorf("batch of %s has no DNS answers", r.Tool)
+		}
+		answers := make([]DNSAnswer, 0, len(r.Targets))
+		for _, t := range r.Targets {
+			var dr DigResult
+			if t.Result == nil || convertResult(t.Result, &dr) != nil {
+				continue
+			}
+			answers = append(answers, digAnswer(DNSQuery{Domain: dr.Domain, RecordType: dr.RecordType}, &dr, nil))
+		}
+		return answers, nil
+	case "dns-diff":
+		var r DNSDiffResult
+		if err := convertResult(result, &r); err != nil {
+			return nil, err
+		}
+		return r.Answers, nil
+	default:
+		return nil, fmt.Errorf("%s jobs have no DNS answers", tool)
+	}
+}
+
+func convertResult(result interface{}, dst interface{}) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dst)
+}
+
+// digAnswer converts a dig result into an answer. runErr is the error the
+// query failed with, if any.
+func digAnswer(q DNSQuery, r *DigResult, runErr error) DNSAnswer {
+	a := DNSAnswer{DNSQuery: q, Records: []DigRecord{}}
+	if r != nil {
+		a.Status = r.Status
+		a.Records = append(a.Records, r.Answer...)
+	}
+	if runErr != nil && a.Status == "" {
+		a.Error = runErr.Error()
+	}
+	return a
+}
+
+// DNSBaseline is a named set of answers that dns-diff jobs compare against
+type DNSBaseline struct {
+	Name string `json:"name"`
+	// Nameserver is the server the answers came from, empty for the
+	// system resolver
+	Nameserver string      `json:"nameserver,omitempty"`
+	Answers    []DNSAnswer `json:"answers"`
+}
+
+// BaselineLookup returns the baseline with the given name
+type BaselineLookup func(name string) (*DNSBaseline, error)
+
+// DNSDiffResult is the outcome of a dns-diff job
+type DNSDiffResult struct {
+	// Baseline is the baseline compared against, empty for a snapshot
+	Baseline   string      `json:"baseline,omitempty"`
+	Nameserver string      `json:"nameserver,omitempty"`
+	Answers    []DNSAnswer `json:"answers"`
+	// Diff is nil for a snapshot
+	Diff *DNSDiff `json:"diff,omitempty"`
+}
+
+// DNSDiffTool queries a list of names and record types, either to snapshot them
+// as a future baseline or to compare them against a stored baseline. Each
+// query is validated by the dig tool, so the same policy and role checks
+// apply as for a single dig job.
+type DNSDiffTool struct {
+	baselines   BaselineLookup
+	maxQueries  int
+	concurrency int
+}
+
+// NewDNSDiff creates a dns-diff tool reading baselines from lookup, which
+// may be nil when baselines are unavailable, and accepting up to maxQueries
+// queries of which concurrency run at once
+func NewDNSDiff(lookup BaselineLookup, maxQueries, concurrency int) DNSDiffTool {
+	return DNSDiffTool{baselines: lookup, maxQueries: maxQueries, concurrency: concurrency}
+}
+
+// Name implements Tool
+func (DNSDiffTool) Name() string { return "dns-diff" }
+
+// Check implements Checker
+func (DNSDiffTool) Check(ctx context.Context) Status {
+	s := Dig{}.Check(ctx)
+	s.Name = "dns-diff"
+	return s
+}
+
+// Prepare implements Tool. params holds either queries, as a list of
+// {domain, recordType} objects or "name TYPE" strings, or the name of a
+// baseline whose queries are repeated, and optionally a nameserver, which
+// defaults to the baseline's.
+func (t DNSDiffTool) Prepare(v *validation.Validator, params map[string]interface{}) (Invocation, error) {
+	if err := v.CheckTool("dig"); err != nil {
+		return nil, err
+	}
+	inv := dnsDiffInvocation{concurrency: t.concurrency}
+	inv.nameserver, _ = params["nameserver"].(string)
+
+	var queries []DNSQuery
+	if name, _ := params["baseline"].(string); name != "" {
+		if _, ok := params["queries"]; ok {
+			return nil, &validation.ValidationError{Field: "queries", Message: "queries are taken from the baseline"}
+		}
+		if t.baselines == nil {
+			return nil, &validation.ValidationError{Field: "baseline", Message: "baselines require the job history"}
+		}
+		baseline, err := t.baselines(name)
+		if err != nil {
+			return nil, &validation.ValidationError{Field: "baseline", Message: fmt.Sprintf("baseline %q does not exist", name)}
+		}
+		inv.baseline = baseline
+		if inv.nameserver == "" {
+			inv.nameserver = baseline.Nameserver
+		}
+		for _, a := range baseline.Answers {
+			queries = append(queries, a.DNSQuery)
+		}
+	} else {
+		var err error
+		if queries, err = dnsQueries(params["queries"]); err != nil {
+			return nil, err
+		}
+	}
+	if len(queries) > t.maxQueries {
+		return nil, &validation.ValidationError{Field: "queries", Message: fmt.Sprintf("at most %d queries are allowed", t.maxQueries)}
+	}
+
+	for _, q := range queries {
+		p := map[string]interface{}{"domain": q.Domain, "recordType": q.RecordType}
+		if inv.nameserver != "" {
+			p["nameserver"] = inv.nameserver
+		}
+		sub, err := Dig{}.Prepare(v, p)
+		if err != nil {
+			var valErr *validation.ValidationError
+			if errors.As(err, &valErr) {
+				field := "queries"
+				if valErr.Field == "nameserver" {
+					field = "nameserver"
+				}
+				return nil, &validation.ValidationError{Field: field, Message: fmt.Sprintf("%s: %s", q, valErr.Message)}
+			}
+			return nil, err
+		}
+		inv.queries = append(inv.queries, q)
+		inv.subs = append(inv.subs, sub)
+	}
+	return inv, nil
+}
+
+// dnsQueries reads the queries parameter, dropping duplicates
+func dnsQueries(raw interface{}) ([]DNSQuery, error) {
+	var items []interface{}
+	switch v := raw.(type) {
+	case []interface{}:
+		items = v
+	case string:
+		for _, line := range strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == '\n' || r == '\r' }) {
+			items = append(items, line)
+		}
+	case nil:
+		return nil, &validation.ValidationError{Field: "queries", Message: "queries or a baseline are required"}
+	default:
+		return nil, &validation.ValidationError{Field: "queries", Message: "invalid queries format"}
+	}
+
+	seen := make(map[string]bool)
+	var queries []DNSQuery
+	for _, item := range items {
+		var q DNSQuery
+		switch v := item.(type) {
+		case string:
+			fields := strings.Fields(v)
+			if len(fields) == 0 {
+				continue
+			}
+			if len(fields) != 2 {
+				return nil, &validation.ValidationError{Field: "queries", Message: fmt.Sprintf("query %q must be a name and a record type", v)}
+			}
+			q = DNSQuery{Domain: fields[0], RecordType: strings.ToUpper(fields[1])}
+		case map[string]interface{}:
+			q.Domain, _ = v["domain"].(string)
+			recordType, _ := v["recordType"].(string)
+			q.RecordType = strings.ToUpper(recordType)
+		default:
+			return nil, &validation.ValidationError{Field: "queries", Message: "invalid queries format"}
+		}
+		if seen[q.key()] {
+			continue
+		}
+		seen[q.key()] = true
+		queries = append(queries, q)
+	}
+	if len(queries) == 0 {
+		return nil, &validation.ValidationError{Field: "queries", Message: "queries or a baseline are required"}
+	}
+	return queries, nil
+}
+
+type dnsDiffInvocation struct {
+	// baseline is nil for a snapshot
+	baseline    *DNSBaseline
+	nameserver  string
+	queries     []DNSQuery
+	subs        []Invocation
+	concurrency int
+}
+
+// Argv lists the baseline and every dig command the job runs
+func (i dnsDiffInvocation) Argv() []string {
+	argv := []string{"dns-diff"}
+	if i.baseline != nil {
+		argv = append(argv, "-baseline", i.baseline.Name)
+	}
+	for _, sub := range i.subs {
+		argv = append(argv, strings.Join(sub.Argv(), " "))
+	}
+	return argv
+}
+
+// Run executes the queries concurrently, borrowing free job slots for all but
+// the first, reporting a line per answer as it arrives, then the changes
+// against the baseline. Failed queries do not fail the job; only
+// cancellation does.
+func (i dnsDiffInvocation) Run(ctx context.Context, output chan<- string) (interface{}, error) {
+	result := &DNSDiffResult{Nameserver: i.nameserver, Answers: make([]DNSAnswer, len(i.queries))}
+	for n, q := range i.queries {
+		result.Answers[n] = DNSAnswer{DNSQuery: q, Records: []DigRecord{}, Error: "cancelled"}
+	}
+
+	fanOut(ctx, len(i.subs), i.concurrency, func(n int) {
+		answer := runQuery(ctx, i.queries[n], i.subs[n])
+		result.Answers[n] = answer
+		send(ctx, output, answerLine(answer))
+	})
+	if err := ctx.Err(); err != nil {
+		return result, err
+	}
+
+	if i.baseline == nil {
+		send(ctx, output, "")
+		send(ctx, output, fmt.Sprintf("--- dns snapshot: %d queries ---", len(result.Answers)))
+		return result, nil
+	}
+	result.Baseline = i.baseline.Name
+	result.Diff = DiffDNS(i.baseline.Answers, result.Answers)
+	send(ctx, output, "")
+	send(ctx, output, fmt.Sprintf("--- dns-diff against baseline %s: %s ---", i.baseline.Name, result.Diff.Summary()))
+	for _, c := range result.Diff.Changes {
+		send(ctx, output, c.String())
+	}
+	return result, nil
+}
+
+// runQuery runs one dig query, discarding its raw output
+func runQuery(ctx context.Context, q DNSQuery, sub Invocation) DNSAnswer {
+	r, err := runDig(ctx, sub)
+	return digAnswer(q, r, err)
+}
+
+// runDig runs a dig invocation, discarding its raw output. The result is
+// nil when dig produced no output.
+func runDig(ctx context.Context, sub Invocation) (*DigResult, error) {
+	lines := make(chan string)
+	go func() {
+		for range lines {
+		}
+	}()
+	res, err := sub.Run(ctx, lines)
+	close(lines)
+	r, _ := res.(*DigResult)
+	return r, err
+}
+
+// answerLine describes an answer in one line
+func answerLine(a DNSAnswer) string {
+	if a.status() == failedStatus {
+		msg := a.Error
+		if msg == "" {
+			msg = "no response"
+		}
+		return fmt.Sprintf("[%s] %s: %s", a.DNSQuery, failedStatus, msg)
+	}
+	data := make([]string, 0, len(a.Records))
+	for _, rec := range a.Records {
+		data = append(data, rec.Data)
+	}
+	sort.Strings(data)
+	if len(data) == 0 {
+		return fmt.Sprintf("[%s] %s: no records", a.DNSQuery, a.Status)
+	}
+	return fmt.Sprintf("[%s] %s: %s", a.DNSQuery, a.Status, strings.Join(data, ", "))
+}
@@ -0,0 +1,143 @@
+// File: backend/internal/tools/dnsdiff_test.go
+package tools
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"backend/internal/validation"
+)
+
+func TestDiffDNS(t *testing.T) {
+	a := func(name, recordType, status string, records ...DigRecord) DNSAnswer {
+		return DNSAnswer{DNSQuery: DNSQuery{Domain: name, RecordType: recordType}, Status: status, Records: records}
+	}
+	rec := func(ttl int, typ, data string) DigRecord {
+		return DigRecord{Name: "example.com.", TTL: ttl, Class: "IN", Type: typ, Data: data}
+	}
+	soa := func(serial string) DigRecord {
+		return rec(3600, "SOA", "ns1.example.com. hostmaster.example.com. "+serial+" 7200 3600 1209600 3600")
+	}
+
+	tests := []struct {
+		name    string
+		base    []DNSAnswer
+		current []DNSAnswer
+		want    []string
+	}{
+		{
+			name:    "Unchanged in another order",
+			base:    []DNSAnswer{a("example.com", "A", "NOERROR", rec(300, "A", "192.0.2.1"), rec(300, "A", "192.0.2.2"))},
+			current: []DNSAnswer{a("Example.com.", "A", "NOERROR", rec(300, "A", "192.0.2.2"), rec(300, "A", "192.0.2.1"))},
+		},
+		{
+			name:    "Added, removed and TTL",
+			base:    []DNSAnswer{a("example.com", "A", "NOERROR", rec(300, "A", "192.0.2.1"), rec(300, "A", "192.0.2.2"))},
+			current: []DNSAnswer{a("example.com", "A", "NOERROR", rec(60, "A", "192.0.2.1"), rec(60, "A", "192.0.2.3"))},
+			want: []string{
+				"~ example.com. A 192.0.2.1: ttl 300 -> 60",
+				"+ example.com. A 192.0.2.3",
+				"- example.com. A 192.0.2.2",
+			},
+		},
+		{
+			name:    "SOA serial",
+			base:    []DNSAnswer{a("example.com", "SOA", "NOERROR", soa("2024010101"))},
+			current: []DNSAnswer{a("example.com", "SOA", "NOERROR", soa("2024010102"))},
+			want:    []string{"~ example.com. SOA: serial 2024010101 -> 2024010102"},
+		},
+		{
+			name:    "Status",
+			base:    []DNSAnswer{a("example.com", "MX", "NOERROR", rec(300, "MX", "10 mx.example.com."))},
+			current: []DNSAnswer{a("example.com", "MX", "NXDOMAIN")},
+			want:    []string{"~ example.com MX: status NOERROR -> NXDOMAIN", "- example.com. MX 10 mx.example.com."},
+		},
+		{
+			name:    "Failed query",
+			base:    []DNSAnswer{a("example.com", "A", "NOERROR", rec(300, "A", "192.0.2.1"))},
+			current: []DNSAnswer{{DNSQuery: DNSQuery{Domain: "example.com", RecordType: "A"}, Error: "timed out"}},
+			want:    []string{"~ example.com A: status NOERROR -> FAILED"},
+		},
+		{
+			name:    "Query missing from baseline",
+			base:    nil,
+			current: []DNSAnswer{a("example.com", "A", "NOERROR", rec(300, "A", "192.0.2.1"))},
+			want:    []string{"+ example.com. A 192.0.2.1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := DiffDNS(tt.base, tt.current)
+			var got []string
+			for _, c := range d.Changes {
+				got = append(got, c.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffDNS() = %q, want %q", got, tt.want)
+			}
+			if d.Added+d.Removed+d.Changed != len(d.Changes) {
+				t.Errorf("counts %d+%d+%d do not add up to %d changes", d.Added, d.Removed, d.Changed, len(d.Changes))
+			}
+		})
+	}
+}
+
+func TestDNSDiffPrepare(t *testing.T) {
+	p := validation.DefaultPolicy()
+	p.DenyTargets = []string{"10.0.0.0/8"}
+	v, err := validation.NewValidator(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lookup := func(name string) (*DNSBaseline, error) {
+		if name != "before" {
+			return nil, errors.New("not found")
+		}
+		return &DNSBaseline{Name: name, Nameserver: "192.0.2.53", Answers: []DNSAnswer{
+			{DNSQuery: DNSQuery{Domain: "example.com", RecordType: "MX"}},
+		}}, nil
+	}
+	diff := NewDNSDiff(lookup, 2, 4)
+
+	tests := []struct {
+		name    string
+		params  map[string]interface{}
+		argv    []string
+		wantErr string
+	}{
+		{"Snapshot", map[string]interface{}{"queries": "example.com a\nexample.com MX, example.com A"},
+			[]string{"dns-diff", "dig example.com A", "dig example.com MX"}, ""},
+		{"Query objects", map[string]interface{}{"queries": []interface{}{map[string]interface{}{"domain": "example.org", "recordType": "NS"}}, "nameserver": "192.0.2.1"},
+			[]string{"dns-diff", "dig @192.0.2.1 example.org NS"}, ""},
+		{"Baseline", map[string]interface{}{"baseline": "before"},
+			[]string{"dns-diff", "-baseline", "before", "dig @192.0.2.53 example.com MX"}, ""},
+		{"Unknown baseline", map[string]interface{}{"baseline": "after"}, nil, `baseline "after" does not exist`},
+		{"Baseline and queries", map[string]interface{}{"baseline": "before", "queries": "example.com A"}, nil, "queries are taken from the baseline"},
+		{"Missing", map[string]interface{}{}, nil, "queries or a baseline are required"},
+		{"Malformed", map[string]interface{}{"queries": "example.com"}, nil, "must be a name and a record type"},
+		{"Too many", map[string]interface{}{"queries": "a.example A, b.example A, c.example A"}, nil, "at most 2 queries"},
+		{"Bad record type", map[string]interface{}{"queries": "example.com ANY"}, nil, "example.com ANY"},
+		{"Denied nameserver", map[string]interface{}{"queries": "example.com A", "nameserver": "10.0.0.53"}, nil, "not permitted"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inv, err := diff.Prepare(v, tt.params)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Prepare() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Prepare() error = %v", err)
+			}
+			if got := inv.Argv(); !reflect.DeepEqual(got, tt.argv) {
+				t.Errorf("Argv() = %q, want %q", got, tt.argv)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+// File: backend/internal/tools/exec.go
+package tools
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+// ExitCode extracts the process exit code from an error returned by Run.
+// It returns 0 for a nil error and -1 when the process did not exit normally.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
+// runCommand executes binary with args, streaming combined stdout and stderr
+// line by line to output, and returns the complete output once it exits
+func runCommand(ctx context.Context, binary string, args []string, output chan<- string) (string, error) {
+	path, err := exec.LookPath(binary)
+	if err != nil {
+		return "", fmt.Errorf("%s command not available", binary)
+	}
+
+	pr, pw := io.Pipe()
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Stdout = pw
+	cmd.Stderr = pw
+
+	if err := cmd.Start(); err != nil {
+		return "", err
+	}
+
+	waitErr := make(chan error, 1)
+	go func() {
+		err := cmd.Wait()
+		pw.Close()
+		waitErr <- err
+	}()
+
+	// Read output
+	var full strings.Builder
+	scanner := bufio.NewScanner(pr)
+	for scanner.Scan() {
+		line := scanner.Text()
+		full.WriteString(line)
+		full.WriteByte('\n')
+		select {
+		case output <- line:
+		case <-ctx.Done():
+		}
+	}
+	// Drain anything left so the process never blocks on a full pipe
+	io.Copy(io.Discard, pr)
+
+	// Wait for command to complete
+	if err := <-waitErr; err != nil {
+		if ctx.Err() != nil {
+			return full.String(), ctx.Err()
+		}
+		return full.String(), err
+	}
+	return full.String(), nil
+}
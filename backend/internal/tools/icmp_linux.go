@@ -0,0 +1,22 @@
+// File: backend/internal/tools/icmp_linux.go
+package tools
+
+import "syscall"
+
+// datagramICMPAllowed reports whether unprivileged ICMP echo sockets can be
+// opened, as permitted by net.ipv4.ping_group_range
+func datagramICMPAllowed() bool {
+	fd, err := syscall.Socket(syscall.AF_INET, syscall.SOCK_DGRAM, syscall.IPPROTO_ICMP)
+	if err != nil {
+		return false
+	}
+	syscall.Close(fd)
+	return true
+}
+
+// hasFileCapabilities reports whether path carries file capabilities such as
+// cap_net_raw
+func hasFileCapabilities(path string) bool {
+	n, err := syscall.Getxattr(path, "security.capability", nil)
+	return err == nil && n > 0
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+
+// File: backend/internal/tools/icmp_other.go
+package tools
+
+// datagramICMPAllowed is only probed on Linux
+func datagramICMPAllowed() bool {
+	return false
+}
+
+// hasFileCapabilities is only probed on Linux
+func hasFileCapabilities(path string) bool {
+	return false
+}
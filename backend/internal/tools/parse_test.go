@@ -0,0 +1,97 @@
+// File: backend/internal/tools/parse_test.go
+package tools
+
+import (
+	"reflect"
+	"testing"
+)
+
+const linuxPingOutput = `PING example.com (93.184.216.34) 56(84) bytes of data.
+64 bytes from 93.184.216.34 (93.184.216.34): icmp_seq=1 ttl=56 time=11.6 ms
+64 bytes from 93.184.216.34 (93.184.216.34): icmp_seq=2 ttl=56 time=11.8 ms
+
+--- example.com ping statistics ---
+3 packets transmitted, 2 received, 33.3333% packet loss, time 2003ms
+rtt min/avg/max/mdev = 11.567/11.703/11.840/0.136 ms
+`
+
+const digOutput = `
+; <<>> DiG 9.18.18 <<>> example.com MX
+;; global options: +cmd
+;; Got answer:
+;; ->>HEADER<<- opcode: QUERY, status: NOERROR, id: 4242
+;; flags: qr rd ra; QUERY: 1, ANSWER: 2, AUTHORITY: 0, ADDITIONAL: 1
+
+;; QUESTION SECTION:
+;example.com.			IN	MX
+
+;; ANSWER SECTION:
+example.com.		300	IN	MX	10 mx1.example.com.
+example.com.		300	IN	MX	20 mx2.example.com.
+
+;; Query time: 12 msec
+;; SERVER: 1.1.1.1#53(1.1.1.1) (UDP)
+;; WHEN: Mon Jan 01 00:00:00 UTC 2024
+;; MSG SIZE  rcvd: 84
+`
+
+func TestParsePingOutput(t *testing.T) {
+	got := ParsePingOutput(linuxPingOutput)
+	want := &PingResult{
+		Address: "93.184.216.34",
+		Replies: []PingReply{
+			{From: "93.184.216.34", Seq: 1, TTL: 56, TimeMs: 11.6},
+			{From: "93.184.216.34", Seq: 2, TTL: 56, TimeMs: 11.8},
+		},
+		Transmitted: 3,
+		Received:    2,
+		PacketLoss:  33.3333,
+		MinMs:       11.567,
+		AvgMs:       11.703,
+		MaxMs:       11.840,
+		MdevMs:      0.136,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParsePingOutput() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseDigOutput(t *testing.T) {
+	tests := []struct {
+		name string
+		out  string
+		want *DigResult
+	}{
+		{
+			"Full output",
+			digOutput,
+			&DigResult{
+				Status: "NOERROR",
+				Flags:  []string{"qr", "rd", "ra"},
+				Answer: []DigRecord{
+					{Name: "example.com.", TTL: 300, Class: "IN", Type: "MX", Data: "10 mx1.example.com."},
+					{Name: "example.com.", TTL: 300, Class: "IN", Type: "MX", Data: "20 mx2.example.com."},
+				},
+				QueryTimeMs: 12,
+				Server:      "1.1.1.1",
+			},
+		},
+		{
+			"Short output",
+			"10 mx1.example.com.\n20 mx2.example.com.\n",
+			&DigResult{
+				Answer: []DigRecord{},
+				Short:  []string{"10 mx1.example.com.", "20 mx2.example.com."},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseDigOutput(tt.out)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseDigOutput() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
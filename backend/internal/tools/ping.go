@@ -3,16 +3,211 @@ package tools
 
 import (
 	"context"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"backend/internal/validation"
 )
 
 type PingParams struct {
 	Target string `json:"target" validate:"required,hostname|ip"`
 	Count  int    `json:"count" validate:"required,min=1,max=30"`
+	// Address is the policy checked address pinged in place of Target,
+	// empty to let ping resolve Target itself
+	Address string `json:"-"`
+}
+
+// PingReply is a single echo reply reported by ping
+type PingReply struct {
+	From   string  `json:"from"`
+	Seq    int     `json:"seq"`
+	TTL    int     `json:"ttl"`
+	TimeMs float64 `json:"timeMs"`
+}
+
+// PingResult is the structured form of ping output
+type PingResult struct {
+	Target      string      `json:"target"`
+	Address     string      `json:"address,omitempty"`
+	Replies     []PingReply `json:"replies"`
+	Transmitted int         `json:"transmitted"`
+	Received    int         `json:"received"`
+	PacketLoss  float64     `json:"packetLoss"`
+	MinMs       float64     `json:"minMs,omitempty"`
+	AvgMs       float64     `json:"avgMs,omitempty"`
+	MaxMs       float64     `json:"maxMs,omitempty"`
+	MdevMs      float64     `json:"mdevMs,omitempty"`
+}
+
+var (
+	pingHeaderRegex  = regexp.MustCompile(`^PING \S+ \(([^)]+)\)`)
+	pingReplyRegex   = regexp.MustCompile(`bytes from ([^:]+):.*icmp_seq=(\d+).*ttl=(\d+).*time[=<]([\d.]+)`)
+	pingSummaryRegex = regexp.MustCompile(`(\d+) packets transmitted, (\d+) (?:packets )?received.*?([\d.]+)% packet loss`)
+	pingRTTRegex     = regexp.MustCompile(`= ([\d.]+)/([\d.]+)/([\d.]+)(?:/([\d.]+))? ms`)
+)
+
+// Ping is the ping tool
+type Ping struct{}
+
+// Name implements Tool
+func (Ping) Name() string { return "ping" }
+
+// Check implements Checker, including whether ICMP echo requests can be sent
+func (Ping) Check(ctx context.Context) Status {
+	s := checkBinary(ctx, "ping", "ping", "-V")
+	if s.Available {
+		s.ICMP = checkICMP(s.Path)
+		if !s.ICMP.Permitted {
+			s.Available = false
+			s.Error = "ICMP sockets are not permitted"
+		}
+	}
+	return s
+}
+
+// Prepare implements Tool
+func (Ping) Prepare(v *validation.Validator, params map[string]interface{}) (Invocation, error) {
+	p, err := ParsePingParams(v, params)
+	if err != nil {
+		return nil, err
+	}
+	return pingInvocation{params: *p}, nil
+}
+
+type pingInvocation struct {
+	params PingParams
+}
+
+func (i pingInvocation) Argv() []string {
+	return append([]string{"ping"}, PingArgs(i.params)...)
+}
+
+func (i pingInvocation) Run(ctx context.Context, output chan<- string) (interface{}, error) {
+	result, err := ExecutePing(ctx, i.params, output)
+	if result == nil {
+		return nil, err
+	}
+	return result, err
+}
+
+// ParsePingParams validates ping command parameters
+func ParsePingParams(v *validation.Validator, params map[string]interface{}) (*PingParams, error) {
+	var p PingParams
+
+	// Extract and validate target
+	targetRaw, ok := params["target"]
+	if !ok {
+		return nil, &validation.ValidationError{Field: "target", Message: "target is required"}
+	}
+	if target, ok := targetRaw.(string); ok {
+		if err := validation.ValidateTarget(target); err != nil {
+			return nil, err
+		}
+		address, err := v.ResolveTarget("target", target)
+		if err != nil {
+			return nil, err
+		}
+		p.Target = target
+		if address != target {
+			p.Address = address
+		}
+	} else {
+		return nil, &validation.ValidationError{Field: "target", Message: "invalid target format"}
+	}
+
+	// Extract and validate count
+	countRaw, ok := params["count"]
+	if !ok {
+		return nil, &validation.ValidationError{Field: "count", Message: "count is required"}
+	}
+
+	// Handle JSON number type conversion
+	var count int
+	switch v := countRaw.(type) {
+	case float64:
+		count = int(v)
+	case int:
+		count = v
+	default:
+		return nil, &validation.ValidationError{Field: "count", Message: "invalid count format"}
+	}
+
+	if err := v.ValidatePingCount(count); err != nil {
+		return nil, err
+	}
+	p.Count = count
+
+	return &p, nil
+}
+
+// PingArgs builds the ping command line arguments
+func PingArgs(params PingParams) []string {
+	target := params.Target
+	if params.Address != "" {
+		target = params.Address
+	}
+	return []string{"-c", strconv.Itoa(params.Count), target}
+}
+
+// ExecutePing runs ping, streaming its output, and returns the parsed result
+func ExecutePing(ctx context.Context, params PingParams, output chan<- string) (*PingResult, error) {
+	out, err := runCommand(ctx, "ping", PingArgs(params), output)
+	if out == "" && err != nil {
+		return nil, err
+	}
+	result := ParsePingOutput(out)
+	result.Target = params.Target
+	return result, err
+}
+
+// ParsePingOutput extracts replies and statistics from ping output
+func ParsePingOutput(out string) *PingResult {
+	result := &PingResult{Replies: []PingReply{}}
+
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		if m := pingHeaderRegex.FindStringSubmatch(line); m != nil {
+			result.Address = m[1]
+			continue
+		}
+		if m := pingReplyRegex.FindStringSubmatch(line); m != nil {
+			seq, _ := strconv.Atoi(m[2])
+			ttl, _ := strconv.Atoi(m[3])
+			rtt, _ := strconv.ParseFloat(m[4], 64)
+			result.Replies = append(result.Replies, PingReply{
+				From:   pingReplyAddress(m[1]),
+				Seq:    seq,
+				TTL:    ttl,
+				TimeMs: rtt,
+			})
+			continue
+		}
+		if m := pingSummaryRegex.FindStringSubmatch(line); m != nil {
+			result.Transmitted, _ = strconv.Atoi(m[1])
+			result.Received, _ = strconv.Atoi(m[2])
+			result.PacketLoss, _ = strconv.ParseFloat(m[3], 64)
+			continue
+		}
+		if m := pingRTTRegex.FindStringSubmatch(line); m != nil {
+			result.MinMs, _ = strconv.ParseFloat(m[1], 64)
+			result.AvgMs, _ = strconv.ParseFloat(m[2], 64)
+			result.MaxMs, _ = strconv.ParseFloat(m[3], 64)
+			if m[4] != "" {
+				result.MdevMs, _ = strconv.ParseFloat(m[4], 64)
+			}
+		}
+	}
+
+	return result
 }
 
-func ExecutePing(ctx context.Context, params PingParams, output chan<- string) error {
-	// TODO: Implement ping command execution
-	// TODO: Validate parameters
-	// TODO: Stream output through channel
-	return nil
+// pingReplyAddress reduces "host (1.2.3.4)" to the bare address
+func pingReplyAddress(from string) string {
+	if start := strings.Index(from, "("); start >= 0 {
+		if end := strings.Index(from[start:], ")"); end > 0 {
+			return from[start+1 : start+end]
+		}
+	}
+	return strings.TrimSpace(from)
 }
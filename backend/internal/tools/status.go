@@ -0,0 +1,98 @@
+// File: backend/internal/tools/status.go
+package tools
+
+import (
+	"context"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// statusTimeout bounds the version command run by a status check
+const statusTimeout = 2 * time.Second
+
+// Status reports whether a tool's dependencies are usable on this host
+type Status struct {
+	Name      string      `json:"name"`
+	Available bool        `json:"available"`
+	Path      string      `json:"path,omitempty"`
+	Version   string      `json:"version,omitempty"`
+	Error     string      `json:"error,omitempty"`
+	ICMP      *ICMPStatus `json:"icmp,omitempty"`
+}
+
+// ICMPStatus describes how ICMP echo requests can be sent
+type ICMPStatus struct {
+	// Permitted reports whether any of the mechanisms below is usable
+	Permitted bool `json:"permitted"`
+	// Raw reports whether this process may open raw ICMP sockets
+	Raw bool `json:"raw"`
+	// Datagram reports whether unprivileged ICMP sockets are allowed
+	Datagram bool `json:"datagram"`
+	// Privileged reports whether the binary is setuid root or has file
+	// capabilities, letting it open raw sockets itself
+	Privileged bool `json:"privileged"`
+}
+
+// Checker is implemented by tools that can report on their dependencies
+type Checker interface {
+	Check(ctx context.Context) Status
+}
+
+// Status checks every registered tool, in name order. Tools that do not
+// implement Checker are reported as available.
+func (r *Registry) Status(ctx context.Context) []Status {
+	statuses := make([]Status, 0, len(r.tools))
+	for _, name := range r.Names() {
+		if c, ok := r.tools[name].(Checker); ok {
+			statuses = append(statuses, c.Check(ctx))
+			continue
+		}
+		statuses = append(statuses, Status{Name: name, Available: true})
+	}
+	return statuses
+}
+
+// checkBinary looks up binary and records the first line printed by running
+// it with versionArgs
+func checkBinary(ctx context.Context, name, binary string, versionArgs ...string) Status {
+	s := Status{Name: name}
+	path, err := exec.LookPath(binary)
+	if err != nil {
+		s.Error = binary + " command not available"
+		return s
+	}
+	s.Path = path
+	s.Available = true
+
+	ctx, cancel := context.WithTimeout(ctx, statusTimeout)
+	defer cancel()
+	// Some tools exit non-zero after printing their version, so the output is
+	// used whenever there is any
+	out, _ := exec.CommandContext(ctx, path, versionArgs...).CombinedOutput()
+	if line, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n"); line != "" {
+		s.Version = strings.TrimSpace(line)
+	}
+	return s
+}
+
+// checkICMP probes which ICMP sockets are available for the binary at path
+func checkICMP(path string) *ICMPStatus {
+	s := &ICMPStatus{
+		Datagram: datagramICMPAllowed(),
+	}
+	if conn, err := net.ListenPacket("ip4:icmp", "0.0.0.0"); err == nil {
+		conn.Close()
+		s.Raw = true
+	}
+	if info, err := os.Stat(path); err == nil && info.Mode()&os.ModeSetuid != 0 {
+		s.Privileged = true
+	}
+	if hasFileCapabilities(path) {
+		s.Privileged = true
+	}
+	s.Permitted = s.Raw || s.Datagram || s.Privileged
+	return s
+}
@@ -0,0 +1,201 @@
+// File: backend/internal/tools/sweep.go
+package tools
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"net"
+	"os/exec"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"backend/internal/validation"
+)
+
+// SweepHost is a host that answered a sweep
+type SweepHost struct {
+	Address string  `json:"address"`
+	TimeMs  float64 `json:"timeMs"`
+}
+
+// SweepResult is the outcome of a ping sweep
+type SweepResult struct {
+	Network string `json:"network"`
+	Hosts   int    `json:"hosts"`
+	Up      int    `json:"up"`
+	Down    int    `json:"down"`
+	// Responders lists the hosts that answered, in address order
+	Responders []SweepHost `json:"responders"`
+}
+
+// Sweep pings every host of an IPv4 range once, reporting which answer
+type Sweep struct {
+	maxHosts    int
+	concurrency int
+	timeout     time.Duration
+}
+
+// NewSweep creates a sweep tool accepting ranges of up to maxHosts hosts,
+// probing concurrency hosts at once and waiting timeout for each reply
+func NewSweep(maxHosts, concurrency int, timeout time.Duration) Sweep {
+	return Sweep{maxHosts: maxHosts, concurrency: concurrency, timeout: timeout}
+}
+
+// Name implements Tool
+func (Sweep) Name() string { return "sweep" }
+
+// Check implements Checker. Sweeps run ping, so they need what ping needs.
+func (Sweep) Check(ctx context.Context) Status {
+	s := Ping{}.Check(ctx)
+	s.Name = "sweep"
+	return s
+}
+
+// Prepare implements Tool. params holds the range in CIDR notation, which
+// must fit the size limit and be permitted as a whole by the target policy.
+func (s Sweep) Prepare(v *validation.Validator, params map[string]interface{}) (Invocation, error) {
+	if err := v.CheckTool("ping"); err != nil {
+		return nil, err
+	}
+	cidr, ok := params["cidr"].(string)
+	if !ok || cidr == "" {
+		return nil, &validation.ValidationError{Field: "cidr", Message: "cidr is required"}
+	}
+	ip, network, err := net.ParseCIDR(cidr)
+	if err != nil || ip.To4() == nil {
+		return nil, &validation.ValidationError{Field: "cidr", Message: "cidr must be an IPv4 range such as 192.0.2.0/24"}
+	}
+	if !ip.Equal(network.IP) {
+		return nil, &validation.ValidationError{Field: "cidr", Message: fmt.Sprintf("cidr has host bits set, did you mean %s?", network)}
+	}
+	if n := sweepHostCount(network); n > uint64(s.maxHosts) {
+		return nil, &validation.ValidationError{Field: "cidr", Message: fmt.Sprintf("range has %d hosts, at most %d are allowed", n, s.maxHosts)}
+	}
+	if err := v.CheckRange("cidr", network); err != nil {
+		return nil, err
+	}
+	return sweepInvocation{network: network, concurrency: s.concurrency, timeout: s.timeout}, nil
+}
+
+// sweepHostCount is the number of hosts in a range, leaving out the network
+// and broadcast addresses of ranges larger than /31
+func sweepHostCount(n *net.IPNet) uint64 {
+	ones, bits := n.Mask.Size()
+	size := uint64(1) << (bits - ones)
+	if size > 2 {
+		size -= 2
+	}
+	return size
+}
+
+// sweepHosts lists the hosts of a range counted by sweepHostCount
+func sweepHosts(n *net.IPNet) []net.IP {
+	count := sweepHostCount(n)
+	first := binary.BigEndian.Uint32(n.IP.To4())
+	if ones, bits := n.Mask.Size(); bits-ones > 1 {
+		first++
+	}
+	hosts := make([]net.IP, count)
+	for i := range hosts {
+		ip := make(net.IP, 4)
+		binary.BigEndian.PutUint32(ip, first+uint32(i))
+		hosts[i] = ip
+	}
+	return hosts
+}
+
+type sweepInvocation struct {
+	network     *net.IPNet
+	concurrency int
+	timeout     time.Duration
+}
+
+func (i sweepInvocation) Argv() []string {
+	return []string{"sweep", "-W", i.waitSeconds(), i.network.String()}
+}
+
+// waitSeconds is ping's -W reply timeout, which takes whole seconds
+func (i sweepInvocation) waitSeconds() string {
+	return strconv.Itoa(max(int(math.Ceil(i.timeout.Seconds())), 1))
+}
+
+// Run probes the hosts concurrently, borrowing free job slots for all but the
+// first, reporting each as up or down as soon as it is known, followed by a
+// summary of the responders
+func (i sweepInvocation) Run(ctx context.Context, output chan<- string) (interface{}, error) {
+	path, err := exec.LookPath("ping")
+	if err != nil {
+		return nil, fmt.Errorf("ping command not available")
+	}
+
+	hosts := sweepHosts(i.network)
+	up := make([]*SweepHost, len(hosts))
+	fanOut(ctx, len(hosts), i.concurrency, func(n int) {
+		addr := hosts[n].String()
+		host, ok := i.probe(ctx, path, addr)
+		if ctx.Err() != nil {
+			return
+		}
+		if ok {
+			up[n] = &host
+			send(ctx, output, fmt.Sprintf("%s up %s ms", addr, formatRTT(host.TimeMs)))
+		} else {
+			send(ctx, output, addr+" down")
+		}
+	})
+
+	result := &SweepResult{Network: i.network.String(), Hosts: len(hosts), Responders: []SweepHost{}}
+	for _, host := range up {
+		if host != nil {
+			result.Responders = append(result.Responders, *host)
+		}
+	}
+	result.Up = len(result.Responders)
+	result.Down = result.Hosts - result.Up
+	if err := ctx.Err(); err != nil {
+		return result, err
+	}
+
+	for _, line := range sweepTable(result) {
+		send(ctx, output, line)
+	}
+	return result, nil
+}
+
+// probe sends a single echo request to addr and reports whether it answered
+func (i sweepInvocation) probe(ctx context.Context, path, addr string) (SweepHost, bool) {
+	// ping normally gives up after -W; the deadline only guards against it
+	// hanging
+	ctx, cancel := context.WithTimeout(ctx, i.timeout+time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, path, "-c", "1", "-W", i.waitSeconds(), "-n", addr).CombinedOutput()
+	r := ParsePingOutput(string(out))
+	if err != nil || len(r.Replies) == 0 {
+		return SweepHost{}, false
+	}
+	return SweepHost{Address: addr, TimeMs: r.Replies[0].TimeMs}, true
+}
+
+// sweepTable renders the summary printed at the end of a sweep
+func sweepTable(result *SweepResult) []string {
+	lines := []string{"", fmt.Sprintf("--- sweep %s: %d hosts, %d up, %d down ---", result.Network, result.Hosts, result.Up, result.Down)}
+	if len(result.Responders) == 0 {
+		return append(lines, "no hosts answered")
+	}
+	var b strings.Builder
+	tw := tabwriter.NewWriter(&b, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "ADDRESS\tTIME")
+	for _, h := range result.Responders {
+		fmt.Fprintf(tw, "%s\t%s ms\n", h.Address, formatRTT(h.TimeMs))
+	}
+	tw.Flush()
+	return append(lines, strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")...)
+}
+
+func formatRTT(ms float64) string {
+	return strconv.FormatFloat(ms, 'f', -1, 64)
+}
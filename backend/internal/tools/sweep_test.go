@@ -0,0 +1,83 @@
+// File: backend/internal/tools/sweep_test.go
+package tools
+
+import (
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/internal/validation"
+)
+
+func TestSweepHosts(t *testing.T) {
+	tests := []struct {
+		cidr string
+		want []string
+	}{
+		{"192.0.2.0/30", []string{"192.0.2.1", "192.0.2.2"}},
+		{"192.0.2.4/31", []string{"192.0.2.4", "192.0.2.5"}},
+		{"192.0.2.9/32", []string{"192.0.2.9"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cidr, func(t *testing.T) {
+			_, n, _ := net.ParseCIDR(tt.cidr)
+			var got []string
+			for _, ip := range sweepHosts(n) {
+				got = append(got, ip.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sweepHosts(%s) = %v, want %v", tt.cidr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSweepPrepare(t *testing.T) {
+	p := validation.DefaultPolicy()
+	p.DenyTargets = []string{"10.0.0.0/8"}
+	v, err := validation.NewValidator(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sweep := NewSweep(254, 16, 2*time.Second)
+
+	tests := []struct {
+		name    string
+		cidr    interface{}
+		argv    []string
+		wantErr string
+	}{
+		{"Class C", "192.0.2.0/24", []string{"sweep", "-W", "2", "192.0.2.0/24"}, ""},
+		{"Missing", nil, nil, "cidr is required"},
+		{"Not a range", "192.0.2.1", nil, "must be an IPv4 range"},
+		{"IPv6", "2001:db8::/120", nil, "must be an IPv4 range"},
+		{"Host bits set", "192.0.2.1/24", nil, "did you mean 192.0.2.0/24?"},
+		{"Too large", "192.0.0.0/23", nil, "range has 510 hosts, at most 254 are allowed"},
+		{"Denied", "10.1.2.0/24", nil, "not permitted by policy"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := map[string]interface{}{}
+			if tt.cidr != nil {
+				params["cidr"] = tt.cidr
+			}
+			inv, err := sweep.Prepare(v, params)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Prepare() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Prepare() error = %v", err)
+			}
+			if got := inv.Argv(); !reflect.DeepEqual(got, tt.argv) {
+				t.Errorf("Argv() = %q, want %q", got, tt.argv)
+			}
+		})
+	}
+}
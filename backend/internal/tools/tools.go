@@ -0,0 +1,95 @@
+// File: backend/internal/tools/tools.go
+package tools
+
+import (
+	"context"
+	"sort"
+
+	"backend/internal/validation"
+)
+
+// Tool describes a network diagnostic that can be validated and executed
+type Tool interface {
+	// Name returns the identifier clients use to request the tool
+	Name() string
+	// Prepare validates raw request parameters against v and returns a
+	// runnable invocation
+	Prepare(v *validation.Validator, params map[string]interface{}) (Invocation, error)
+}
+
+// Invocation is a validated, ready to run tool execution
+type Invocation interface {
+	// Argv returns the command line that will be executed
+	Argv() []string
+	// Run executes the command, streaming output lines to the channel, and
+	// returns the structured result parsed from the complete output
+	Run(ctx context.Context, output chan<- string) (interface{}, error)
+}
+
+// Slots lends the job slots bounding how many tools run at once. A tool
+// that runs sub-invocations in parallel borrows a slot for each one beyond
+// the first, which runs on the job's own slot.
+type Slots interface {
+	// TryAcquire takes a free slot without waiting, reporting whether it
+	// got one. Jobs waiting for a slot go first.
+	TryAcquire() bool
+	// Release returns a slot taken by TryAcquire
+	Release()
+}
+
+type slotsKey struct{}
+
+// WithSlots returns a context whose invocations borrow slots from s
+func WithSlots(ctx context.Context, s Slots) context.Context {
+	return context.WithValue(ctx, slotsKey{}, s)
+}
+
+// slotsFrom returns the slots an invocation may borrow. Without any, run
+// outside a job manager, every parallel sub-invocation may run.
+func slotsFrom(ctx context.Context) Slots {
+	if s, ok := ctx.Value(slotsKey{}).(Slots); ok {
+		return s
+	}
+	return unlimitedSlots{}
+}
+
+type unlimitedSlots struct{}
+
+func (unlimitedSlots) TryAcquire() bool { return true }
+
+func (unlimitedSlots) Release() {}
+
+// Registry holds the tools available to clients
+type Registry struct {
+	tools map[string]Tool
+}
+
+// NewRegistry creates a registry containing the given tools
+func NewRegistry(tools ...Tool) *Registry {
+	r := &Registry{tools: make(map[string]Tool)}
+	for _, t := range tools {
+		r.tools[t.Name()] = t
+	}
+	return r
+}
+
+// DefaultRegistry returns a registry with every built-in tool
+func DefaultRegistry() *Registry {
+	return NewRegistry(Ping{}, Dig{})
+}
+
+// Lookup returns the tool registered under name
+func (r *Registry) Lookup(name string) (Tool, bool) {
+	t, ok := r.tools[name]
+	return t, ok
+}
+
+// Names returns the sorted names of all registered tools
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.tools))
+	for name := range r.tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,522 @@
+// File: backend/internal/tools/zonecheck.go
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"backend/internal/validation"
+)
+
+// Kinds of zone problems
+const (
+	ProblemSerialMismatch   = "serial-mismatch"
+	ProblemLameDelegation   = "lame-delegation"
+	ProblemNonAuthoritative = "non-authoritative"
+	ProblemNSMismatch       = "ns-mismatch"
+	ProblemAnswerMismatch   = "answer-mismatch"
+	ProblemParentLookup     = "parent-lookup"
+	ProblemNotChecked       = "not-checked"
+)
+
+// maxParentServers bounds the parent zone servers asked for the delegation
+// before giving up
+const maxParentServers = 3
+
+// ZoneServer is what one of a zone's nameservers answered
+type ZoneServer struct {
+	Name string `json:"name"`
+	// InParent and InChild report which NS sets list the server
+	InParent bool `json:"inParent"`
+	InChild  bool `json:"inChild"`
+	// Serial is the SOA serial the server returned
+	Serial string `json:"serial,omitempty"`
+	// SOAStatus is the response status of the SOA query
+	SOAStatus string `json:"soaStatus,omitempty"`
+	// Lame is set when the server did not answer the SOA query
+	// authoritatively
+	Lame bool `json:"lame"`
+	// Status, Answer and Authoritative describe the response to the chosen
+	// record set
+	Status        string   `json:"status,omitempty"`
+	Answer        []string `json:"answer"`
+	Authoritative bool     `json:"authoritative"`
+	QueryTimeMs   int      `json:"queryTimeMs,omitempty"`
+	// Skipped is set when the policy forbids querying the server
+	Skipped bool `json:"skipped,omitempty"`
+	// Error is why the server could not be checked
+	Error string `json:"error,omitempty"`
+}
+
+// ZoneProblem is one inconsistency found in a zone
+type ZoneProblem struct {
+	Kind string `json:"kind"`
+	// Server is the nameserver the problem concerns, empty for problems of
+	// the whole zone
+	Server  string `json:"server,omitempty"`
+	Message string `json:"message"`
+}
+
+func (p ZoneProblem) String() string {
+	return p.Kind + ": " + p.Message
+}
+
+// ZoneCheckResult is the outcome of a zone-check job
+type ZoneCheckResult struct {
+	Zone       string `json:"zone"`
+	RecordType string `json:"recordType"`
+	// ParentServer is the parent zone server the delegation was read from
+	ParentServer string        `json:"parentServer,omitempty"`
+	ParentNS     []string      `json:"parentNs"`
+	ChildNS      []string      `json:"childNs"`
+	Servers      []ZoneServer  `json:"servers"`
+	Problems     []ZoneProblem `json:"problems"`
+	Healthy      bool          `json:"healthy"`
+}
+
+// ZoneCheck checks that the authoritative nameservers of a zone agree with
+// each other and with the delegation in the parent zone. The nameservers
+// are discovered while the job runs, so each is checked against the policy
+// then; servers the policy forbids are skipped.
+type ZoneCheck struct {
+	maxServers int
+}
+
+// NewZoneCheck creates a zone-check tool querying at most maxServers of a
+// zone's nameservers
+func NewZoneCheck(maxServers int) ZoneCheck {
+	return ZoneCheck{maxServers: maxServers}
+}
+
+// Name implements Tool
+func (ZoneCheck) Name() string { return "zone-check" }
+
+// Check implements Checker. Zone checks run dig, so they need what dig
+// needs.
+func (ZoneCheck) Check(ctx context.Context) Status {
+	s := Dig{}.Check(ctx)
+	s.Name = "zone-check"
+	return s
+}
+
+// Prepare implements Tool. params holds the zone and optionally the record
+// type queried from every nameserver besides SOA, NS by default.
+func (z ZoneCheck) Prepare(v *validation.Validator, params map[string]interface{}) (Invocation, error) {
+	if err := v.CheckTool("dig"); err != nil {
+		return nil, err
+	}
+	zone, ok := params["zone"].(string)
+	if !ok || zone == "" {
+		return nil, &validation.ValidationError{Field: "zone", Message: "zone is required"}
+	}
+	if err := validation.ValidateDomain(zone); err != nil {
+		return nil, &validation.ValidationError{Field: "zone", Message: err.(*validation.ValidationError).Message}
+	}
+	if err := v.CheckTarget("zone", zone); err != nil {
+		return nil, err
+	}
+	recordType := "NS"
+	if raw, ok := params["recordType"]; ok {
+		rt, ok := raw.(string)
+		if !ok {
+			return nil, &validation.ValidationError{Field: "recordType", Message: "invalid record type format"}
+		}
+		if err := v.ValidateRecordType(rt); err != nil {
+			return nil, err
+		}
+		recordType = strings.ToUpper(rt)
+	}
+	return zoneCheckInvocation{
+		zone:       strings.ToLower(strings.TrimSuffix(zone, ".")) + ".",
+		recordType: recordType,
+		maxServers: z.maxServers,
+		validator:  v,
+	}, nil
+}
+
+type zoneCheckInvocation struct {
+	// zone is lower case and fully qualified
+	zone       string
+	recordType string
+	maxServers int
+	// validator checks the discovered nameservers against the policy
+	validator *validation.Validator
+}
+
+func (i zoneCheckInvocation) Argv() []string {
+	return []string{"zone-check", i.zone, i.recordType}
+}
+
+// Run discovers the child and parent NS sets, queries every nameserver for
+// the SOA and the chosen record set without recursion, concurrently on free
+// job slots, and reports the problems found. Problems do not fail the job; failing to find any NS
+// records does.
+func (i zoneCheckInvocation) Run(ctx context.Context, output chan<- string) (interface{}, error) {
+	result := &ZoneCheckResult{Zone: i.zone, RecordType: i.recordType, ParentNS: []string{}, ChildNS: []string{}, Servers: []ZoneServer{}, Problems: []ZoneProblem{}}
+
+	child, err := i.dig(ctx, i.zone, "NS", "", "")
+	if err != nil && child == nil {
+		return result, err
+	}
+	if child != nil {
+		result.ChildNS = nsNames(child, i.zone)
+	}
+	if len(result.ChildNS) == 0 {
+		return result, fmt.Errorf("no NS records found for %s, is it the apex of a zone?", i.zone)
+	}
+	send(ctx, output, fmt.Sprintf("child NS: %s", strings.Join(result.ChildNS, ", ")))
+
+	if problem := i.parentNS(ctx, result); problem != nil {
+		result.Problems = append(result.Problems, *problem)
+		send(ctx, output, problem.String())
+	} else {
+		send(ctx, output, fmt.Sprintf("parent NS (from %s): %s", result.ParentServer, strings.Join(result.ParentNS, ", ")))
+	}
+
+	result.Servers = zoneServers(result.ParentNS, result.ChildNS)
+	if len(result.Servers) > i.maxServers {
+		send(ctx, output, fmt.Sprintf("checking the first %d of %d nameservers", i.maxServers, len(result.Servers)))
+		result.Servers = result.Servers[:i.maxServers]
+	}
+
+	fanOut(ctx, len(result.Servers), len(result.Servers), func(n int) {
+		s := &result.Servers[n]
+		i.checkServer(ctx, s)
+		send(ctx, output, serverLine(s, i.recordType))
+	})
+	if err := ctx.Err(); err != nil {
+		return result, err
+	}
+
+	analyseZone(result)
+	for _, line := range zoneTable(result) {
+		send(ctx, output, line)
+	}
+	return result, nil
+}
+
+// parentNS reads the zone's delegation from a server of the parent zone.
+// It returns a problem when no parent server gave one.
+func (i zoneCheckInvocation) parentNS(ctx context.Context, result *ZoneCheckResult) *ZoneProblem {
+	parent := parentZone(i.zone)
+	r, err := i.dig(ctx, parent, "NS", "", "")
+	if r == nil || len(nsNames(r, parent)) == 0 {
+		msg := fmt.Sprintf("no nameservers found for the parent zone %s", parent)
+		if err != nil {
+			msg += ": " + err.Error()
+		}
+		return &ZoneProblem{Kind: ProblemParentLookup, Message: msg}
+	}
+
+	tried := 0
+	var reasons []string
+	for _, server := range nsNames(r, parent) {
+		if tried == maxParentServers {
+			break
+		}
+		address, err := i.validator.ResolveTarget("nameserver", strings.TrimSuffix(server, "."))
+		if err != nil {
+			continue
+		}
+		tried++
+		d, err := i.dig(ctx, i.zone, "NS", server, address)
+		switch {
+		case d != nil && d.Status == "NOERROR" && len(nsNames(d, i.zone)) > 0:
+			result.ParentServer = server
+			result.ParentNS = nsNames(d, i.zone)
+			return nil
+		case d != nil && d.Status != "":
+			reasons = append(reasons, fmt.Sprintf("%s answered %s", server, d.Status))
+		case err != nil:
+			reasons = append(reasons, fmt.Sprintf("%s: %s", server, err))
+		default:
+			reasons = append(reasons, server+" gave no response")
+		}
+	}
+	if tried == 0 {
+		return &ZoneProblem{Kind: ProblemParentLookup, Message: fmt.Sprintf("no server of the parent zone %s is permitted by policy", parent)}
+	}
+	return &ZoneProblem{Kind: ProblemParentLookup, Message: fmt.Sprintf("no delegation for %s found in %s (%s)", i.zone, parent, strings.Join(reasons, "; "))}
+}
+
+// checkServer queries one nameserver for the zone's SOA and the chosen
+// record set
+func (i zoneCheckInvocation) checkServer(ctx context.Context, s *ZoneServer) {
+	s.Answer = []string{}
+	address, err := i.validator.ResolveTarget("nameserver", strings.TrimSuffix(s.Name, "."))
+	if err != nil {
+		s.Skipped = true
+		s.Error = "not permitted by policy"
+		return
+	}
+
+	soa, err := i.dig(ctx, i.zone, "SOA", s.Name, address)
+	switch {
+	case soa == nil || soa.Status == "":
+		s.Lame = true
+		s.Error = "no response"
+		if err != nil {
+			s.Error = err.Error()
+		}
+		return
+	case soa.Status != "NOERROR" || !hasFlag(soa, "aa"):
+		s.Lame = true
+	}
+	s.SOAStatus = soa.Status
+	for _, rec := range soa.Answer {
+		if rec.Type == "SOA" && sameName(rec.Name, i.zone) {
+			s.Serial = soaSerial(rec.Data)
+		}
+	}
+
+	r, err := i.dig(ctx, i.zone, i.recordType, s.Name, address)
+	if r == nil || r.Status == "" {
+		s.Error = "no response to " + i.recordType + " query"
+		if err != nil {
+			s.Error = i.recordType + " query: " + err.Error()
+		}
+		return
+	}
+	s.Status, s.QueryTimeMs = r.Status, r.QueryTimeMs
+	s.Authoritative = hasFlag(r, "aa")
+	for _, rec := range r.Answer {
+		s.Answer = append(s.Answer, rec.Data)
+	}
+	sort.Strings(s.Answer)
+}
+
+// dig runs a query without recursion against server, or a recursive query
+// through the system resolver when server is empty. address is the policy
+// checked address of server, queried in its place.
+func (i zoneCheckInvocation) dig(ctx context.Context, domain, recordType, server, address string) (*DigResult, error) {
+	params := DigParams{Domain: domain, RecordType: recordType}
+	if server != "" {
+		params.Nameserver = server
+		params.Parameters = map[string]interface{}{"norecurse": true}
+		if address != strings.TrimSuffix(server, ".") {
+			params.NameserverAddress = address
+		}
+	}
+	return runDig(ctx, digInvocation{params: params})
+}
+
+// analyseZone records the problems of a zone whose servers were checked
+func analyseZone(result *ZoneCheckResult) {
+	if len(result.ParentNS) > 0 {
+		var onlyParent, onlyChild []string
+		for _, s := range result.Servers {
+			switch {
+			case s.InParent && !s.InChild:
+				onlyParent = append(onlyParent, s.Name)
+			case s.InChild && !s.InParent:
+				onlyChild = append(onlyChild, s.Name)
+			}
+		}
+		if len(onlyParent)+len(onlyChild) > 0 {
+			var parts []string
+			if len(onlyParent) > 0 {
+				parts = append(parts, "only in the parent: "+strings.Join(onlyParent, ", "))
+			}
+			if len(onlyChild) > 0 {
+				parts = append(parts, "only in the zone: "+strings.Join(onlyChild, ", "))
+			}
+			result.Problems = append(result.Problems, ZoneProblem{Kind: ProblemNSMismatch, Message: "NS sets differ, " + strings.Join(parts, "; ")})
+		}
+	}
+
+	serials := make(map[string][]string)
+	answers := make(map[string][]string)
+	var serialOrder, answerOrder []string
+	checked := 0
+	for _, s := range result.Servers {
+		if s.Skipped {
+			continue
+		}
+		checked++
+		if s.Lame {
+			reason := "did not answer authoritatively"
+			switch {
+			case s.Error != "":
+				reason = s.Error
+			case s.SOAStatus != "NOERROR":
+				reason = "answered " + s.SOAStatus
+			}
+			result.Problems = append(result.Problems, ZoneProblem{Kind: ProblemLameDelegation, Server: s.Name, Message: fmt.Sprintf("%s %s for %s", s.Name, reason, result.Zone)})
+			continue
+		}
+		if s.Serial != "" {
+			if len(serials[s.Serial]) == 0 {
+				serialOrder = append(serialOrder, s.Serial)
+			}
+			serials[s.Serial] = append(serials[s.Serial], s.Name)
+		}
+		if s.Status == "" {
+			continue
+		}
+		if !s.Authoritative {
+			result.Problems = append(result.Problems, ZoneProblem{Kind: ProblemNonAuthoritative, Server: s.Name, Message: fmt.Sprintf("%s answered %s %s without the authoritative flag", s.Name, result.Zone, result.RecordType)})
+		}
+		key := s.Status + " " + strings.Join(s.Answer, ", ")
+		if len(answers[key]) == 0 {
+			answerOrder = append(answerOrder, key)
+		}
+		answers[key] = append(answers[key], s.Name)
+	}
+	if checked == 0 && len(result.Servers) > 0 {
+		result.Problems = append(result.Problems, ZoneProblem{Kind: ProblemNotChecked, Message: fmt.Sprintf("no nameserver of %s is permitted by policy", result.Zone)})
+	}
+	if len(serialOrder) > 1 {
+		result.Problems = append(result.Problems, ZoneProblem{Kind: ProblemSerialMismatch, Message: "SOA serials differ: " + groupSummary(serialOrder, serials)})
+	}
+	if len(answerOrder) > 1 {
+		result.Problems = append(result.Problems, ZoneProblem{Kind: ProblemAnswerMismatch, Message: result.RecordType + " answers differ: " + groupSummary(answerOrder, answers)})
+	}
+	result.Healthy = len(result.Problems) == 0
+}
+
+// groupSummary lists each value with the servers that returned it
+func groupSummary(order []string, servers map[string][]string) string {
+	parts := make([]string, 0, len(order))
+	for _, value := range order {
+		parts = append(parts, fmt.Sprintf("%s from %s", value, strings.Join(servers[value], ", ")))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// zoneServers merges the parent and child NS sets, child servers first
+func zoneServers(parent, child []string) []ZoneServer {
+	var servers []ZoneServer
+	index := make(map[string]int)
+	for _, name := range child {
+		index[name] = len(servers)
+		servers = append(servers, ZoneServer{Name: name, InChild: true})
+	}
+	for _, name := range parent {
+		if n, ok := index[name]; ok {
+			servers[n].InParent = true
+			continue
+		}
+		servers = append(servers, ZoneServer{Name: name, InParent: true})
+	}
+	return servers
+}
+
+// nsNames returns the sorted, lower case NS targets for zone in the answer
+// and authority sections of a response
+func nsNames(r *DigResult, zone string) []string {
+	seen := make(map[string]bool)
+	names := []string{}
+	for _, section := range [][]DigRecord{r.Answer, r.Authority} {
+		for _, rec := range section {
+			if rec.Type != "NS" || !sameName(rec.Name, zone) {
+				continue
+			}
+			name := strings.ToLower(rec.Data)
+			if !strings.HasSuffix(name, ".") {
+				name += "."
+			}
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// parentZone returns the zone one label above zone, the root for a top
+// level domain
+func parentZone(zone string) string {
+	_, parent, ok := strings.Cut(strings.TrimSuffix(zone, "."), ".")
+	if !ok {
+		return "."
+	}
+	return parent + "."
+}
+
+func sameName(a, b string) bool {
+	return strings.EqualFold(strings.TrimSuffix(a, "."), strings.TrimSuffix(b, "."))
+}
+
+func hasFlag(r *DigResult, flag string) bool {
+	for _, f := range r.Flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// serverLine describes a checked server in one line
+func serverLine(s *ZoneServer, recordType string) string {
+	if s.Error != "" && s.SOAStatus == "" {
+		return fmt.Sprintf("[%s] %s", s.Name, s.Error)
+	}
+	soa := "SOA " + s.SOAStatus
+	if s.Serial != "" {
+		soa = "SOA serial " + s.Serial
+	}
+	if s.Lame {
+		soa += ", lame"
+	}
+	if s.Error != "" {
+		return fmt.Sprintf("[%s] %s, %s", s.Name, soa, s.Error)
+	}
+	auth := "authoritative"
+	if !s.Authoritative {
+		auth = "not authoritative"
+	}
+	answer := "no records"
+	if len(s.Answer) > 0 {
+		answer = strings.Join(s.Answer, ", ")
+	}
+	return fmt.Sprintf("[%s] %s; %s %s: %s (%s)", s.Name, soa, recordType, s.Status, answer, auth)
+}
+
+// zoneTable renders the summary printed at the end of a zone check
+func zoneTable(result *ZoneCheckResult) []string {
+	var b strings.Builder
+	tw := tabwriter.NewWriter(&b, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "NAMESERVER\tPARENT\tZONE\tSERIAL\tAA\t%s\n", result.RecordType)
+	for _, s := range result.Servers {
+		serial, aa, answer := s.Serial, "no", s.Status
+		switch {
+		case s.Lame:
+			aa = "lame"
+		case s.Authoritative:
+			aa = "yes"
+		}
+		if serial == "" {
+			serial = "-"
+		}
+		if s.Error != "" {
+			answer = s.Error
+		} else if len(s.Answer) > 0 {
+			answer = strings.Join(s.Answer, ", ")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", s.Name, yesNo(s.InParent), yesNo(s.InChild), serial, aa, answer)
+	}
+	tw.Flush()
+
+	lines := []string{"", fmt.Sprintf("--- zone-check %s: %d nameservers ---", result.Zone, len(result.Servers))}
+	lines = append(lines, strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")...)
+	if result.Healthy {
+		return append(lines, "no problems found")
+	}
+	lines = append(lines, fmt.Sprintf("%d problems:", len(result.Problems)))
+	for _, p := range result.Problems {
+		lines = append(lines, "  "+p.String())
+	}
+	return lines
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
@@ -0,0 +1,149 @@
+// File: backend/internal/tools/zonecheck_test.go
+package tools
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"backend/internal/validation"
+)
+
+func TestZoneCheckPrepare(t *testing.T) {
+	p := validation.DefaultPolicy()
+	p.DenyTargets = []string{"*.internal.example"}
+	v, err := validation.NewValidator(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check := NewZoneCheck(20)
+
+	tests := []struct {
+		name    string
+		params  map[string]interface{}
+		argv    []string
+		wantErr string
+	}{
+		{"Default record type", map[string]interface{}{"zone": "Example.com"}, []string{"zone-check", "example.com.", "NS"}, ""},
+		{"Record type", map[string]interface{}{"zone": "example.com.", "recordType": "mx"}, []string{"zone-check", "example.com.", "MX"}, ""},
+		{"Missing zone", map[string]interface{}{}, nil, "zone is required"},
+		{"Invalid zone", map[string]interface{}{"zone": "-example.com"}, nil, "invalid label format"},
+		{"Denied zone", map[string]interface{}{"zone": "corp.internal.example"}, nil, "not permitted"},
+		{"Bad record type", map[string]interface{}{"zone": "example.com", "recordType": "ANY"}, nil, "invalid DNS record type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inv, err := check.Prepare(v, tt.params)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Prepare() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Prepare() error = %v", err)
+			}
+			if got := inv.Argv(); !reflect.DeepEqual(got, tt.argv) {
+				t.Errorf("Argv() = %q, want %q", got, tt.argv)
+			}
+		})
+	}
+}
+
+func TestAnalyseZone(t *testing.T) {
+	ok := func(name, serial string, answer ...string) ZoneServer {
+		return ZoneServer{Name: name, InParent: true, InChild: true, Serial: serial, SOAStatus: "NOERROR", Status: "NOERROR", Answer: answer, Authoritative: true}
+	}
+
+	tests := []struct {
+		name    string
+		servers []ZoneServer
+		want    []string
+	}{
+		{
+			name:    "Healthy",
+			servers: []ZoneServer{ok("ns1.example.com.", "5", "192.0.2.1"), ok("ns2.example.com.", "5", "192.0.2.1")},
+		},
+		{
+			name:    "Serials and answers differ",
+			servers: []ZoneServer{ok("ns1.example.com.", "5", "192.0.2.1"), ok("ns2.example.com.", "4", "192.0.2.2"), ok("ns3.example.com.", "5", "192.0.2.1")},
+			want: []string{
+				"serial-mismatch: SOA serials differ: 5 from ns1.example.com., ns3.example.com.; 4 from ns2.example.com.",
+				"answer-mismatch: A answers differ: NOERROR 192.0.2.1 from ns1.example.com., ns3.example.com.; NOERROR 192.0.2.2 from ns2.example.com.",
+			},
+		},
+		{
+			name: "Lame and not authoritative",
+			servers: []ZoneServer{
+				ok("ns1.example.com.", "5", "192.0.2.1"),
+				{Name: "ns2.example.com.", InParent: true, InChild: true, SOAStatus: "REFUSED", Lame: true, Answer: []string{}},
+				{Name: "ns3.example.com.", InParent: true, InChild: true, Lame: true, Error: "connection timed out", Answer: []string{}},
+				{Name: "ns4.example.com.", InParent: true, InChild: true, Serial: "5", SOAStatus: "NOERROR", Status: "NOERROR", Answer: []string{"192.0.2.1"}},
+			},
+			want: []string{
+				"lame-delegation: ns2.example.com. answered REFUSED for example.com.",
+				"lame-delegation: ns3.example.com. connection timed out for example.com.",
+				"non-authoritative: ns4.example.com. answered example.com. A without the authoritative flag",
+			},
+		},
+		{
+			name: "NS sets differ",
+			servers: []ZoneServer{
+				ok("ns1.example.com.", "5", "192.0.2.1"),
+				{Name: "ns2.example.com.", InChild: true, Serial: "5", SOAStatus: "NOERROR", Status: "NOERROR", Answer: []string{"192.0.2.1"}, Authoritative: true},
+				{Name: "old.example.net.", InParent: true, Serial: "5", SOAStatus: "NOERROR", Status: "NOERROR", Answer: []string{"192.0.2.1"}, Authoritative: true},
+			},
+			want: []string{"ns-mismatch: NS sets differ, only in the parent: old.example.net.; only in the zone: ns2.example.com."},
+		},
+		{
+			name: "Denied server skipped",
+			servers: []ZoneServer{
+				ok("ns1.example.com.", "5", "192.0.2.1"),
+				{Name: "ns2.example.com.", InParent: true, InChild: true, Skipped: true, Error: "not permitted by policy", Answer: []string{}},
+			},
+		},
+		{
+			name: "Every server denied",
+			servers: []ZoneServer{
+				{Name: "ns1.example.com.", InParent: true, InChild: true, Skipped: true, Error: "not permitted by policy", Answer: []string{}},
+				{Name: "ns2.example.com.", InParent: true, InChild: true, Skipped: true, Error: "not permitted by policy", Answer: []string{}},
+			},
+			want: []string{"not-checked: no nameserver of example.com. is permitted by policy"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &ZoneCheckResult{Zone: "example.com.", RecordType: "A", ParentNS: []string{"ns1.example.com."}, Servers: tt.servers}
+			analyseZone(result)
+			var got []string
+			for _, p := range result.Problems {
+				got = append(got, p.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("problems = %q, want %q", got, tt.want)
+			}
+			if result.Healthy != (len(tt.want) == 0) {
+				t.Errorf("Healthy = %v with %d problems", result.Healthy, len(got))
+			}
+		})
+	}
+}
+
+func TestZoneCheckServerDenied(t *testing.T) {
+	p := validation.DefaultPolicy()
+	p.DenyTargets = []string{"*.example.com"}
+	v, err := validation.NewValidator(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inv := zoneCheckInvocation{zone: "example.com.", recordType: "A", maxServers: 20, validator: v}
+
+	s := ZoneServer{Name: "ns1.example.com.", InParent: true, InChild: true}
+	inv.checkServer(context.Background(), &s)
+	if !s.Skipped || s.Lame || s.Status != "" {
+		t.Errorf("denied server = %+v, want skipped without a query", s)
+	}
+}
@@ -11,32 +11,11 @@ import (
 const (
 	maxDomainLength = 253
 	maxLabelLength  = 63
-	maxPingCount    = 30
-	minPingCount    = 1
 )
 
 var (
 	// DNS label validation as per RFC 1035
 	labelRegex = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]*[a-zA-Z0-9])?$`)
-
-	// Valid record types
-	validRecordTypes = map[string]bool{
-		"A":     true,
-		"AAAA":  true,
-		"MX":    true,
-		"NS":    true,
-		"TXT":   true,
-		"CNAME": true,
-		"SOA":   true,
-		"PTR":   true,
-	}
-
-	// Allowed dig parameters
-	allowedDigParams = map[string]bool{
-		"short":  true,
-		"trace":  true,
-		"answer": true,
-	}
 )
 
 // ValidationError represents a validation error with a specific message
@@ -123,39 +102,20 @@ func ValidateIPv4(ip string) error {
 	return nil
 }
 
-// ValidatePingCount ensures the ping count is within allowed range
+// ValidatePingCount ensures the ping count is within the active policy's range
 func ValidatePingCount(count int) error {
-	if count < minPingCount || count > maxPingCount {
-		return &ValidationError{
-			Field:   "count",
-			Message: fmt.Sprintf("count must be between %d and %d", minPingCount, maxPingCount),
-		}
-	}
-	return nil
+	return Current().ValidatePingCount(count)
 }
 
-// ValidateRecordType checks if the DNS record type is valid
+// ValidateRecordType checks if the DNS record type is allowed by the active policy
 func ValidateRecordType(recordType string) error {
-	if !validRecordTypes[strings.ToUpper(recordType)] {
-		return &ValidationError{
-			Field:   "recordType",
-			Message: "invalid DNS record type",
-		}
-	}
-	return nil
+	return Current().ValidateRecordType(recordType)
 }
 
-// ValidateDigParameters validates additional dig command parameters
+// ValidateDigParameters validates additional dig command parameters against
+// the active policy
 func ValidateDigParameters(params map[string]interface{}) error {
-	for param := range params {
-		if !allowedDigParams[param] {
-			return &ValidationError{
-				Field:   "parameters",
-				Message: fmt.Sprintf("parameter '%s' is not allowed", param),
-			}
-		}
-	}
-	return nil
+	return Current().ValidateDigParameters(params)
 }
 
 // ValidateTarget validates either an IP address or domain name
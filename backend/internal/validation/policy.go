@@ -0,0 +1,356 @@
+// File: backend/internal/validation/policy.go
+package validation
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// resolveTimeout bounds the DNS lookup used to match hostnames against
+// address based target rules
+const resolveTimeout = 2 * time.Second
+
+// lookupIP resolves hostname targets; tests replace it
+var lookupIP = net.DefaultResolver.LookupIP
+
+// Policy holds the configurable limits enforced by the validators
+type Policy struct {
+	MinPingCount  int
+	MaxPingCount  int
+	RecordTypes   []string
+	DigParameters []string
+	// AllowTargets and DenyTargets hold IP addresses, CIDR ranges or domain
+	// names. An empty allow list permits every target that is not denied.
+	AllowTargets []string
+	DenyTargets  []string
+}
+
+// DefaultPolicy returns the limits used when no configuration is applied
+func DefaultPolicy() Policy {
+	return Policy{
+		MinPingCount:  1,
+		MaxPingCount:  30,
+		RecordTypes:   []string{"A", "AAAA", "MX", "NS", "TXT", "CNAME", "SOA", "PTR"},
+		DigParameters: []string{"short", "trace", "answer"},
+	}
+}
+
+// Validator enforces a Policy. A Validator is immutable, so a request that
+// validates all of its fields against one Validator sees a consistent policy
+// even while the active policy is being replaced.
+type Validator struct {
+	policy        Policy
+	recordTypes   map[string]bool
+	digParameters map[string]bool
+	allow         targetRules
+	deny          targetRules
+
+	// restricted is set when the Validator only grants what roles permit
+	restricted bool
+	roleNames  []string
+	roles      []*compiledRole
+	// granting holds the roles that permitted every check made so far
+	granting *roleSet
+}
+
+// targetRules matches targets against address ranges and domain suffixes
+type targetRules struct {
+	nets    []*net.IPNet
+	domains []string
+}
+
+var active atomic.Pointer[Validator]
+
+func init() {
+	if err := SetPolicy(DefaultPolicy()); err != nil {
+		panic(err)
+	}
+}
+
+// SetPolicy atomically replaces the policy used by the package level
+// validation functions
+func SetPolicy(p Policy) error {
+	v, err := NewValidator(p)
+	if err != nil {
+		return err
+	}
+	SetCurrent(v)
+	return nil
+}
+
+// SetCurrent atomically replaces the Validator used by the package level
+// validation functions
+func SetCurrent(v *Validator) {
+	active.Store(v)
+}
+
+// Current returns the Validator enforcing the active policy
+func Current() *Validator {
+	return active.Load()
+}
+
+// NewValidator compiles a policy into a Validator
+func NewValidator(p Policy) (*Validator, error) {
+	v := &Validator{
+		policy:        p,
+		recordTypes:   make(map[string]bool),
+		digParameters: make(map[string]bool),
+	}
+	for _, t := range p.RecordTypes {
+		v.recordTypes[strings.ToUpper(t)] = true
+	}
+	for _, param := range p.DigParameters {
+		v.digParameters[param] = true
+	}
+
+	var err error
+	if v.allow, err = compileTargetRules(p.AllowTargets); err != nil {
+		return nil, err
+	}
+	if v.deny, err = compileTargetRules(p.DenyTargets); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func compileTargetRules(entries []string) (targetRules, error) {
+	var rules targetRules
+	for _, entry := range entries {
+		if ip := net.ParseIP(entry); ip != nil {
+			bits := 32
+			if ip.To4() == nil {
+				bits = 128
+			}
+			rules.nets = append(rules.nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		if _, ipNet, err := net.ParseCIDR(entry); err == nil {
+			rules.nets = append(rules.nets, ipNet)
+			continue
+		}
+		domain := strings.ToLower(strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(entry, "*"), "."), "."))
+		if domain == "" {
+			return targetRules{}, fmt.Errorf("invalid target rule %q", entry)
+		}
+		rules.domains = append(rules.domains, domain)
+	}
+	return rules, nil
+}
+
+func (r targetRules) empty() bool {
+	return len(r.nets) == 0 && len(r.domains) == 0
+}
+
+func (r targetRules) matchDomain(name string) bool {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	for _, d := range r.domains {
+		if name == d || strings.HasSuffix(name, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r targetRules) matchIP(ip net.IP) bool {
+	for _, n := range r.nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Policy returns the policy the Validator enforces
+func (v *Validator) Policy() Policy {
+	return v.policy
+}
+
+// ValidatePingCount ensures the ping count is within allowed range
+func (v *Validator) ValidatePingCount(count int) error {
+	if count < v.policy.MinPingCount || count > v.policy.MaxPingCount {
+		return &ValidationError{
+			Field:   "count",
+			Message: fmt.Sprintf("count must be between %d and %d", v.policy.MinPingCount, v.policy.MaxPingCount),
+		}
+	}
+	if !v.grants(func(r *compiledRole) bool { return r.MaxPingCount == 0 || count <= r.MaxPingCount }) {
+		return v.forbidden(fmt.Sprintf("ping.count:%d", count))
+	}
+	return nil
+}
+
+// ValidateRecordType checks if the DNS record type is allowed
+func (v *Validator) ValidateRecordType(recordType string) error {
+	recordType = strings.ToUpper(recordType)
+	if !v.recordTypes[recordType] {
+		return &ValidationError{
+			Field:   "recordType",
+			Message: "invalid DNS record type",
+		}
+	}
+	if !v.grants(func(r *compiledRole) bool { return r.recordTypes == nil || r.recordTypes[recordType] }) {
+		return v.forbidden("dig.recordType:" + recordType)
+	}
+	return nil
+}
+
+// ValidateDigParameters validates additional dig command parameters
+func (v *Validator) ValidateDigParameters(params map[string]interface{}) error {
+	for param := range params {
+		if !v.digParameters[param] {
+			return &ValidationError{
+				Field:   "parameters",
+				Message: fmt.Sprintf("parameter '%s' is not allowed", param),
+			}
+		}
+		if !v.grants(func(r *compiledRole) bool { return r.digParameters.has(param) }) {
+			return v.forbidden("dig.parameter:" + param)
+		}
+	}
+	return nil
+}
+
+// CheckTargetPolicy reports whether the target policy permits a tool to be
+// pointed at target using the active policy
+func CheckTargetPolicy(field, target string) error {
+	return Current().CheckTarget(field, target)
+}
+
+// CheckTarget reports whether the policy permits a tool to be pointed at
+// target. Hostnames are resolved when address rules are configured, and every
+// resolved address must be permitted; a hostname that does not resolve then
+// is refused. When the Validator is restricted to roles, one of them must
+// also permit the target.
+func (v *Validator) CheckTarget(field, target string) error {
+	_, err := v.ResolveTarget(field, target)
+	return err
+}
+
+// ResolveTarget checks target like CheckTarget and returns the address the
+// tool should contact. When address rules apply, a hostname is replaced by
+// one of the addresses it was checked with, so that the tool's own lookup
+// cannot be answered with a denied address. Otherwise target is returned.
+func (v *Validator) ResolveTarget(field, target string) (string, error) {
+	needIPs := len(v.allow.nets) > 0 || len(v.deny.nets) > 0
+	for _, r := range v.roles {
+		needIPs = needIPs || len(r.allow.nets) > 0 || len(r.deny.nets) > 0
+	}
+	t := resolveTarget(target, needIPs)
+	if needIPs && len(t.ips) == 0 {
+		return "", &ValidationError{Field: field, Message: fmt.Sprintf("target %s could not be resolved", target)}
+	}
+
+	if !t.permittedBy(v.allow, v.deny) {
+		return "", &ValidationError{Field: field, Message: fmt.Sprintf("target %s is not permitted by policy", target)}
+	}
+	if v.restricted && !v.grants(func(r *compiledRole) bool { return t.permittedBy(r.allow, r.deny) }) {
+		return "", v.forbidden("target:" + target)
+	}
+	return t.address(), nil
+}
+
+// CheckRange reports whether the policy permits a tool to be pointed at
+// every address in n. The range must not overlap a denied range and, when
+// there is an allow list, must lie within a single allowed range. When the
+// Validator is restricted to roles, one of them must also permit the range.
+func (v *Validator) CheckRange(field string, n *net.IPNet) error {
+	if !rangePermittedBy(n, v.allow, v.deny) {
+		return &ValidationError{Field: field, Message: fmt.Sprintf("range %s is not permitted by policy", n)}
+	}
+	if !v.grants(func(r *compiledRole) bool { return rangePermittedBy(n, r.allow, r.deny) }) {
+		return v.forbidden("target:" + n.String())
+	}
+	return nil
+}
+
+// rangePermittedBy reports whether every address in n passes an allow and
+// deny list. Domain rules never match a range.
+func rangePermittedBy(n *net.IPNet, allow, deny targetRules) bool {
+	for _, d := range deny.nets {
+		if d.Contains(n.IP) || n.Contains(d.IP) {
+			return false
+		}
+	}
+	if allow.empty() {
+		return true
+	}
+	ones, bits := n.Mask.Size()
+	for _, a := range allow.nets {
+		aOnes, aBits := a.Mask.Size()
+		if aBits == bits && aOnes <= ones && a.Contains(n.IP) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolvedTarget is a target with the addresses it resolved to
+type resolvedTarget struct {
+	name     string
+	ips      []net.IP
+	isDomain bool
+}
+
+// resolveTarget looks up the addresses of a hostname target if needIPs is
+// set, i.e. when address based rules have to be matched
+func resolveTarget(target string, needIPs bool) resolvedTarget {
+	t := resolvedTarget{name: target, isDomain: true}
+	if ip := net.ParseIP(target); ip != nil {
+		t.ips = []net.IP{ip}
+		t.isDomain = false
+	} else if needIPs {
+		ctx, cancel := context.WithTimeout(context.Background(), resolveTimeout)
+		addrs, err := lookupIP(ctx, "ip", target)
+		cancel()
+		if err == nil {
+			t.ips = addrs
+		}
+	}
+	return t
+}
+
+// address returns the address to contact: the target itself for addresses
+// and unresolved hostnames, otherwise a resolved address, IPv4 preferred
+func (t resolvedTarget) address() string {
+	if !t.isDomain || len(t.ips) == 0 {
+		return t.name
+	}
+	for _, ip := range t.ips {
+		if ip.To4() != nil {
+			return ip.String()
+		}
+	}
+	return t.ips[0].String()
+}
+
+// permittedBy reports whether the target passes an allow and deny list
+func (t resolvedTarget) permittedBy(allow, deny targetRules) bool {
+	if t.isDomain && deny.matchDomain(t.name) {
+		return false
+	}
+	for _, ip := range t.ips {
+		if deny.matchIP(ip) {
+			return false
+		}
+	}
+
+	if allow.empty() {
+		return true
+	}
+	if t.isDomain && allow.matchDomain(t.name) {
+		return true
+	}
+	if len(t.ips) == 0 {
+		return false
+	}
+	for _, ip := range t.ips {
+		if !allow.matchIP(ip) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,94 @@
+// File: backend/internal/validation/policy_test.go
+package validation
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+)
+
+func TestCheckRange(t *testing.T) {
+	p := DefaultPolicy()
+	p.AllowTargets = []string{"10.0.0.0/16", "192.0.2.10", "example.com"}
+	p.DenyTargets = []string{"10.0.5.0/24"}
+	v, err := NewValidator(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		cidr    string
+		wantErr bool
+	}{
+		{"Inside allowed range", "10.0.1.0/24", false},
+		{"Whole allowed range", "10.0.0.0/16", true},
+		{"Single allowed address", "192.0.2.10/32", false},
+		{"Denied subnet", "10.0.5.0/28", true},
+		{"Wider than allowed range", "10.0.0.0/15", true},
+		{"Outside allowed ranges", "198.51.100.0/24", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, n, err := net.ParseCIDR(tt.cidr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := v.CheckRange("cidr", n); (err != nil) != tt.wantErr {
+				t.Errorf("CheckRange(%s) error = %v, wantErr %v", tt.cidr, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestResolveTarget(t *testing.T) {
+	lookupIP = func(_ context.Context, _, host string) ([]net.IP, error) {
+		switch host {
+		case "www.example.com":
+			return []net.IP{net.ParseIP("2001:db8::1"), net.ParseIP("192.0.2.1")}, nil
+		case "internal.example.com":
+			return []net.IP{net.ParseIP("10.0.0.1")}, nil
+		}
+		return nil, errors.New("no such host")
+	}
+	defer func() { lookupIP = net.DefaultResolver.LookupIP }()
+
+	p := DefaultPolicy()
+	p.DenyTargets = []string{"10.0.0.0/8"}
+	v, err := NewValidator(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unrestricted, err := NewValidator(DefaultPolicy())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		v       *Validator
+		target  string
+		want    string
+		wantErr bool
+	}{
+		{"Address", v, "192.0.2.7", "192.0.2.7", false},
+		{"Hostname pinned to IPv4", v, "www.example.com", "192.0.2.1", false},
+		{"Hostname resolving to denied address", v, "internal.example.com", "", true},
+		{"Unresolvable hostname", v, "missing.example.com", "", true},
+		{"No address rules", unrestricted, "missing.example.com", "missing.example.com", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.v.ResolveTarget("target", tt.target)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveTarget(%s) error = %v, wantErr %v", tt.target, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ResolveTarget(%s) = %q, want %q", tt.target, got, tt.want)
+			}
+		})
+	}
+}
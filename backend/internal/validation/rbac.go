@@ -0,0 +1,213 @@
+// File: backend/internal/validation/rbac.go
+package validation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Wildcard grants every tool or dig parameter in a Role
+const Wildcard = "*"
+
+// ForbiddenError is returned when none of a client's roles grants the
+// permission a request needs
+type ForbiddenError struct {
+	// Permission names what is missing, e.g. "tool:dig" or
+	// "dig.parameter:trace"
+	Permission string
+	Roles      []string
+}
+
+func (e *ForbiddenError) Error() string {
+	if len(e.Roles) == 0 {
+		return fmt.Sprintf("forbidden: missing permission %s (no roles assigned)", e.Permission)
+	}
+	return fmt.Sprintf("forbidden: missing permission %s (roles: %s)", e.Permission, strings.Join(e.Roles, ", "))
+}
+
+// Role is a named set of permissions. A client holding several roles may run
+// any job that at least one of them permits as a whole; permissions of
+// different roles are not combined within a job.
+type Role struct {
+	Name string
+	// Tools lists the tools the role may run, or Wildcard for all
+	Tools []string
+	// MaxPingCount caps the ping count; 0 leaves only the policy limit
+	MaxPingCount int
+	// RecordTypes limits dig record types; empty permits every type the
+	// policy allows
+	RecordTypes []string
+	// DigParameters lists the dig options the role may use, or Wildcard
+	DigParameters []string
+	// AllowTargets and DenyTargets restrict targets further than the policy
+	AllowTargets []string
+	DenyTargets  []string
+}
+
+// nameSet is a set of names that may contain Wildcard
+type nameSet map[string]bool
+
+func newNameSet(names []string) nameSet {
+	s := make(nameSet, len(names))
+	for _, name := range names {
+		s[name] = true
+	}
+	return s
+}
+
+func (s nameSet) has(name string) bool {
+	return s[Wildcard] || s[name]
+}
+
+// compiledRole is a Role prepared for matching
+type compiledRole struct {
+	Role
+	tools         nameSet
+	recordTypes   map[string]bool
+	digParameters nameSet
+	allow         targetRules
+	deny          targetRules
+}
+
+// RBAC assigns roles to client identities
+type RBAC struct {
+	roles        map[string]*compiledRole
+	defaultRoles []string
+	assignments  map[string][]string
+}
+
+// NewRBAC compiles role definitions. Identities without an assignment, and
+// anonymous clients, get defaultRoles. Every role named in defaultRoles or
+// assignments must be defined.
+func NewRBAC(roles []Role, defaultRoles []string, assignments map[string][]string) (*RBAC, error) {
+	r := &RBAC{
+		roles:        make(map[string]*compiledRole, len(roles)),
+		defaultRoles: defaultRoles,
+		assignments:  assignments,
+	}
+	for _, role := range roles {
+		c := &compiledRole{
+			Role:          role,
+			tools:         newNameSet(role.Tools),
+			digParameters: newNameSet(role.DigParameters),
+		}
+		if len(role.RecordTypes) > 0 {
+			c.recordTypes = make(map[string]bool)
+			for _, t := range role.RecordTypes {
+				c.recordTypes[strings.ToUpper(t)] = true
+			}
+		}
+		var err error
+		if c.allow, err = compileTargetRules(role.AllowTargets); err != nil {
+			return nil, fmt.Errorf("role %s: %w", role.Name, err)
+		}
+		if c.deny, err = compileTargetRules(role.DenyTargets); err != nil {
+			return nil, fmt.Errorf("role %s: %w", role.Name, err)
+		}
+		r.roles[role.Name] = c
+	}
+
+	for _, name := range defaultRoles {
+		if r.roles[name] == nil {
+			return nil, fmt.Errorf("default role %s is not defined", name)
+		}
+	}
+	for identity, names := range assignments {
+		for _, name := range names {
+			if r.roles[name] == nil {
+				return nil, fmt.Errorf("role %s assigned to %s is not defined", name, identity)
+			}
+		}
+	}
+	return r, nil
+}
+
+// RolesFor returns the sorted names of the roles held by identity: those
+// assigned to it plus any defined roles its credentials claim, or the
+// default roles if there are none
+func (r *RBAC) RolesFor(identity string, claimed []string) []string {
+	seen := make(map[string]bool)
+	var names []string
+	add := func(name string) {
+		if r.roles[name] != nil && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	if identity != "" {
+		for _, name := range r.assignments[identity] {
+			add(name)
+		}
+	}
+	for _, name := range claimed {
+		add(name)
+	}
+	if len(names) == 0 {
+		for _, name := range r.defaultRoles {
+			add(name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WithRoles returns a copy of v that additionally requires every tool,
+// option and target to be permitted by one of the named roles. The copy is
+// meant for a single job: every check it passes narrows the roles to those
+// that permitted it, so the whole job must be permitted by one role.
+func (v *Validator) WithRoles(r *RBAC, names []string) *Validator {
+	restricted := *v
+	restricted.restricted = true
+	restricted.roleNames = names
+	restricted.roles = nil
+	for _, name := range names {
+		if role := r.roles[name]; role != nil {
+			restricted.roles = append(restricted.roles, role)
+		}
+	}
+	restricted.granting = &roleSet{roles: restricted.roles}
+	return &restricted
+}
+
+// roleSet is the set of roles still able to permit a job. Tools may check
+// targets from several goroutines, hence the lock.
+type roleSet struct {
+	mu    sync.Mutex
+	roles []*compiledRole
+}
+
+// CheckTool reports whether the client's roles permit running tool
+func (v *Validator) CheckTool(tool string) error {
+	if !v.grants(func(r *compiledRole) bool { return r.tools.has(tool) }) {
+		return v.forbidden("tool:" + tool)
+	}
+	return nil
+}
+
+// grants reports whether an unrestricted Validator is in use or one of the
+// roles that permitted the earlier checks satisfies ok. Those that do not are
+// left out of later checks.
+func (v *Validator) grants(ok func(*compiledRole) bool) bool {
+	if !v.restricted {
+		return true
+	}
+	v.granting.mu.Lock()
+	defer v.granting.mu.Unlock()
+	var kept []*compiledRole
+	for _, r := range v.granting.roles {
+		if ok(r) {
+			kept = append(kept, r)
+		}
+	}
+	if len(kept) == 0 {
+		return false
+	}
+	v.granting.roles = kept
+	return true
+}
+
+func (v *Validator) forbidden(permission string) error {
+	return &ForbiddenError{Permission: permission, Roles: v.roleNames}
+}
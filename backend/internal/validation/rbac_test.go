@@ -0,0 +1,101 @@
+package validation
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestRBAC(t *testing.T) {
+	rbac, err := NewRBAC([]Role{
+		{Name: "viewer", Tools: []string{"ping"}, MaxPingCount: 5, DenyTargets: []string{"10.0.0.0/8"}},
+		{Name: "operator", Tools: []string{"ping", "dig"}, RecordTypes: []string{"A", "AAAA"}, DigParameters: []string{"short"}},
+		{Name: "admin", Tools: []string{Wildcard}, DigParameters: []string{Wildcard}},
+		{Name: "internal", Tools: []string{"ping"}, MaxPingCount: 5, AllowTargets: []string{"10.0.0.0/8"}},
+		{Name: "external", Tools: []string{"ping"}, DenyTargets: []string{"10.0.0.0/8"}},
+	}, []string{"viewer"}, map[string][]string{
+		"apikey:ops":  {"operator"},
+		"jwt:root":    {"admin"},
+		"basic:mixed": {"viewer", "operator"},
+		"basic:split": {"internal", "external"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := rbac.RolesFor("", nil); !reflect.DeepEqual(got, []string{"viewer"}) {
+		t.Errorf("anonymous roles = %v, want [viewer]", got)
+	}
+	if got := rbac.RolesFor("jwt:someone", []string{"operator", "unknown"}); !reflect.DeepEqual(got, []string{"operator"}) {
+		t.Errorf("claimed roles = %v, want [operator]", got)
+	}
+
+	base, err := NewValidator(DefaultPolicy())
+	if err != nil {
+		t.Fatal(err)
+	}
+	forRoles := func(identity string) *Validator {
+		return base.WithRoles(rbac, rbac.RolesFor(identity, nil))
+	}
+
+	tests := []struct {
+		name           string
+		identity       string
+		check          func(v *Validator) error
+		wantPermission string
+	}{
+		{"Viewer pings", "", func(v *Validator) error { return v.CheckTool("ping") }, ""},
+		{"Viewer cannot dig", "", func(v *Validator) error { return v.CheckTool("dig") }, "tool:dig"},
+		{"Viewer count limit", "", func(v *Validator) error { return v.ValidatePingCount(10) }, "ping.count:10"},
+		{"Viewer internal target", "", func(v *Validator) error { return v.CheckTarget("target", "10.1.2.3") }, "target:10.1.2.3"},
+		{"Operator count", "apikey:ops", func(v *Validator) error { return v.ValidatePingCount(30) }, ""},
+		{"Operator internal target", "apikey:ops", func(v *Validator) error { return v.CheckTarget("target", "10.1.2.3") }, ""},
+		{"Operator record type", "apikey:ops", func(v *Validator) error { return v.ValidateRecordType("MX") }, "dig.recordType:MX"},
+		{"Operator trace", "apikey:ops", func(v *Validator) error {
+			return v.ValidateDigParameters(map[string]interface{}{"trace": true})
+		}, "dig.parameter:trace"},
+		{"Admin trace", "jwt:root", func(v *Validator) error {
+			return v.ValidateDigParameters(map[string]interface{}{"trace": true})
+		}, ""},
+		{"Union of roles", "basic:mixed", func(v *Validator) error { return v.CheckTool("dig") }, ""},
+		{"One role permits the job", "basic:split", func(v *Validator) error {
+			if err := v.CheckTarget("target", "10.1.2.3"); err != nil {
+				return err
+			}
+			return v.ValidatePingCount(5)
+		}, ""},
+		{"Roles are not combined", "basic:split", func(v *Validator) error {
+			if err := v.CheckTarget("target", "10.1.2.3"); err != nil {
+				return err
+			}
+			return v.ValidatePingCount(20)
+		}, "ping.count:20"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.check(forRoles(tt.identity))
+			var forbidden *ForbiddenError
+			switch {
+			case tt.wantPermission == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tt.wantPermission != "" && !errors.As(err, &forbidden):
+				t.Errorf("error = %v, want ForbiddenError", err)
+			case tt.wantPermission != "" && forbidden.Permission != tt.wantPermission:
+				t.Errorf("Permission = %q, want %q", forbidden.Permission, tt.wantPermission)
+			}
+		})
+	}
+
+	// Policy limits still apply before roles are considered
+	var valErr *ValidationError
+	if err := forRoles("jwt:root").ValidatePingCount(100); !errors.As(err, &valErr) {
+		t.Errorf("ValidatePingCount(100) = %v, want ValidationError", err)
+	}
+}
+
+func TestNewRBACUndefinedRole(t *testing.T) {
+	if _, err := NewRBAC(nil, []string{"missing"}, nil); err == nil {
+		t.Error("expected an error for an undefined default role")
+	}
+}
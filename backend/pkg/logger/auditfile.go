@@ -0,0 +1,455 @@
+// File: backend/pkg/logger/auditfile.go
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	auditFilePrefix = "audit-"
+	auditFileSuffix = ".log"
+
+	// auditHeadFile records the sequence number and hash of the newest
+	// record, so that records removed from the end of the chain are noticed
+	auditHeadFile = "audit-head.json"
+
+	// genesisHash is the previous hash of the very first record
+	genesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+	// hashField is appended last to every record so the hashed body can be
+	// recovered byte for byte when verifying
+	hashField = `,"hash":"`
+)
+
+// AuditFileOptions controls rotation and retention of audit files
+type AuditFileOptions struct {
+	// MaxSize rotates the file once it reaches this many bytes
+	MaxSize int64
+	// RotateEvery rotates the file once it has been open this long
+	RotateEvery time.Duration
+	// MaxFiles removes the oldest files beyond this count
+	MaxFiles int
+	// MaxAge removes files last written longer ago than this
+	MaxAge time.Duration
+}
+
+// AuditFile is a writer for JSON log records that appends each record to
+// size and time rotated files in a directory. Every record is extended with
+// a sequence number, the hash of the previous record and its own SHA-256
+// hash, forming a chain that VerifyAuditDir can check for gaps or edits. The
+// newest record's sequence number and hash are also kept in a head file.
+type AuditFile struct {
+	dir  string
+	opts AuditFileOptions
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+	seq      uint64
+	lastHash string
+}
+
+// chainHead is the content of the head file
+type chainHead struct {
+	Seq  uint64 `json:"seq"`
+	Hash string `json:"hash"`
+}
+
+// chainFields are the fields added to every record
+type chainFields struct {
+	Seq  uint64 `json:"seq"`
+	Prev string `json:"prevHash"`
+	Hash string `json:"hash"`
+}
+
+// OpenAuditFile opens the audit directory, continuing the hash chain from the
+// newest existing file, or from the head file when records were removed from
+// the end so that verification reports them missing
+func OpenAuditFile(dir string, opts AuditFileOptions) (*AuditFile, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+	a := &AuditFile{dir: dir, opts: opts, lastHash: genesisHash}
+
+	files, err := auditFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) > 0 {
+		latest := files[len(files)-1]
+		last, err := lastRecord(latest)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", latest, err)
+		}
+		if last != nil {
+			a.seq = last.Seq
+			a.lastHash = last.Hash
+		}
+		f, err := os.OpenFile(latest, os.O_WRONLY|os.O_APPEND, 0o640)
+		if err != nil {
+			return nil, err
+		}
+		info, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		a.file, a.size, a.openedAt = f, info.Size(), time.Now()
+	}
+
+	head, err := readHead(dir)
+	if err != nil {
+		if a.file != nil {
+			a.file.Close()
+		}
+		return nil, err
+	}
+	if head != nil && head.Seq > a.seq {
+		a.seq, a.lastHash = head.Seq, head.Hash
+	}
+	return a, nil
+}
+
+// Write appends one JSON record. p must hold a single JSON object, optionally
+// followed by a newline, as produced by slog.JSONHandler.
+func (a *AuditFile) Write(p []byte) (int, error) {
+	record := bytes.TrimRight(p, "\n")
+	if len(record) < 2 || record[0] != '{' || record[len(record)-1] != '}' {
+		return 0, errors.New("audit record is not a JSON object")
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := a.rotateIfNeeded(); err != nil {
+		return 0, err
+	}
+
+	seq := a.seq + 1
+	var line bytes.Buffer
+	line.Write(record[:len(record)-1])
+	if len(record) > 2 {
+		line.WriteByte(',')
+	}
+	fmt.Fprintf(&line, `"seq":%d,"prevHash":"%s"`, seq, a.lastHash)
+	hash := recordHash(line.Bytes())
+	fmt.Fprintf(&line, `%s%s"}`+"\n", hashField, hash)
+
+	n, err := a.file.Write(line.Bytes())
+	a.size += int64(n)
+	if err != nil {
+		return 0, err
+	}
+	if err := a.file.Sync(); err != nil {
+		return 0, err
+	}
+	a.seq = seq
+	a.lastHash = hash
+	if err := writeHead(a.dir, chainHead{Seq: seq, Hash: hash}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close closes the current file
+func (a *AuditFile) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.file == nil {
+		return nil
+	}
+	err := a.file.Close()
+	a.file = nil
+	return err
+}
+
+// rotateIfNeeded starts a new file when the current one is full or too old.
+// The caller must hold a.mu.
+func (a *AuditFile) rotateIfNeeded() error {
+	if a.file != nil {
+		full := a.opts.MaxSize > 0 && a.size >= a.opts.MaxSize
+		old := a.opts.RotateEvery > 0 && time.Since(a.openedAt) >= a.opts.RotateEvery
+		if !full && !old {
+			return nil
+		}
+		if err := a.file.Close(); err != nil {
+			return err
+		}
+		a.file = nil
+	}
+
+	// Files are named after their first sequence number so they sort in
+	// chain order
+	name := filepath.Join(a.dir, fmt.Sprintf("%s%012d%s", auditFilePrefix, a.seq+1, auditFileSuffix))
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o640)
+	if err != nil {
+		return err
+	}
+	a.file, a.size, a.openedAt = f, 0, time.Now()
+	return a.prune(name)
+}
+
+// prune removes files beyond the retention limits, never the current one
+func (a *AuditFile) prune(current string) error {
+	files, err := auditFiles(a.dir)
+	if err != nil {
+		return err
+	}
+	cutoff := time.Now().Add(-a.opts.MaxAge)
+	for i, name := range files {
+		if name == current {
+			continue
+		}
+		remove := a.opts.MaxFiles > 0 && len(files)-i > a.opts.MaxFiles
+		if !remove && a.opts.MaxAge > 0 {
+			if info, err := os.Stat(name); err == nil && info.ModTime().Before(cutoff) {
+				remove = true
+			}
+		}
+		if remove {
+			if err := os.Remove(name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// auditFiles returns the audit files in dir in chain order
+func auditFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasPrefix(e.Name(), auditFilePrefix) && strings.HasSuffix(e.Name(), auditFileSuffix) {
+			files = append(files, filepath.Join(dir, e.Name()))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// lastRecord returns the chain fields of the final record in a file
+func lastRecord(name string) (*chainFields, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var last []byte
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) > 0 {
+			last = append(last[:0], scanner.Bytes()...)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if last == nil {
+		return nil, nil
+	}
+	var fields chainFields
+	if err := json.Unmarshal(last, &fields); err != nil {
+		return nil, err
+	}
+	return &fields, nil
+}
+
+// readHead returns the chain head recorded in dir, nil when there is none
+func readHead(dir string) (*chainHead, error) {
+	data, err := os.ReadFile(filepath.Join(dir, auditHeadFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var head chainHead
+	if err := json.Unmarshal(data, &head); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", auditHeadFile, err)
+	}
+	return &head, nil
+}
+
+// writeHead replaces the head file, going through a temporary file so that
+// it is never left half written
+func writeHead(dir string, head chainHead) error {
+	data, err := json.Marshal(head)
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(dir, auditHeadFile+".tmp")
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o640)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(dir, auditHeadFile))
+}
+
+func recordHash(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// VerifyIssue describes a broken link in the audit chain
+type VerifyIssue struct {
+	File    string
+	Line    int
+	Seq     uint64
+	Problem string
+}
+
+func (i VerifyIssue) String() string {
+	return fmt.Sprintf("%s:%d (seq %d): %s", i.File, i.Line, i.Seq, i.Problem)
+}
+
+// VerifyReport summarises a verification run
+type VerifyReport struct {
+	Files    int
+	Records  int
+	FirstSeq uint64
+	LastSeq  uint64
+	Issues   []VerifyIssue
+}
+
+// VerifyAuditDir walks every audit file in dir in order, recomputing each
+// record's hash and checking that sequence numbers and previous hashes link
+// up, and that the chain ends with the record named by the head file.
+// Removing the oldest files is accepted as retention, so the chain may start
+// at any sequence number; everything after the first record must be
+// continuous, and records removed from the end are reported as missing.
+func VerifyAuditDir(dir string) (*VerifyReport, error) {
+	files, err := auditFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	head, err := readHead(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &VerifyReport{Files: len(files)}
+	chain := chainState{}
+	if head != nil {
+		chain.headSeq = head.Seq
+	}
+	for _, name := range files {
+		if err := verifyFile(name, report, &chain); err != nil {
+			return nil, err
+		}
+	}
+
+	headIssue := func(seq uint64, format string, args ...interface{}) {
+		report.Issues = append(report.Issues, VerifyIssue{
+			File: filepath.Join(dir, auditHeadFile), Seq: seq, Problem: fmt.Sprintf(format, args...),
+		})
+	}
+	switch {
+	case head == nil && report.Records > 0:
+		headIssue(report.LastSeq, "head file is missing, records removed from the end cannot be detected")
+	case head == nil:
+	case head.Seq > report.LastSeq:
+		headIssue(head.Seq, "records %d-%d are missing from the end of the chain", report.LastSeq+1, head.Seq)
+	case chain.headHash != head.Hash:
+		headIssue(head.Seq, "head hash does not match record %d", head.Seq)
+	}
+	return report, nil
+}
+
+// chainState carries the chain across the files being verified
+type chainState struct {
+	prevSeq  uint64
+	prevHash string
+	// headSeq is the sequence number in the head file and headHash the
+	// hash of the record found with it
+	headSeq  uint64
+	headHash string
+}
+
+func verifyFile(name string, report *VerifyReport, chain *chainState) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		raw := scanner.Bytes()
+		if len(bytes.TrimSpace(raw)) == 0 {
+			continue
+		}
+		report.Records++
+
+		issue := func(seq uint64, format string, args ...interface{}) {
+			report.Issues = append(report.Issues, VerifyIssue{
+				File: name, Line: line, Seq: seq, Problem: fmt.Sprintf(format, args...),
+			})
+		}
+
+		var fields chainFields
+		if err := json.Unmarshal(raw, &fields); err != nil {
+			issue(0, "record is not valid JSON")
+			continue
+		}
+
+		idx := bytes.LastIndex(raw, []byte(hashField))
+		if idx < 0 || recordHash(raw[:idx]) != fields.Hash {
+			issue(fields.Seq, "hash mismatch, record was modified")
+		}
+
+		if report.FirstSeq == 0 {
+			report.FirstSeq = fields.Seq
+			if fields.Seq == 1 && fields.Prev != genesisHash {
+				issue(fields.Seq, "first record does not start the chain")
+			}
+		} else {
+			if fields.Seq != chain.prevSeq+1 {
+				issue(fields.Seq, "sequence gap, expected %d", chain.prevSeq+1)
+			}
+			if fields.Prev != chain.prevHash {
+				issue(fields.Seq, "previous hash does not match the preceding record")
+			}
+		}
+
+		if fields.Seq == chain.headSeq {
+			chain.headHash = fields.Hash
+		}
+		chain.prevSeq = fields.Seq
+		chain.prevHash = fields.Hash
+		report.LastSeq = fields.Seq
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,160 @@
+package logger
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeAuditRecords(t *testing.T, dir string, opts AuditFileOptions, n int) {
+	t.Helper()
+	f, err := OpenAuditFile(dir, opts)
+	if err != nil {
+		t.Fatalf("OpenAuditFile: %v", err)
+	}
+	defer f.Close()
+	l := NewWithWriters(f, &bytes.Buffer{})
+	for i := 0; i < n; i++ {
+		l.LogCommand(CommandRecord{Event: EventStarted, Tool: "ping", Argv: []string{"ping", "-c", "4", "example.com"}, Decision: DecisionAllowed})
+	}
+}
+
+func TestAuditFileVerify(t *testing.T) {
+	dir := t.TempDir()
+	opts := AuditFileOptions{MaxSize: 1024}
+
+	writeAuditRecords(t, dir, opts, 10)
+	// Reopening continues the chain
+	writeAuditRecords(t, dir, opts, 10)
+
+	report, err := VerifyAuditDir(dir)
+	if err != nil {
+		t.Fatalf("VerifyAuditDir: %v", err)
+	}
+	if report.Records != 20 || report.FirstSeq != 1 || report.LastSeq != 20 {
+		t.Errorf("report = %+v, want 20 records with seq 1-20", report)
+	}
+	if report.Files < 2 {
+		t.Errorf("Files = %d, expected rotation by size", report.Files)
+	}
+	if len(report.Issues) != 0 {
+		t.Errorf("unexpected issues: %v", report.Issues)
+	}
+}
+
+func TestAuditFileRetention(t *testing.T) {
+	dir := t.TempDir()
+	writeAuditRecords(t, dir, AuditFileOptions{MaxSize: 512, MaxFiles: 2}, 20)
+
+	files, err := auditFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("got %d files, want 2", len(files))
+	}
+	report, err := VerifyAuditDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.FirstSeq == 1 || report.LastSeq != 20 || len(report.Issues) != 0 {
+		t.Errorf("report = %+v, want a continuous chain ending at 20", report)
+	}
+}
+
+func TestAuditFileNewestFileDeleted(t *testing.T) {
+	dir := t.TempDir()
+	opts := AuditFileOptions{MaxSize: 512}
+	writeAuditRecords(t, dir, opts, 10)
+
+	files, err := auditFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(files[len(files)-1]); err != nil {
+		t.Fatal(err)
+	}
+	// Writing on continues from the head, leaving a gap where the file was
+	writeAuditRecords(t, dir, opts, 2)
+
+	report, err := VerifyAuditDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.LastSeq != 12 || len(report.Issues) == 0 || report.Issues[0].Seq != 11 || !strings.Contains(report.Issues[0].Problem, "sequence gap") {
+		t.Errorf("report = %+v, want a sequence gap at seq 11", report)
+	}
+}
+
+func TestAuditFileTampering(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(lines []string) []string
+		want   string
+	}{
+		{
+			name: "Modified record",
+			tamper: func(lines []string) []string {
+				lines[3] = strings.Replace(lines[3], "example.com", "example.org", 2)
+				return lines
+			},
+			want: "hash mismatch",
+		},
+		{
+			name: "Deleted record",
+			tamper: func(lines []string) []string {
+				return append(lines[:3], lines[4:]...)
+			},
+			want: "sequence gap",
+		},
+		{
+			name: "Deleted newest records",
+			tamper: func(lines []string) []string {
+				return lines[:4]
+			},
+			want: "records 5-6 are missing",
+		},
+		{
+			name: "Reordered records",
+			tamper: func(lines []string) []string {
+				lines[2], lines[3] = lines[3], lines[2]
+				return lines
+			},
+			want: "previous hash",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeAuditRecords(t, dir, AuditFileOptions{}, 6)
+
+			name := filepath.Join(dir, "audit-000000000001.log")
+			data, err := os.ReadFile(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+			lines = tt.tamper(lines)
+			if err := os.WriteFile(name, []byte(strings.Join(lines, "\n")+"\n"), 0o640); err != nil {
+				t.Fatal(err)
+			}
+
+			report, err := VerifyAuditDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			found := false
+			for _, issue := range report.Issues {
+				if strings.Contains(issue.Problem, tt.want) {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("issues %v do not report %q", report.Issues, tt.want)
+			}
+		})
+	}
+}
@@ -2,26 +2,120 @@
 package logger
 
 import (
-	"log"
+	"context"
+	"io"
+	"log/slog"
 	"os"
+	"strings"
+	"time"
 )
 
+// Command log events
+const (
+	EventRejected  = "rejected"
+	EventStarted   = "started"
+	EventCompleted = "completed"
+)
+
+// Policy decisions recorded with each command
+const (
+	DecisionAllowed = "allowed"
+	DecisionDenied  = "denied"
+)
+
+// CommandRecord is a single entry in the command execution log
+type CommandRecord struct {
+	Event      string
+	JobID      string
+	Tool       string
+	Argv       []string
+	Parameters map[string]interface{}
+	ClientIP   string
+	// Identity is the authenticated client, empty for anonymous requests
+	Identity string
+	// Roles are the roles the request was authorised with
+	Roles     []string
+	Transport string
+	Decision  string
+	// Reason explains a denied decision or a failed command
+	Reason      string
+	State       string
+	ExitCode    *int
+	Duration    time.Duration
+	OutputBytes int
+}
+
 type Logger struct {
-	cmdLogger *log.Logger
-	errLogger *log.Logger
+	cmdLogger *slog.Logger
+	errLogger *slog.Logger
 }
 
+// New creates a logger writing JSON command records to stdout and errors to
+// stderr
 func New() *Logger {
+	return NewWithWriters(os.Stdout, os.Stderr)
+}
+
+// NewWithWriters creates a logger writing JSON command records to cmd and
+// errors to errs
+func NewWithWriters(cmd, errs io.Writer) *Logger {
 	return &Logger{
-		cmdLogger: log.New(os.Stdout, "[CMD] ", log.LstdFlags),
-		errLogger: log.New(os.Stderr, "[ERROR] ", log.LstdFlags),
+		cmdLogger: slog.New(slog.NewJSONHandler(cmd, nil)).With("log", "command"),
+		errLogger: slog.New(slog.NewJSONHandler(errs, nil)).With("log", "error"),
 	}
 }
 
-func (l *Logger) LogCommand(toolType, command string, params map[string]interface{}) {
-	// TODO: Implement command logging
+// LogCommand writes a command execution record
+func (l *Logger) LogCommand(rec CommandRecord) {
+	attrs := []slog.Attr{
+		slog.String("event", rec.Event),
+		slog.String("tool", rec.Tool),
+		slog.String("clientIP", rec.ClientIP),
+		slog.String("decision", rec.Decision),
+	}
+	if rec.JobID != "" {
+		attrs = append(attrs, slog.String("jobId", rec.JobID))
+	}
+	if rec.Identity != "" {
+		attrs = append(attrs, slog.String("identity", rec.Identity))
+	}
+	if len(rec.Roles) > 0 {
+		attrs = append(attrs, slog.Any("roles", rec.Roles))
+	}
+	if rec.Transport != "" {
+		attrs = append(attrs, slog.String("transport", rec.Transport))
+	}
+	if len(rec.Argv) > 0 {
+		attrs = append(attrs,
+			slog.String("command", strings.Join(rec.Argv, " ")),
+			slog.Any("argv", rec.Argv),
+		)
+	}
+	if rec.Parameters != nil {
+		attrs = append(attrs, slog.Any("parameters", rec.Parameters))
+	}
+	if rec.Reason != "" {
+		attrs = append(attrs, slog.String("reason", rec.Reason))
+	}
+	if rec.Event == EventCompleted {
+		attrs = append(attrs,
+			slog.String("state", rec.State),
+			slog.Float64("durationMs", float64(rec.Duration)/float64(time.Millisecond)),
+			slog.Int("outputBytes", rec.OutputBytes),
+		)
+		if rec.ExitCode != nil {
+			attrs = append(attrs, slog.Int("exitCode", *rec.ExitCode))
+		}
+	}
+
+	level := slog.LevelInfo
+	if rec.Decision == DecisionDenied {
+		level = slog.LevelWarn
+	}
+	l.cmdLogger.LogAttrs(context.Background(), level, "command", attrs...)
 }
 
+// LogError writes an error together with a description of what was being done
 func (l *Logger) LogError(err error, context string) {
-	// TODO: Implement error logging
+	l.errLogger.Error(err.Error(), "context", context)
 }
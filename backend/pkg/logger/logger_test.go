@@ -0,0 +1,78 @@
+// File: backend/pkg/logger/logger_test.go
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestLogCommand(t *testing.T) {
+	var cmd, errs bytes.Buffer
+	l := NewWithWriters(&cmd, &errs)
+
+	exitCode := 1
+	l.LogCommand(CommandRecord{
+		Event:       EventCompleted,
+		JobID:       "abc",
+		Tool:        "ping",
+		Argv:        []string{"ping", "-c", "1", "example.com"},
+		ClientIP:    "10.0.0.1",
+		Decision:    DecisionAllowed,
+		State:       "failed",
+		ExitCode:    &exitCode,
+		Duration:    1500 * time.Millisecond,
+		OutputBytes: 42,
+	})
+
+	var rec map[string]interface{}
+	if err := json.Unmarshal(cmd.Bytes(), &rec); err != nil {
+		t.Fatalf("record is not JSON: %v", err)
+	}
+	want := map[string]interface{}{
+		"log":         "command",
+		"event":       "completed",
+		"jobId":       "abc",
+		"tool":        "ping",
+		"command":     "ping -c 1 example.com",
+		"clientIP":    "10.0.0.1",
+		"decision":    "allowed",
+		"state":       "failed",
+		"exitCode":    float64(1),
+		"durationMs":  float64(1500),
+		"outputBytes": float64(42),
+	}
+	for key, value := range want {
+		if rec[key] != value {
+			t.Errorf("%s = %v, want %v", key, rec[key], value)
+		}
+	}
+	if _, ok := rec["time"]; !ok {
+		t.Error("record has no timestamp")
+	}
+}
+
+func TestLogCommandDenied(t *testing.T) {
+	var cmd, errs bytes.Buffer
+	l := NewWithWriters(&cmd, &errs)
+
+	l.LogCommand(CommandRecord{
+		Event:    EventRejected,
+		Tool:     "ping",
+		ClientIP: "10.0.0.1",
+		Decision: DecisionDenied,
+		Reason:   "target: target 10.0.0.5 is not permitted by policy",
+	})
+
+	var rec map[string]interface{}
+	if err := json.Unmarshal(cmd.Bytes(), &rec); err != nil {
+		t.Fatalf("record is not JSON: %v", err)
+	}
+	if rec["level"] != "WARN" || rec["decision"] != "denied" || rec["reason"] == nil {
+		t.Errorf("unexpected denied record: %v", rec)
+	}
+	if _, ok := rec["exitCode"]; ok {
+		t.Error("rejected record should not carry an exit code")
+	}
+}
@@ -0,0 +1,43 @@
+// File: backend/pkg/logger/slog.go
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+)
+
+// level is shared by the installed handler so it can change at runtime
+var level = new(slog.LevelVar)
+
+// Configure installs the process wide slog handler. Output from the standard
+// log package is routed through it as well.
+func Configure(lvl, format string) error {
+	if err := SetLevel(lvl); err != nil {
+		return err
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch format {
+	case "json":
+		handler = slog.NewJSONHandler(os.Stderr, opts)
+	case "text":
+		handler = slog.NewTextHandler(os.Stderr, opts)
+	default:
+		return fmt.Errorf("invalid log format %q", format)
+	}
+
+	slog.SetDefault(slog.New(handler))
+	return nil
+}
+
+// SetLevel changes the minimum level logged by the installed handler
+func SetLevel(lvl string) error {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(lvl)); err != nil {
+		return fmt.Errorf("invalid log level %q", lvl)
+	}
+	level.Set(l)
+	return nil
+}
@@ -0,0 +1,193 @@
+// File: backend/pkg/proxyproto/proxyproto.go
+package proxyproto
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// v1 headers are at most 107 bytes including the CRLF
+const maxV1Length = 107
+
+var (
+	v1Prefix    = []byte("PROXY ")
+	v2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+)
+
+// ErrInvalidHeader is returned by Read on connections whose PROXY header
+// could not be parsed
+var ErrInvalidHeader = errors.New("invalid PROXY protocol header")
+
+// Listener accepts PROXY protocol v1 and v2 headers on connections from
+// trusted peers and reports the address they carry as the connection's
+// remote address. Connections from other peers are passed through untouched,
+// so a client cannot spoof its address by sending a header itself.
+type Listener struct {
+	net.Listener
+	trusted func(net.IP) bool
+	timeout time.Duration
+}
+
+// NewListener wraps ln. trusted reports whether a peer may send a header and
+// timeout bounds how long reading the header may take.
+func NewListener(ln net.Listener, trusted func(net.IP) bool, timeout time.Duration) *Listener {
+	return &Listener{Listener: ln, trusted: trusted, timeout: timeout}
+}
+
+// Accept waits for the next connection. The header is read lazily on the
+// first Read or RemoteAddr call so a slow peer cannot stall the accept loop.
+func (l *Listener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	addr, ok := c.RemoteAddr().(*net.TCPAddr)
+	if !ok || !l.trusted(addr.IP) {
+		return c, nil
+	}
+	return &Conn{Conn: c, r: bufio.NewReaderSize(c, 256), remote: addr, timeout: l.timeout}, nil
+}
+
+// Conn is a connection from a trusted peer that may start with a PROXY header
+type Conn struct {
+	net.Conn
+	r       *bufio.Reader
+	timeout time.Duration
+
+	once   sync.Once
+	remote net.Addr
+	err    error
+}
+
+// Read reads from the connection after the PROXY header
+func (c *Conn) Read(p []byte) (int, error) {
+	c.once.Do(c.readHeader)
+	if c.err != nil {
+		return 0, c.err
+	}
+	return c.r.Read(p)
+}
+
+// RemoteAddr returns the client address from the PROXY header, or the peer
+// address when the connection carried none
+func (c *Conn) RemoteAddr() net.Addr {
+	c.once.Do(c.readHeader)
+	return c.remote
+}
+
+func (c *Conn) readHeader() {
+	if c.timeout > 0 {
+		c.Conn.SetReadDeadline(time.Now().Add(c.timeout))
+		defer c.Conn.SetReadDeadline(time.Time{})
+	}
+	addr, err := parseHeader(c.r)
+	if err != nil {
+		c.err = err
+		return
+	}
+	if addr != nil {
+		c.remote = addr
+	}
+}
+
+// parseHeader consumes a PROXY header from r if one is present. It returns
+// the source address, or nil when there is no header or it carries no
+// address, such as a v2 LOCAL command used for health checks.
+func parseHeader(r *bufio.Reader) (net.Addr, error) {
+	peek, err := r.Peek(len(v1Prefix))
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if bytes.Equal(peek, v1Prefix) {
+		return parseV1(r)
+	}
+	if bytes.Equal(peek, v2Signature[:len(v1Prefix)]) {
+		if peek, err := r.Peek(len(v2Signature)); err == nil && bytes.Equal(peek, v2Signature) {
+			return parseV2(r)
+		}
+	}
+	return nil, nil
+}
+
+// parseV1 parses a text header such as
+// "PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\n"
+func parseV1(r *bufio.Reader) (net.Addr, error) {
+	var line []byte
+	for !bytes.HasSuffix(line, []byte("\r\n")) {
+		if len(line) >= maxV1Length {
+			return nil, ErrInvalidHeader
+		}
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, ErrInvalidHeader
+		}
+		line = append(line, b)
+	}
+
+	fields := strings.Fields(strings.TrimSuffix(string(line), "\r\n"))
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return nil, nil
+	}
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return nil, ErrInvalidHeader
+	}
+	ip := net.ParseIP(fields[2])
+	port, err := strconv.ParseUint(fields[4], 10, 16)
+	if ip == nil || err != nil || (fields[1] == "TCP4") != (ip.To4() != nil) {
+		return nil, ErrInvalidHeader
+	}
+	return &net.TCPAddr{IP: ip, Port: int(port)}, nil
+}
+
+// parseV2 parses a binary header
+func parseV2(r *bufio.Reader) (net.Addr, error) {
+	head := make([]byte, 16)
+	if _, err := io.ReadFull(r, head); err != nil {
+		return nil, ErrInvalidHeader
+	}
+	verCmd, family := head[12], head[13]
+	body := make([]byte, binary.BigEndian.Uint16(head[14:16]))
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, ErrInvalidHeader
+	}
+	if verCmd>>4 != 2 {
+		return nil, fmt.Errorf("%w: version %d", ErrInvalidHeader, verCmd>>4)
+	}
+
+	switch verCmd & 0x0f {
+	case 0x0:
+		// LOCAL: the proxy's own connection, keep the peer address
+		return nil, nil
+	case 0x1:
+	default:
+		return nil, fmt.Errorf("%w: command %d", ErrInvalidHeader, verCmd&0x0f)
+	}
+
+	switch family {
+	case 0x11: // TCP over IPv4
+		if len(body) < 12 {
+			return nil, ErrInvalidHeader
+		}
+		return &net.TCPAddr{IP: net.IP(body[0:4]), Port: int(binary.BigEndian.Uint16(body[8:10]))}, nil
+	case 0x21: // TCP over IPv6
+		if len(body) < 36 {
+			return nil, ErrInvalidHeader
+		}
+		return &net.TCPAddr{IP: net.IP(body[0:16]), Port: int(binary.BigEndian.Uint16(body[32:34]))}, nil
+	default:
+		// UDP and Unix sockets carry no usable client address
+		return nil, nil
+	}
+}
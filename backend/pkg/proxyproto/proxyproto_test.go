@@ -0,0 +1,71 @@
+package proxyproto
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+)
+
+func v2Header(cmd, family byte, body []byte) string {
+	head := append([]byte{}, v2Signature...)
+	head = append(head, 0x20|cmd, family, 0, 0)
+	binary.BigEndian.PutUint16(head[14:], uint16(len(body)))
+	return string(append(head, body...))
+}
+
+func TestParseHeader(t *testing.T) {
+	v4 := []byte{192, 0, 2, 1, 198, 51, 100, 1, 0xdc, 0x04, 0x01, 0xbb}
+	v6 := make([]byte, 36)
+	copy(v6, net.ParseIP("2001:db8::1"))
+	binary.BigEndian.PutUint16(v6[32:], 4711)
+
+	tests := []struct {
+		name     string
+		input    string
+		wantAddr string
+		wantErr  bool
+	}{
+		{"No header", "GET / HTTP/1.1\r\n\r\n", "", false},
+		{"Empty connection", "", "", false},
+		{"v1 TCP4", "PROXY TCP4 192.0.2.1 198.51.100.1 56324 443\r\nGET /", "192.0.2.1:56324", false},
+		{"v1 TCP6", "PROXY TCP6 2001:db8::1 2001:db8::2 4711 443\r\nGET /", "[2001:db8::1]:4711", false},
+		{"v1 unknown", "PROXY UNKNOWN\r\nGET /", "", false},
+		{"v1 family mismatch", "PROXY TCP4 2001:db8::1 2001:db8::2 4711 443\r\n", "", true},
+		{"v1 missing fields", "PROXY TCP4 192.0.2.1\r\n", "", true},
+		{"v1 too long", "PROXY TCP4 " + strings.Repeat("1", 120) + "\r\n", "", true},
+		{"v2 TCP4", v2Header(1, 0x11, v4) + "GET /", "192.0.2.1:56324", false},
+		{"v2 TCP6", v2Header(1, 0x21, v6) + "GET /", "[2001:db8::1]:4711", false},
+		{"v2 local", v2Header(0, 0x00, nil) + "GET /", "", false},
+		{"v2 truncated", v2Header(1, 0x11, v4[:6]), "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := bufio.NewReader(strings.NewReader(tt.input))
+			addr, err := parseHeader(r)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidHeader) {
+					t.Errorf("err = %v, want ErrInvalidHeader", err)
+				}
+				return
+			}
+			got := ""
+			if addr != nil {
+				got = addr.String()
+			}
+			if got != tt.wantAddr {
+				t.Errorf("addr = %q, want %q", got, tt.wantAddr)
+			}
+			if rest, _ := io.ReadAll(r); tt.input != "" && !strings.HasPrefix(string(rest), "GET /") {
+				t.Errorf("remaining = %q, want the request", rest)
+			}
+		})
+	}
+}
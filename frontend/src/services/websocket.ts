@@ -11,7 +11,11 @@ export interface WebSocketCommand {
     error?: string
   }
   
-  export const WS_URL = 'ws://localhost:8080/ws'
+  // VITE_WS_URL overrides the default of the page's own host, which uses
+  // wss:// when the page was served over HTTPS
+  export const WS_URL: string =
+    import.meta.env.VITE_WS_URL ??
+    `${window.location.protocol === 'https:' ? 'wss' : 'ws'}://${window.location.host}/ws`
   
   export const createWebSocketMessage = (
     type: WebSocketCommand['type'],